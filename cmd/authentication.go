@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"shorturl/internal/api"
+	"shorturl/internal/auth"
+	"shorturl/internal/config"
+)
+
+// newAuthenticator returns authenticator of API keys if authentication is enabled,
+// otherwise it returns nil. Configured keys are saved to PostgreSQL key store if the pool
+// is set, or to in-memory key store if it is not.
+func newAuthenticator(cfg config.Auth, pool *pgxpool.Pool) (api.Authenticator, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	if pool == nil {
+		return auth.NewAuthenticator(auth.NewInMemoryKeyStore(cfg.APIKeys...)), nil
+	}
+
+	store, err := postgresKeyStore(pool, cfg.APIKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewAuthenticator(store), nil
+}
+
+func postgresKeyStore(pool *pgxpool.Pool, keys []string) (*auth.PostgreSQLKeyStore, error) {
+	const timeout = 15 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	store := auth.NewPostgreSQLKeyStore(pool)
+	for _, key := range keys {
+		if err := store.AddKey(ctx, "env", key); err != nil {
+			return nil, fmt.Errorf("failed to save api key from env: %w", err)
+		}
+	}
+
+	return store, nil
+}
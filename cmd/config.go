@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"shorturl/internal/config"
+)
+
+// runConfig runs command "config print" that prints the effective configuration of command
+// "serve" with the same flags. Values of secrets are redacted.
+func runConfig(args []string) error {
+	action, args := splitAction(args)
+	if action != "print" {
+		return fmt.Errorf("unknown action %q of command config, valid one: print", action)
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, 0, serveFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	return config.Print(os.Stdout, cfg)
+}
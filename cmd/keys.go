@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"shorturl/internal/auth"
+)
+
+// keysArgsCounts are counts of arguments of command "keys" after flags by its action.
+var keysArgsCounts = map[string]int{"add": 1, "list": 0, "revoke": 1}
+
+// runKeys runs command "keys" that manages API keys saved in PostgreSQL storage. Argument
+// "add NAME" generates a new key, saves its hash with the name and prints the key, it cannot
+// be printed again. Argument "list" prints hashes and names of saved keys, "revoke HASH" removes
+// the key by its hash. Keys of variable "API_KEYS" are saved again on launch of the server.
+func runKeys(args []string) error {
+	action, args := splitAction(args)
+	argsCount, ok := keysArgsCounts[action]
+	if !ok {
+		return fmt.Errorf("unknown action %q of command keys, valid ones: add, list, revoke", action)
+	}
+
+	flags := flag.NewFlagSet("keys "+action, flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, argsCount)
+	if err != nil {
+		return err
+	}
+
+	pool, err := validatedPostgresPool(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if err := prepareScheme(pool, false); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	store := auth.NewPostgreSQLKeyStore(pool)
+	switch action {
+	case "add":
+		return addKey(ctx, store, flags.Arg(0))
+	case "list":
+		return printKeys(ctx, store)
+	default:
+		return revokeKey(ctx, store, flags.Arg(0))
+	}
+}
+
+func addKey(ctx context.Context, store *auth.PostgreSQLKeyStore, name string) error {
+	key, err := auth.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	if err := store.AddKey(ctx, name, key); err != nil {
+		return err
+	}
+
+	fmt.Println(key)
+	return nil
+}
+
+func printKeys(ctx context.Context, store *auth.PostgreSQLKeyStore) error {
+	keys, err := store.Keys(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "HASH\tNAME\tCREATED AT")
+	for _, key := range keys {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", key.Hash, key.Name, key.CreatedAt.Format(time.RFC3339))
+	}
+
+	return writer.Flush()
+}
+
+func revokeKey(ctx context.Context, store *auth.PostgreSQLKeyStore, keyHash string) error {
+	isDeleted, err := store.DeleteKey(ctx, keyHash)
+	if err != nil {
+		return err
+	}
+
+	if !isDeleted {
+		return fmt.Errorf("api key with hash %q is not found", keyHash)
+	}
+
+	fmt.Printf("Revoked api key %s\n", keyHash)
+	return nil
+}
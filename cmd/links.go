@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"shorturl/internal/config"
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice"
+)
+
+// maxLinkLineSize is the maximum size of a line with a link read by command import.
+const maxLinkLineSize = 1 << 20
+
+// linkRecord is a link in JSON lines of commands import and export.
+// Missing expiration means that the link never expires, missing owner means that it is anonymous.
+type linkRecord struct {
+	ShortURL    string     `json:"short_url,omitempty"`
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Disabled    bool       `json:"disabled,omitempty"`
+	Owner       string     `json:"owner,omitempty"`
+}
+
+func newLinkRecord(link urlservice.Link) linkRecord {
+	record := linkRecord{
+		ShortURL:    link.ShortURL,
+		OriginalURL: link.OriginalURL,
+		Disabled:    link.Disabled,
+		Owner:       link.Owner,
+	}
+
+	if !link.ExpiresAt.IsZero() {
+		record.ExpiresAt = &link.ExpiresAt
+	}
+
+	return record
+}
+
+// link validates the original URL of the record and returns the link to import.
+func (r linkRecord) link() (urlservice.Link, error) {
+	originalURL, err := urlservice.NormalizeURL(r.OriginalURL)
+	if err != nil {
+		return urlservice.Link{}, err
+	}
+
+	link := urlservice.Link{
+		ShortURL:    r.ShortURL,
+		OriginalURL: originalURL,
+		Disabled:    r.Disabled,
+		Owner:       r.Owner,
+	}
+
+	if r.ExpiresAt != nil {
+		link.ExpiresAt = *r.ExpiresAt
+	}
+
+	return link, nil
+}
+
+// withService calls fn with the service over the configured storage.
+// The storage is closed after fn returns.
+func withService(cfg config.Config, fn func(ctx context.Context, service urlservice.ShortURLService) error) (err error) {
+	selection, err := selectedStorage(cfg, nil, false)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err = errors.Join(err, selection.close())
+	}()
+
+	service := urlservice.NewShortURLService(encoder.NewIDEncoder(), uint(cfg.ShortURLLength), selection.option)
+	return fn(context.Background(), service)
+}
+
+// runImport runs command "import" that saves links read as JSON lines.
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	path := flags.String("f", "", "Path to the file with links. Standard input is read if it is empty")
+	cfg, err := loadConfig(flags, args, 0, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	input := io.Reader(os.Stdin)
+	if *path != "" {
+		file, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		input = file
+	}
+
+	return withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		return importLinks(ctx, service, input, os.Stdout, os.Stderr)
+	})
+}
+
+// importLinks imports every link read from the input and writes their count to the output. Links that
+// cannot be imported are reported to errOutput with numbers of their lines and skipped, their count
+// is returned as an error.
+func importLinks(ctx context.Context, service urlservice.ShortURLService, input io.Reader, output, errOutput io.Writer) error {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(nil, maxLinkLineSize)
+
+	var imported, failed int
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		if err := importLink(ctx, service, scanner.Bytes()); err != nil {
+			fmt.Fprintf(errOutput, "Link on line %d is not imported: %s\n", line, err)
+			failed++
+			continue
+		}
+
+		imported++
+	}
+
+	fmt.Fprintf(output, "Imported %d links\n", imported)
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read links: %w", err)
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d links are not imported", failed)
+	}
+
+	return nil
+}
+
+func importLink(ctx context.Context, service urlservice.ShortURLService, data []byte) error {
+	var record linkRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return fmt.Errorf("invalid json: %w", err)
+	}
+
+	link, err := record.link()
+	if err != nil {
+		return err
+	}
+
+	_, err = service.ImportLink(ctx, link)
+	return err
+}
+
+// runExport runs command "export" that writes all links as JSON lines.
+func runExport(args []string) (err error) {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	path := flags.String("o", "", "Path to the file to write links. Standard output is written if it is empty")
+	cfg, err := loadConfig(flags, args, 0, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	output := io.Writer(os.Stdout)
+	if *path != "" {
+		file, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+
+		defer func() {
+			err = errors.Join(err, file.Close())
+		}()
+
+		output = file
+	}
+
+	buffered := bufio.NewWriter(output)
+	jsonEncoder := json.NewEncoder(buffered)
+	err = withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		return service.ExportLinks(ctx, func(link urlservice.Link) error {
+			return jsonEncoder.Encode(newLinkRecord(link))
+		})
+	})
+
+	return errors.Join(err, buffered.Flush())
+}
+
+// runCreate runs command "create" that prints a short URL for the original URL.
+func runCreate(args []string) error {
+	flags := flag.NewFlagSet("create", flag.ContinueOnError)
+	alias := flags.String("alias", "", "Alias used as short URL instead of encoded one")
+	ttl := flags.Duration("ttl", 0, "Time to live of short URL, it never expires if both ttl and expiration time are not set")
+	rawExpiresAt := flags.String("expires-at", "", "Expiration time of short URL in RFC 3339 format")
+	owner := flags.String("owner", "", "Owner of short URL, it is anonymous if owner is empty")
+	cfg, err := loadConfig(flags, args, 1, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	options := urlservice.LinkOptions{Alias: *alias, TTL: *ttl, Owner: *owner}
+	if *rawExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, *rawExpiresAt)
+		if err != nil {
+			return fmt.Errorf("expiration time flag contains not RFC 3339 time: %w", err)
+		}
+
+		options.ExpiresAt = expiresAt
+	}
+
+	originalURL, err := urlservice.NormalizeURL(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		shortURL, err := service.ShortURL(ctx, originalURL, options)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(shortURL)
+		return nil
+	})
+}
+
+// runResolve runs command "resolve" that prints the original URL of the short URL.
+func runResolve(args []string) error {
+	flags := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, 1, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		originalURL, err := service.OriginalURL(ctx, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(originalURL)
+		return nil
+	})
+}
+
+// runStats runs command "stats" that prints click statistics of the short URL.
+func runStats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	owner := flags.String("owner", "", "Owner of short URL, it is empty for anonymous short URLs")
+	cfg, err := loadConfig(flags, args, 1, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		stats, err := service.Stats(ctx, *owner, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		return printStats(os.Stdout, stats)
+	})
+}
+
+// runDelete runs command "delete" that removes the short URL of any owner with its statistics.
+func runDelete(args []string) error {
+	return runLinkChange("delete", args, urlservice.ShortURLService.Delete)
+}
+
+// runDisable runs command "disable" that stops resolving the short URL of any owner.
+func runDisable(args []string) error {
+	return runLinkChange("disable", args, urlservice.ShortURLService.Disable)
+}
+
+// runEnable runs command "enable" that resolves the disabled short URL of any owner again.
+func runEnable(args []string) error {
+	return runLinkChange("enable", args, urlservice.ShortURLService.Enable)
+}
+
+// runLinkChange runs the command that changes the short URL passed as its argument. Commands are
+// administrative, so the short URL is changed by urlservice.AnyOwner regardless of its owner.
+func runLinkChange(
+	name string,
+	args []string,
+	change func(service urlservice.ShortURLService, ctx context.Context, owner, shortURL string) error,
+) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, 1, storageFlagNames...)
+	if err != nil {
+		return err
+	}
+
+	return withService(cfg, func(ctx context.Context, service urlservice.ShortURLService) error {
+		return change(service, ctx, urlservice.AnyOwner, flags.Arg(0))
+	})
+}
+
+func printStats(output io.Writer, stats urlservice.Stats) error {
+	lastClickAt := "never"
+	if !stats.LastClickAt.IsZero() {
+		lastClickAt = stats.LastClickAt.Format(time.RFC3339)
+	}
+
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Short URL:\t%s\n", stats.ShortURL)
+	fmt.Fprintf(writer, "Clicks:\t%d\n", stats.Clicks)
+	fmt.Fprintf(writer, "Last click:\t%s\n", lastClickAt)
+
+	sections := []struct {
+		title  string
+		counts []urlservice.ClickCount
+	}{
+		{title: "Days", counts: stats.Days},
+		{title: "Referrers", counts: stats.Referrers},
+		{title: "User agents", counts: stats.UserAgents},
+		{title: "Networks", counts: stats.Networks},
+	}
+
+	for _, section := range sections {
+		if len(section.counts) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(writer, "%s:\t\n", section.title)
+		for _, count := range section.counts {
+			fmt.Fprintf(writer, "  %s\t%d\n", count.Value, count.Clicks)
+		}
+	}
+
+	return writer.Flush()
+}
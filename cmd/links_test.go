@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice"
+)
+
+// captureStdout returns what fn writes to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	output := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- string(content)
+	}()
+
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	fn()
+	require.NoError(t, writer.Close())
+	return <-output
+}
+
+// runCommand runs the command with the arguments and returns its standard output.
+func runCommand(t *testing.T, run func(args []string) error, args ...string) (string, error) {
+	t.Helper()
+
+	var err error
+	output := captureStdout(t, func() {
+		err = run(args)
+	})
+
+	return output, err
+}
+
+// boltArgs returns flags selecting bolt storage in a new file of the test.
+func boltArgs(t *testing.T) []string {
+	t.Helper()
+
+	return []string{"-s", "bolt", "-bolt-path", filepath.Join(t.TempDir(), "shorturl.db")}
+}
+
+func TestLinkCommands(t *testing.T) {
+	storageArgs := boltArgs(t)
+
+	output, err := runCommand(t, runCreate, append(storageArgs, "-alias", "example", "https://example.com/path")...)
+	require.NoError(t, err)
+	assert.Equal(t, "example\n", output)
+
+	output, err = runCommand(t, runResolve, append(storageArgs, "example")...)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/path\n", output)
+
+	output, err = runCommand(t, runStats, append(storageArgs, "example")...)
+	require.NoError(t, err)
+	assert.Equal(t, "Short URL:   example\nClicks:      0\nLast click:  never\n", output)
+
+	_, err = runCommand(t, runStats, append(storageArgs, "-owner", "owner", "example")...)
+	assert.ErrorIs(t, err, urlservice.ErrURLNotFound, "Stats of another owner must not be printed")
+
+	_, err = runCommand(t, runResolve, append(storageArgs, "missing")...)
+	assert.ErrorIs(t, err, urlservice.ErrURLNotFound)
+
+	exportFile := filepath.Join(t.TempDir(), "links.jsonl")
+	output, err = runCommand(t, runExport, append(storageArgs, "-o", exportFile)...)
+	require.NoError(t, err)
+	assert.Empty(t, output)
+
+	exported, err := os.ReadFile(exportFile)
+	require.NoError(t, err)
+	assert.Equal(t, `{"short_url":"example","original_url":"https://example.com/path"}`+"\n", string(exported))
+
+	importStorageArgs := boltArgs(t)
+	output, err = runCommand(t, runImport, append(importStorageArgs, "-f", exportFile)...)
+	require.NoError(t, err)
+	assert.Equal(t, "Imported 1 links\n", output)
+
+	output, err = runCommand(t, runResolve, append(importStorageArgs, "example")...)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/path\n", output)
+}
+
+func TestLinkChangeCommands(t *testing.T) {
+	storageArgs := boltArgs(t)
+
+	_, err := runCommand(t, runCreate, append(storageArgs, "-alias", "example", "-owner", "owner", "https://example.com/path")...)
+	require.NoError(t, err)
+
+	output, err := runCommand(t, runDisable, append(storageArgs, "example")...)
+	require.NoError(t, err)
+	assert.Empty(t, output)
+
+	_, err = runCommand(t, runResolve, append(storageArgs, "example")...)
+	assert.ErrorIs(t, err, urlservice.ErrURLDisabled, "Link of another owner is not disabled")
+
+	_, err = runCommand(t, runEnable, append(storageArgs, "example")...)
+	require.NoError(t, err)
+
+	output, err = runCommand(t, runResolve, append(storageArgs, "example")...)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/path\n", output)
+
+	_, err = runCommand(t, runDelete, append(storageArgs, "example")...)
+	require.NoError(t, err)
+
+	_, err = runCommand(t, runResolve, append(storageArgs, "example")...)
+	assert.ErrorIs(t, err, urlservice.ErrURLNotFound)
+
+	_, err = runCommand(t, runDelete, append(storageArgs, "example")...)
+	assert.ErrorIs(t, err, urlservice.ErrURLNotFound)
+}
+
+func TestLinkCommands_InvalidArguments(t *testing.T) {
+	tests := []struct {
+		name          string
+		run           func(args []string) error
+		args          []string
+		expectedError string
+	}{
+		{
+			name:          "create without url",
+			run:           runCreate,
+			expectedError: "command create expects 1 arguments after flags, got 0",
+		},
+		{
+			name:          "create with empty url",
+			run:           runCreate,
+			args:          []string{""},
+			expectedError: urlservice.ErrMissingURL.Error(),
+		},
+		{
+			name:          "create with invalid expiration",
+			run:           runCreate,
+			args:          []string{"-expires-at", "tomorrow", "https://example.com"},
+			expectedError: "expiration time flag contains not RFC 3339 time",
+		},
+		{
+			name:          "resolve with extra arguments",
+			run:           runResolve,
+			args:          []string{"first", "second"},
+			expectedError: "command resolve expects 1 arguments after flags, got 2",
+		},
+		{
+			name:          "delete without short url",
+			run:           runDelete,
+			expectedError: "command delete expects 1 arguments after flags, got 0",
+		},
+		{
+			name:          "import of missing file",
+			run:           runImport,
+			args:          []string{"-f", filepath.Join(t.TempDir(), "missing.jsonl")},
+			expectedError: "no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runCommand(t, tt.run, append(boltArgs(t), tt.args...)...)
+			assert.ErrorContains(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestImportLinks(t *testing.T) {
+	input := strings.Join([]string{
+		`{"short_url":"first","original_url":"https://example.com/1"}`,
+		``,
+		`{"short_url":`,
+		`{"short_url":"second"}`,
+		`{"original_url":"https://example.com/2","disabled":true,"expires_at":"2100-01-01T00:00:00Z"}`,
+		`{"short_url":"first","original_url":"https://example.com/other"}`,
+	}, "\n")
+
+	service := urlservice.NewShortURLService(encoder.NewIDEncoder(), 10, urlservice.WithInMemoryStorage())
+	var output, errOutput bytes.Buffer
+	err := importLinks(context.Background(), service, strings.NewReader(input), &output, &errOutput)
+
+	assert.EqualError(t, err, "3 links are not imported")
+	assert.Equal(t, "Imported 2 links\n", output.String())
+
+	assert.Equal(t, 3, strings.Count(errOutput.String(), "is not imported"))
+	assert.Contains(t, errOutput.String(), "Link on line 3 is not imported: invalid json")
+	assert.Contains(t, errOutput.String(), "Link on line 4 is not imported: missing original url\n")
+	assert.Contains(t, errOutput.String(), "Link on line 6 is not imported: "+urlservice.ErrAliasTaken.Error())
+
+	originalURL, err := service.OriginalURL(context.Background(), "first")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/1", originalURL, "Existing link must not be changed by import")
+}
+
+func TestImportLinks_TooLongLine(t *testing.T) {
+	input := `{"original_url":"https://example.com/1"}` + "\n" + strings.Repeat("a", maxLinkLineSize+1)
+
+	service := urlservice.NewShortURLService(encoder.NewIDEncoder(), 10, urlservice.WithInMemoryStorage())
+	var output, errOutput bytes.Buffer
+	err := importLinks(context.Background(), service, strings.NewReader(input), &output, &errOutput)
+
+	assert.ErrorContains(t, err, "failed to read links")
+	assert.Equal(t, "Imported 1 links\n", output.String())
+	assert.Empty(t, errOutput.String())
+}
+
+func TestPrintStats(t *testing.T) {
+	tests := []struct {
+		name     string
+		stats    urlservice.Stats
+		expected string
+	}{
+		{
+			name:  "without clicks",
+			stats: urlservice.Stats{ShortURL: "1234567890"},
+			expected: "Short URL:   1234567890\n" +
+				"Clicks:      0\n" +
+				"Last click:  never\n",
+		},
+		{
+			name: "with clicks",
+			stats: urlservice.Stats{
+				ShortURL:    "1234567890",
+				Clicks:      3,
+				LastClickAt: time.Date(2026, 10, 17, 12, 30, 0, 0, time.UTC),
+				Days:        []urlservice.ClickCount{{Value: "2026-10-16", Clicks: 1}, {Value: "2026-10-17", Clicks: 2}},
+				Referrers:   []urlservice.ClickCount{{Value: "https://example.org/", Clicks: 3}},
+				Networks:    []urlservice.ClickCount{{Value: "192.0.2.0/24", Clicks: 3}},
+			},
+			expected: "Short URL:              1234567890\n" +
+				"Clicks:                 3\n" +
+				"Last click:             2026-10-17T12:30:00Z\n" +
+				"Days:                   \n" +
+				"  2026-10-16            1\n" +
+				"  2026-10-17            2\n" +
+				"Referrers:              \n" +
+				"  https://example.org/  3\n" +
+				"Networks:               \n" +
+				"  192.0.2.0/24          3\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			require.NoError(t, printStats(&output, tt.stats))
+			assert.Equal(t, tt.expected, output.String())
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunInBackground(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stopped := make(chan struct{})
+	done := runInBackground(ctx, func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	select {
+	case <-done:
+		t.Fatal("Done channel is closed before the context is done")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancel()
+	<-done
+
+	select {
+	case <-stopped:
+	default:
+		assert.Fail(t, "Done channel is closed before run returns")
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"shorturl/internal/config"
+	"shorturl/internal/migrations"
+)
+
+// runMigrate runs command "migrate" that manages the scheme of PostgreSQL storage.
+// Argument "up" applies all new migrations, "down" rolls back the last applied one
+// and "status" prints all migrations with time of their applying.
+func runMigrate(args []string) error {
+	action, args := splitAction(args)
+	if action != "up" && action != "down" && action != "status" {
+		return fmt.Errorf("unknown action %q of command migrate, valid ones: up, down, status", action)
+	}
+
+	flags := flag.NewFlagSet("migrate "+action, flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, 0)
+	if err != nil {
+		return err
+	}
+
+	pool, err := validatedPostgresPool(cfg.Postgres)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	migrator, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch action {
+	case "up":
+		return migrateUp(ctx, migrator)
+	case "down":
+		return migrateDown(ctx, migrator)
+	default:
+		return printMigrationStatus(ctx, migrator)
+	}
+}
+
+func migrateUp(ctx context.Context, migrator *migrations.Migrator) error {
+	applied, err := migrator.Up(ctx)
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %d %s\n", migration.Version, migration.Name)
+	}
+
+	if err == nil && len(applied) == 0 {
+		fmt.Println("Scheme is up to date")
+	}
+
+	return err
+}
+
+func migrateDown(ctx context.Context, migrator *migrations.Migrator) error {
+	migration, ok, err := migrator.Down(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		fmt.Println("No applied migrations")
+		return nil
+	}
+
+	fmt.Printf("Rolled back migration %d %s\n", migration.Version, migration.Name)
+	return nil
+}
+
+func printMigrationStatus(ctx context.Context, migrator *migrations.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(writer, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return writer.Flush()
+}
+
+// prepareScheme applies new migrations of PostgreSQL scheme if isMigrated is true, otherwise
+// it checks that the scheme is up to date. It has a timeout for checking, but not for migrations.
+func prepareScheme(pool *pgxpool.Pool, isMigrated bool) error {
+	const timeout = 15 * time.Second
+
+	migrator, err := migrations.New(pool)
+	if err != nil {
+		return err
+	}
+
+	if isMigrated {
+		_, err := migrator.Up(context.Background())
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err = migrator.Check(ctx)
+	if errors.Is(err, migrations.ErrOutdatedScheme) {
+		return fmt.Errorf("%w, run \"shorturl_api migrate up\" or launch with flag \"-migrate\"", err)
+	}
+
+	return err
+}
+
+// validatedPostgresPool initializes postgres connection pool for commands, that use
+// PostgreSQL regardless of the selected storage, so its settings are validated here.
+func validatedPostgresPool(cfg config.Postgres) (*pgxpool.Pool, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration of postgres:\n%w", err)
+	}
+
+	return postgresPool(cfg, nil)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"shorturl/internal/config"
+	"shorturl/internal/ratelimit"
+)
+
+// newRateLimiters returns limiters of creating and resolving requests with the configured
+// rates. Limiter is nil if its rate is set to zero.
+func newRateLimiters(cfg config.RateLimits) (create, resolve ratelimit.Limiter) {
+	return newRateLimiter(cfg.CreateRate, cfg.CreateBurst), newRateLimiter(cfg.ResolveRate, cfg.ResolveBurst)
+}
+
+func newRateLimiter(rate float64, burst int) ratelimit.Limiter {
+	if rate == 0 {
+		return nil
+	}
+
+	return ratelimit.NewTokenBucketLimiter(rate, burst)
+}
@@ -2,69 +2,221 @@ package main
 
 import (
 	"context"
-	"flag"
+	"errors"
 	"fmt"
-	"os"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.etcd.io/bbolt"
+	"go.opentelemetry.io/otel/trace"
 
+	"shorturl/internal/config"
+	"shorturl/internal/tracing"
 	"shorturl/internal/urlservice"
+	"shorturl/internal/urlservice/boltstore"
+	"shorturl/internal/urlservice/memstore"
 )
 
-// selectedStorageOption is parsing flags and returning selected urlservice.StorageOptionFunc (or default).
+// storageSelection is a storage selected on program launch. Pool is set only if
+// PostgreSQL storage is selected, it is shared with other PostgreSQL stores.
+// Journal is set only if in-memory storage is selected with a journal directory.
+// Redis client and bolt database are set only if their storages are selected.
+type storageSelection struct {
+	option      urlservice.StorageOptionFunc
+	pool        *pgxpool.Pool
+	journal     *memstore.Journal
+	redisClient *redis.Client
+	boltDB      *bbolt.DB
+}
+
+// close closes the journal with a snapshot, the pool, the redis client and the bolt database
+// if they are set.
+func (s storageSelection) close() error {
+	var err error
+	if s.journal != nil {
+		err = s.journal.Close()
+	}
+
+	if s.pool != nil {
+		s.pool.Close()
+	}
+
+	if s.redisClient != nil {
+		err = errors.Join(err, s.redisClient.Close())
+	}
+
+	if s.boltDB != nil {
+		err = errors.Join(err, s.boltDB.Close())
+	}
+
+	return err
+}
+
+// storageFlagNames are flags selecting the storage, shared by commands working with links.
+var storageFlagNames = []string{"s", "bolt-path", "journal-dir"}
+
+// selectedStorage is returning the storage selected by the configuration.
 //
-// If a selected option does not exist, it returns error.
-func selectedStorageOption() (urlservice.StorageOptionFunc, error) {
-	const (
-		inMemoryOption = "in-memory"
-		postgresOption = "postgres"
-	)
-
-	tooltip := fmt.Sprintf("Specify the type of storage to use ('%s' or '%s'). Default is '%s'", inMemoryOption, postgresOption, inMemoryOption)
-	storageType := flag.String("s", inMemoryOption, tooltip)
-	flag.Parse()
-
-	switch *storageType {
-	case inMemoryOption:
-		return urlservice.WithInMemoryStorage(), nil
-	case postgresOption:
-		return withPostgresStorage()
+// PostgreSQL storage with the out-of-date scheme is refused, unless isMigrated is true
+// and new migrations are applied. If tracer provider is not nil, queries of PostgreSQL
+// storage are traced.
+func selectedStorage(cfg config.Config, tracerProvider trace.TracerProvider, isMigrated bool) (storageSelection, error) {
+	switch cfg.Storage.Type {
+	case config.StoragePostgres:
+		return withPostgresStorage(cfg.Postgres, tracerProvider, isMigrated)
+	case config.StorageRedis:
+		return withRedisStorage(cfg.Redis)
+	case config.StorageBolt:
+		return withBoltStorage(cfg.Bolt.Path)
 	default:
-		return nil, fmt.Errorf("invalid input: got %q, valid options: %q, %q", inMemoryOption, postgresOption, *storageType)
+		return withInMemoryStorage(cfg.Journal)
+	}
+}
+
+func withCache(selection storageSelection, cfg config.Cache) storageSelection {
+	settings := urlservice.CacheSettings{
+		Size:        cfg.Size,
+		TTL:         cfg.TTL,
+		NegativeTTL: cfg.NegativeTTL,
+	}
+
+	selection.option = urlservice.WithCache(selection.option, settings)
+	return selection
+}
+
+func withPostgresStorage(cfg config.Postgres, tracerProvider trace.TracerProvider, isMigrated bool) (storageSelection, error) {
+	pool, err := postgresPool(cfg, tracerProvider)
+	if err != nil {
+		return storageSelection{}, err
+	}
+
+	if err := prepareScheme(pool, isMigrated); err != nil {
+		pool.Close()
+		return storageSelection{}, err
+	}
+
+	return storageSelection{option: urlservice.WithPostgreSQLStorage(pool), pool: pool}, nil
+}
+
+// postgresPool initializes postgres connection pool with the connection settings and checks
+// that the server is reachable, since connections of the pool are established lazily.
+func postgresPool(cfg config.Postgres, tracerProvider trace.TracerProvider) (*pgxpool.Pool, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.ConnString())
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres connection settings: %w", err)
+	}
+
+	if cfg.ConnectTimeout != 0 {
+		poolConfig.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	}
+
+	if cfg.ApplicationName != "" {
+		poolConfig.ConnConfig.RuntimeParams["application_name"] = cfg.ApplicationName
+	}
+
+	if cfg.StatementTimeout != 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+	}
+
+	if cfg.Pool.MaxConns != 0 {
+		poolConfig.MaxConns = int32(cfg.Pool.MaxConns)
+	}
+
+	if cfg.Pool.MinConns != 0 {
+		poolConfig.MinConns = int32(cfg.Pool.MinConns)
+	}
+
+	if cfg.Pool.MaxConnLifetime != 0 {
+		poolConfig.MaxConnLifetime = cfg.Pool.MaxConnLifetime
+	}
+
+	if cfg.Pool.MaxConnIdleTime != 0 {
+		poolConfig.MaxConnIdleTime = cfg.Pool.MaxConnIdleTime
+	}
+
+	if cfg.Pool.HealthCheckPeriod != 0 {
+		poolConfig.HealthCheckPeriod = cfg.Pool.HealthCheckPeriod
+	}
+
+	if tracerProvider != nil {
+		poolConfig.ConnConfig.Tracer = tracing.NewQueryTracer(tracerProvider)
+	}
+
+	ctx := context.Background()
+	if poolConfig.ConnConfig.ConnectTimeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, poolConfig.ConnConfig.ConnectTimeout)
+		defer cancel()
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
 	}
+
+	return pool, nil
+}
+
+func withInMemoryStorage(cfg config.Journal) (storageSelection, error) {
+	if cfg.Dir == "" {
+		return storageSelection{option: urlservice.WithInMemoryStorage()}, nil
+	}
+
+	journal, err := memstore.OpenJournal(memstore.JournalSettings{
+		Dir:              cfg.Dir,
+		SyncInterval:     cfg.SyncInterval,
+		SnapshotInterval: cfg.SnapshotInterval,
+	})
+	if err != nil {
+		return storageSelection{}, err
+	}
+
+	return storageSelection{option: urlservice.WithInMemoryStorage(memstore.WithJournal(journal)), journal: journal}, nil
 }
 
-func withPostgresStorage() (urlservice.StorageOptionFunc, error) {
-	pool, err := postgresPool()
+func withBoltStorage(path string) (storageSelection, error) {
+	db, err := boltstore.Open(path)
 	if err != nil {
-		return nil, err
+		return storageSelection{}, err
 	}
 
-	return urlservice.WithPostgreSQLStorage(pool), nil
+	return storageSelection{option: urlservice.WithBoltStorage(db), boltDB: db}, nil
 }
 
-// postgresPool initializes postgres connection pool with values from environment variables.
+func withRedisStorage(cfg config.Redis) (storageSelection, error) {
+	client, err := redisClient(cfg)
+	if err != nil {
+		return storageSelection{}, err
+	}
+
+	return storageSelection{option: urlservice.WithRedisStorage(client), redisClient: client}, nil
+}
+
+// redisClient initializes redis client with the connection settings.
 // It has a timeout for connection and returns error on connection fails.
-func postgresPool() (*pgxpool.Pool, error) {
+func redisClient(cfg config.Redis) (*redis.Client, error) {
 	const timeoutValue = 15 * time.Second
 
-	var (
-		user     = os.Getenv("POSTGRES_USER")
-		password = os.Getenv("POSTGRES_PASSWORD")
-		host     = os.Getenv("POSTGRES_HOST")
-		port     = os.Getenv("POSTGRES_PORT")
-		dbName   = os.Getenv("POSTGRES_DB")
-	)
+	options := &redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeoutValue)
 	defer cancel()
 
-	connString := fmt.Sprintf("postgres://%s:%s@%s:%s/%s", user, password, host, port, dbName)
-	pool, err := pgxpool.New(ctx, connString)
-	if err != nil {
-		return nil, fmt.Errorf("faild to connect to postgres: %w", err)
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to connect to redis: %w", err), client.Close())
 	}
 
-	return pool, nil
+	return client, nil
 }
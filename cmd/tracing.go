@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"shorturl/internal/config"
+	"shorturl/internal/tracing"
+)
+
+// newTracerProvider returns a tracer provider with the configured exporter and a function
+// to export remaining spans on shutdown. Provider is nil if tracing is disabled.
+func newTracerProvider(ctx context.Context, cfg config.Tracing) (trace.TracerProvider, func() error, error) {
+	provider, err := tracing.NewTracerProvider(ctx, tracing.Exporter(cfg.Exporter))
+	if err != nil || provider == nil {
+		return nil, func() error { return nil }, err
+	}
+
+	shutdown := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		return provider.Shutdown(ctx)
+	}
+
+	return provider, shutdown, nil
+}
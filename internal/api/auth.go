@@ -0,0 +1,143 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"shorturl/internal/pb"
+)
+
+// bearerPrefix is a prefix of API key in Authorization header and authorization metadata.
+const bearerPrefix = "Bearer "
+
+// Authenticator checks API keys of requests to protected methods and returns owners of keys.
+// It must return an error wrapping auth.ErrUnauthenticated if the key is missing or invalid.
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (string, error)
+}
+
+// ownerContextKey is a key of authenticated owner in request context.
+type ownerContextKey struct{}
+
+func contextWithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerContextKey{}, owner)
+}
+
+// ownerFromContext returns the owner of API key of the request. It is empty
+// for public methods and if authentication is disabled.
+func ownerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerContextKey{}).(string)
+	return owner
+}
+
+// WithAuthenticator returns an option that requires API key for REST API methods
+// changing or reading data of short URLs. Resolving of short URLs stays public.
+func WithAuthenticator(authenticator Authenticator) RESTServerOption {
+	return func(server *RESTServer) {
+		server.authenticator = authenticator
+	}
+}
+
+// WithGRPCAuthenticator returns an option that requires API key for gRPC methods
+// changing or reading data of short URLs. Resolving of short URLs stays public.
+func WithGRPCAuthenticator(authenticator Authenticator) GRPCServerOption {
+	return func(server *GRPCServer) {
+		server.authenticator = authenticator
+	}
+}
+
+// publicGRPCMethods are methods of short URL service that do not require API key.
+var publicGRPCMethods = map[string]bool{
+	pb.ShortURLService_GetOriginalURL_FullMethodName:       true,
+	pb.ShortURLService_BatchGetOriginalURLs_FullMethodName: true,
+}
+
+// authMiddleware returns a handler that calls the handler with the owner of API key in request
+// context only if request has valid API key in Authorization header. Otherwise, it responds
+// with code 401. If authenticator is nil, the handler is returned as is.
+func authMiddleware(authenticator Authenticator, handler http.HandlerFunc) http.HandlerFunc {
+	if authenticator == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := bearerKey(r.Header.Get("Authorization"))
+		owner, err := authenticator.Authenticate(r.Context(), key)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", strings.TrimSpace(bearerPrefix))
+			writeResponse(w, "", err)
+			return
+		}
+
+		handler(w, r.WithContext(contextWithOwner(r.Context(), owner)))
+	}
+}
+
+// protected returns the handler requiring API key if server has authenticator.
+func (s *RESTServer) protected(handler http.HandlerFunc) http.HandlerFunc {
+	return authMiddleware(s.authenticator, handler)
+}
+
+func (s *GRPCServer) authUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *GRPCServer) authStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// contextStream is a server stream with a context replaced by interceptor, like a context with the owner of API key.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate checks API key in authorization metadata if the method is protected and
+// returns the context with the owner of API key. Methods of other services, like reflection,
+// are not protected.
+func (s *GRPCServer) authenticate(ctx context.Context, method string) (context.Context, error) {
+	isServiceMethod := strings.HasPrefix(method, "/"+pb.ShortURLService_ServiceDesc.ServiceName+"/")
+	if s.authenticator == nil || !isServiceMethod || publicGRPCMethods[method] {
+		return ctx, nil
+	}
+
+	var key string
+	if md, isFound := metadata.FromIncomingContext(ctx); isFound {
+		key = bearerKey(firstMetadataValue(md, "authorization"))
+	}
+
+	owner, err := s.authenticator.Authenticate(ctx, key)
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return nil, status.Error(code, err.Error())
+	}
+
+	return contextWithOwner(ctx, owner), nil
+}
+
+func bearerKey(authorization string) string {
+	if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(authorization[len(bearerPrefix):])
+}
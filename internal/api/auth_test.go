@@ -0,0 +1,357 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"shorturl/internal/auth"
+	"shorturl/internal/encoder"
+	"shorturl/internal/pb"
+	"shorturl/internal/urlservice"
+)
+
+const testAPIKey = "test-key"
+
+func TestProtectedRequestAuthentication(t *testing.T) {
+	tests := []struct {
+		name               string
+		authorization      string
+		expectedStatusCode int
+	}{
+		{
+			name:               "valid key",
+			authorization:      "Bearer " + testAPIKey,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "invalid key",
+			authorization:      "Bearer invalid-key",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "missing key",
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "not bearer scheme",
+			authorization:      "Basic " + testAPIKey,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedStatusCode == http.StatusNoContent {
+				urlServiceMock.EXPECT().
+					Delete(mock.Anything, auth.HashKey(testAPIKey), "1234567890").
+					Return(nil).
+					Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+			request := httptest.NewRequest(http.MethodDelete, "/api/v1/urls/1234567890", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+				assertBodyContent(t, recorder)
+			}
+		})
+	}
+}
+
+func TestPublicRequestWithAuthenticator(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		Return("https://example.com", nil).
+		Once()
+	urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+	request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+}
+
+func TestBatchRequestWithAuthenticator(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		authorization      string
+		setupMock          func(urlServiceMock *MockshortURLService)
+		expectedStatusCode int
+	}{
+		{
+			name: "resolve without key",
+			body: `{"action":"resolve","urls":["1234567890"]}`,
+			setupMock: func(urlServiceMock *MockshortURLService) {
+				urlServiceMock.EXPECT().
+					BatchOriginalURLs(mock.Anything, []string{"1234567890"}).
+					Return([]urlservice.ResolveResult{{ShortURL: "1234567890", OriginalURL: "https://example.com"}}, nil).
+					Once()
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "create without key",
+			body:               `{"action":"create","urls":["https://example.com"]}`,
+			setupMock:          func(*MockshortURLService) {},
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "create with key",
+			body:          `{"action":"create","urls":["https://example.com"]}`,
+			authorization: "Bearer " + testAPIKey,
+			setupMock: func(urlServiceMock *MockshortURLService) {
+				urlServiceMock.EXPECT().
+					BatchShortURLs(mock.Anything, auth.HashKey(testAPIKey), []string{"https://example.com"}).
+					Return([]string{"1234567890"}, nil).
+					Once()
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			tt.setupMock(urlServiceMock)
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls:batch", strings.NewReader(tt.body))
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Contains(t, recorder.Body.String(), `"url":"1234567890"`)
+			}
+		})
+	}
+}
+
+func TestProtectedRequestWithAuthenticatorError(t *testing.T) {
+	authenticatorMock := NewMockAuthenticator(t)
+	authenticatorMock.EXPECT().
+		Authenticate(mock.Anything, testAPIKey).
+		Return("", assert.AnError).
+		Once()
+
+	urlServiceMock := NewMockshortURLService(t)
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(authenticatorMock))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://example.com"}`))
+	request.Header.Set("Authorization", "Bearer "+testAPIKey)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestCreationRequestWithOwner(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ShortURL(mock.Anything, "https://example.com", urlservice.LinkOptions{Owner: auth.HashKey(testAPIKey)}).
+		Return("1234567890", nil).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://example.com"}`))
+	request.Header.Set("Authorization", "Bearer "+testAPIKey)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestProtectedMethodAuthentication(t *testing.T) {
+	tests := []struct {
+		name         string
+		key          string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "valid key",
+			key:          testAPIKey,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "invalid key",
+			key:          "invalid-key",
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name:         "missing key",
+			expectedCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedCode == codes.OK {
+				urlServiceMock.EXPECT().
+					Delete(mock.Anything, auth.HashKey(testAPIKey), "1234567890").
+					Return(nil).
+					Once()
+			}
+
+			client := grpcClient(t, urlServiceMock, WithGRPCAuthenticator(testAuthenticator()))
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tt.key)
+			}
+
+			_, err := client.DeleteShortURL(ctx, &pb.ShortURL{Url: "1234567890"})
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+		})
+	}
+}
+
+func TestProtectedStreamMethodAuthentication(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	client := grpcClient(t, urlServiceMock, WithGRPCAuthenticator(testAuthenticator()))
+
+	stream, err := client.ExportURLs(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assertCorrectGRPCCode(t, err, codes.Unauthenticated)
+}
+
+func TestPublicMethodWithAuthenticator(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		Return("https://example.com", nil).
+		Once()
+	urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCAuthenticator(testAuthenticator()))
+	_, err := client.GetOriginalURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	assertCorrectGRPCCode(t, err, codes.OK)
+}
+
+func testAuthenticator() auth.Authenticator {
+	return auth.NewAuthenticator(auth.NewInMemoryKeyStore(testAPIKey))
+}
+
+// newOwnedLinkService returns the service over in-memory storage with a link created by the owner of testAPIKey.
+func newOwnedLinkService(t *testing.T) (urlservice.ShortURLService, string) {
+	t.Helper()
+
+	service := urlservice.NewShortURLService(encoder.NewIDEncoder(), 10, urlservice.WithInMemoryStorage())
+	shortURL, err := service.ShortURL(context.Background(), "https://example.com/",
+		urlservice.LinkOptions{Owner: auth.HashKey(testAPIKey)})
+	require.NoError(t, err)
+
+	return service, shortURL
+}
+
+func TestProtectedRequestsOfAnotherOwner(t *testing.T) {
+	const anotherKey = "another-key"
+
+	service, shortURL := newOwnedLinkService(t)
+	authenticator := auth.NewAuthenticator(auth.NewInMemoryKeyStore(testAPIKey, anotherKey))
+	sut := NewRESTServer(":8080", service, WithAuthenticator(authenticator))
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/api/v1/urls/" + shortURL + "/stats"},
+		{method: http.MethodGet, path: "/api/v1/urls/" + shortURL + "/history"},
+		{method: http.MethodPatch, path: "/api/v1/urls/" + shortURL, body: `{"disabled":true}`},
+		{method: http.MethodPut, path: "/api/v1/urls/" + shortURL, body: `{"url":"https://example.org/"}`},
+		{method: http.MethodDelete, path: "/api/v1/urls/" + shortURL},
+	}
+
+	for _, r := range requests {
+		t.Run(r.method+" "+r.path, func(t *testing.T) {
+			request := httptest.NewRequest(r.method, r.path, strings.NewReader(r.body))
+			request.Header.Set("Authorization", "Bearer "+anotherKey)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusNotFound, recorder.Code)
+		})
+	}
+
+	originalURL, err := service.OriginalURL(context.Background(), shortURL)
+	require.NoError(t, err, "Link is changed by another owner")
+	assert.Equal(t, "https://example.com/", originalURL)
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/v1/urls/"+shortURL, nil)
+	request.Header.Set("Authorization", "Bearer "+testAPIKey)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusNoContent, recorder.Code, "Link is not deleted by its owner")
+}
+
+func TestProtectedMethodsOfAnotherOwner(t *testing.T) {
+	const anotherKey = "another-key"
+
+	service, shortURL := newOwnedLinkService(t)
+	authenticator := auth.NewAuthenticator(auth.NewInMemoryKeyStore(testAPIKey, anotherKey))
+	client := grpcClient(t, service, WithGRPCAuthenticator(authenticator))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+anotherKey)
+
+	_, err := client.GetStats(ctx, &pb.ShortURL{Url: shortURL})
+	assertCorrectGRPCCode(t, err, codes.NotFound)
+	_, err = client.DeleteShortURL(ctx, &pb.ShortURL{Url: shortURL})
+	assertCorrectGRPCCode(t, err, codes.NotFound)
+
+	stream, err := client.ExportURLs(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assertCorrectGRPCCode(t, err, codes.PermissionDenied)
+
+	importStream, err := client.ImportURLs(ctx)
+	require.NoError(t, err)
+	require.NoError(t, importStream.Send(&pb.Link{Url: "imported", OriginalUrl: "https://example.org/", Owner: auth.HashKey(testAPIKey)}))
+	summary, err := importStream.CloseAndRecv()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), summary.Imported)
+
+	page, err := service.ListShortURLs(context.Background(), auth.HashKey(anotherKey), "", 10)
+	require.NoError(t, err)
+	require.Len(t, page.Links, 1)
+	assert.Equal(t, "imported", page.Links[0].ShortURL, "Imported link is not owned by the owner of API key")
+}
@@ -2,14 +2,26 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"io"
 	"net"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"shorturl/internal/pb"
+	"shorturl/internal/urlservice"
+	"shorturl/internal/urlservice/analytics"
 )
 
 // GRPCServer is gRPC server implementation that processing requests to short URL service.
@@ -18,15 +30,24 @@ import (
 type GRPCServer struct {
 	pb.UnimplementedShortURLServiceServer
 
-	server     *grpc.Server
-	listener   net.Listener
-	urlService ShortURLService
+	server        *grpc.Server
+	listener      net.Listener
+	urlService    ShortURLService
+	authenticator Authenticator
+	rateLimits    rateLimits
+	metrics       RequestMetrics
+	tracing       *tracing
+	health        *health.Server
+	tlsConfig     *tls.Config
 }
 
-// NewGRPCServer initializes GRPCServer with its address to listen, and short URL service.
-// It returns a pointer to object.
-func NewGRPCServer(listenAddress string, urlService ShortURLService) (*GRPCServer, error) {
-	server := initGRPCServer(urlService)
+// GRPCServerOption is used to set optional settings of GRPCServer on its initialization.
+type GRPCServerOption func(server *GRPCServer)
+
+// NewGRPCServer initializes GRPCServer with its address to listen, short URL service
+// and optional settings. It returns a pointer to object.
+func NewGRPCServer(listenAddress string, urlService ShortURLService, options ...GRPCServerOption) (*GRPCServer, error) {
+	server := initGRPCServer(urlService, options...)
 	err := server.initListener(listenAddress)
 	if err != nil {
 		return nil, err
@@ -40,7 +61,7 @@ func (s *GRPCServer) Run() error {
 	return s.server.Serve(s.listener)
 }
 
-// Stop is calling method GracefulStop of object's grpc.Server.
+// Stop is calling method GracefulStop of object's grpc.Server. Server should be drained with Drain before.
 func (s *GRPCServer) Stop() {
 	s.server.GracefulStop()
 }
@@ -49,7 +70,7 @@ func (s *GRPCServer) Stop() {
 // processing a request and handles its error. If error is not nil, it responds
 // with corresponded error codes.Code and writes an error message.
 func (s *GRPCServer) CreateShortURL(ctx context.Context, req *pb.OriginalURL) (*pb.ShortURL, error) {
-	shortURL, err := handleCreationShortURL(ctx, req.Url, s.urlService)
+	shortURL, err := handleCreationShortURL(ctx, req.Url, linkOptionsFromRequest(req), s.urlService)
 	if err != nil {
 		_, code := errorStatusCodes(err)
 		return nil, status.Error(code, err.Error())
@@ -63,7 +84,7 @@ func (s *GRPCServer) CreateShortURL(ctx context.Context, req *pb.OriginalURL) (*
 // processing a request and handles its error. If error is not nil, it responds
 // with corresponded error codes.Code and writes an error message.
 func (s *GRPCServer) GetOriginalURL(ctx context.Context, req *pb.ShortURL) (*pb.OriginalURL, error) {
-	original, err := handleGetOriginalURL(ctx, req.Url, s.urlService)
+	original, err := handleResolveShortURL(ctx, clickFromContext(ctx, req.Url), s.urlService)
 	if err != nil {
 		_, code := errorStatusCodes(err)
 		return nil, status.Error(code, err.Error())
@@ -73,20 +94,342 @@ func (s *GRPCServer) GetOriginalURL(ctx context.Context, req *pb.ShortURL) (*pb.
 	return resp, nil
 }
 
+// GetStats is an implementation of rpc GetStats method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) GetStats(ctx context.Context, req *pb.ShortURL) (*pb.Stats, error) {
+	stats, err := handleGetStats(ctx, req.Url, s.urlService)
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return nil, status.Error(code, err.Error())
+	}
+
+	return statsToResponse(stats), nil
+}
+
+func linkOptionsFromRequest(req *pb.OriginalURL) urlservice.LinkOptions {
+	options := urlservice.LinkOptions{
+		Alias: req.Alias,
+		TTL:   time.Duration(req.TtlSeconds) * time.Second,
+	}
+
+	if req.ExpiresAt != nil {
+		options.ExpiresAt = req.ExpiresAt.AsTime()
+	}
+
+	return options
+}
+
+// BatchCreateShortURLs is an implementation of rpc BatchCreateShortURLs method. It is
+// processing a request and handles its error. If error of the whole batch is not nil,
+// it responds with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) BatchCreateShortURLs(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	results, err := handleBatchCreation(ctx, req.Urls, s.urlService)
+	return batchToResponse(results, err)
+}
+
+// BatchGetOriginalURLs is an implementation of rpc BatchGetOriginalURLs method. It is
+// processing a request and handles its error. If error of the whole batch is not nil,
+// it responds with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) BatchGetOriginalURLs(ctx context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	results, err := handleBatchResolving(ctx, req.Urls, s.urlService)
+	return batchToResponse(results, err)
+}
+
+func batchToResponse(results []batchResult, requestHandlingError error) (*pb.BatchResponse, error) {
+	if requestHandlingError != nil {
+		_, code := errorStatusCodes(requestHandlingError)
+		return nil, status.Error(code, requestHandlingError.Error())
+	}
+
+	resp := &pb.BatchResponse{
+		Results: make([]*pb.BatchResult, 0, len(results)),
+	}
+
+	for _, result := range results {
+		pbResult := &pb.BatchResult{
+			Url:         result.shortURL,
+			OriginalUrl: result.originalURL,
+		}
+
+		if result.err != nil {
+			_, code := errorStatusCodes(result.err)
+			pbResult.Code = int32(code)
+			pbResult.Error = result.err.Error()
+		}
+
+		resp.Results = append(resp.Results, pbResult)
+	}
+
+	return resp, nil
+}
+
+// maxReportedImportErrors limits count of errors of single links in response of ImportURLs.
+const maxReportedImportErrors = 100
+
+// ImportURLs is an implementation of rpc ImportURLs method. It imports every link of the stream,
+// errors of single links are counted and the first of them are written to the summary.
+// It responds with the summary when the client closes the stream.
+func (s *GRPCServer) ImportURLs(stream pb.ShortURLService_ImportURLsServer) error {
+	summary := &pb.ImportSummary{}
+	for index := int64(0); ; index++ {
+		pbLink, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(summary)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = handleImportLink(stream.Context(), linkFromRequest(pbLink), s.urlService)
+		if err == nil {
+			summary.Imported++
+			continue
+		}
+
+		summary.Failed++
+		if len(summary.Errors) < maxReportedImportErrors {
+			_, code := errorStatusCodes(err)
+			summary.Errors = append(summary.Errors, &pb.ImportError{
+				Index:       index,
+				Url:         pbLink.Url,
+				OriginalUrl: pbLink.OriginalUrl,
+				Code:        int32(code),
+				Error:       err.Error(),
+			})
+		}
+	}
+}
+
+// ExportURLs is an implementation of rpc ExportURLs method. It streams every link of the storage,
+// it is refused with codes.PermissionDenied if the request has API key. If the export fails,
+// it responds with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) ExportURLs(_ *emptypb.Empty, stream pb.ShortURLService_ExportURLsServer) error {
+	err := handleExportLinks(stream.Context(), s.urlService, func(link urlservice.Link) error {
+		return stream.Send(linkToResponse(link))
+	})
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return status.Error(code, err.Error())
+	}
+
+	return nil
+}
+
+// ListShortURLs is an implementation of rpc ListShortURLs method. It responds with a page of links
+// owned by the owner of API key. If error is not nil, it responds with corresponded error codes.Code
+// and writes an error message.
+func (s *GRPCServer) ListShortURLs(ctx context.Context, req *pb.ListShortURLsRequest) (*pb.LinkPage, error) {
+	page, err := handleListShortURLs(ctx, req.Cursor, int(req.PageSize), s.urlService)
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return nil, status.Error(code, err.Error())
+	}
+
+	resp := &pb.LinkPage{
+		Links:      make([]*pb.Link, 0, len(page.Links)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, link := range page.Links {
+		resp.Links = append(resp.Links, linkToResponse(link))
+	}
+
+	return resp, nil
+}
+
+func linkFromRequest(req *pb.Link) urlservice.Link {
+	link := urlservice.Link{
+		ShortURL:    req.Url,
+		OriginalURL: req.OriginalUrl,
+		Disabled:    req.Disabled,
+		Owner:       req.Owner,
+	}
+
+	if req.ExpiresAt != nil {
+		link.ExpiresAt = req.ExpiresAt.AsTime()
+	}
+
+	return link
+}
+
+func linkToResponse(link urlservice.Link) *pb.Link {
+	resp := &pb.Link{
+		Url:         link.ShortURL,
+		OriginalUrl: link.OriginalURL,
+		Disabled:    link.Disabled,
+		Owner:       link.Owner,
+	}
+
+	if !link.ExpiresAt.IsZero() {
+		resp.ExpiresAt = timestamppb.New(link.ExpiresAt)
+	}
+
+	return resp
+}
+
+// DeleteShortURL is an implementation of rpc DeleteShortURL method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) DeleteShortURL(ctx context.Context, req *pb.ShortURL) (*emptypb.Empty, error) {
+	err := handleDeleteShortURL(ctx, req.Url, s.urlService)
+	return emptyResponse(err)
+}
+
+// DisableShortURL is an implementation of rpc DisableShortURL method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) DisableShortURL(ctx context.Context, req *pb.ShortURL) (*emptypb.Empty, error) {
+	err := handleSetDisabled(ctx, req.Url, true, s.urlService)
+	return emptyResponse(err)
+}
+
+// EnableShortURL is an implementation of rpc EnableShortURL method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) EnableShortURL(ctx context.Context, req *pb.ShortURL) (*emptypb.Empty, error) {
+	err := handleSetDisabled(ctx, req.Url, false, s.urlService)
+	return emptyResponse(err)
+}
+
+// UpdateShortURL is an implementation of rpc UpdateShortURL method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) UpdateShortURL(ctx context.Context, req *pb.UpdateShortURLRequest) (*pb.ShortURL, error) {
+	err := handleUpdateShortURL(ctx, req.Url, req.OriginalUrl, s.urlService)
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return nil, status.Error(code, err.Error())
+	}
+
+	resp := &pb.ShortURL{Url: req.Url}
+	return resp, nil
+}
+
+// GetHistory is an implementation of rpc GetHistory method. It is
+// processing a request and handles its error. If error is not nil, it responds
+// with corresponded error codes.Code and writes an error message.
+func (s *GRPCServer) GetHistory(ctx context.Context, req *pb.ShortURL) (*pb.History, error) {
+	changes, err := handleGetHistory(ctx, req.Url, s.urlService)
+	if err != nil {
+		_, code := errorStatusCodes(err)
+		return nil, status.Error(code, err.Error())
+	}
+
+	resp := &pb.History{
+		Url:     req.Url,
+		Changes: make([]*pb.DestinationChange, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, &pb.DestinationChange{
+			PreviousUrl: change.PreviousURL,
+			NewUrl:      change.NewURL,
+			ChangedAt:   timestamppb.New(change.ChangedAt),
+		})
+	}
+
+	return resp, nil
+}
+
+func emptyResponse(requestHandlingError error) (*emptypb.Empty, error) {
+	if requestHandlingError != nil {
+		_, code := errorStatusCodes(requestHandlingError)
+		return nil, status.Error(code, requestHandlingError.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// clickFromContext returns a click on the short URL made by the client of request with the context.
+// Referrer is taken from "referer" metadata if the client provides it.
+func clickFromContext(ctx context.Context, shortURL string) analytics.Click {
+	click := analytics.Click{
+		ShortURL: shortURL,
+		Time:     time.Now(),
+	}
+
+	if md, isFound := metadata.FromIncomingContext(ctx); isFound {
+		click.Referrer = firstMetadataValue(md, "referer")
+		click.UserAgent = firstMetadataValue(md, "user-agent")
+	}
+
+	if p, isFound := peer.FromContext(ctx); isFound && p.Addr != nil {
+		click.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(click.ClientIP); err == nil {
+			click.ClientIP = host
+		}
+	}
+
+	return click
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) != 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+func statsToResponse(stats urlservice.Stats) *pb.Stats {
+	resp := &pb.Stats{
+		Url:        stats.ShortURL,
+		Clicks:     stats.Clicks,
+		Days:       clickCountsToResponse(stats.Days),
+		Referrers:  clickCountsToResponse(stats.Referrers),
+		UserAgents: clickCountsToResponse(stats.UserAgents),
+		Networks:   clickCountsToResponse(stats.Networks),
+	}
+
+	if !stats.LastClickAt.IsZero() {
+		resp.LastClickAt = timestamppb.New(stats.LastClickAt)
+	}
+
+	return resp
+}
+
+func clickCountsToResponse(counts []urlservice.ClickCount) []*pb.ClickCount {
+	result := make([]*pb.ClickCount, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, &pb.ClickCount{Value: count.Value, Clicks: count.Clicks})
+	}
+
+	return result
+}
+
 // initGRPCServer initializes grpc.Server and registers it to serve requests with
 // GRPCServer object as pb.ShortURLServiceServer. Also, it registers reflection.
+// The server uses TLS transport credentials if they are set by WithGRPCTLS.
 //
 // This function initializes and returns a pointer to GRPCServer
 // that is ready to start serving requests.
-func initGRPCServer(urlService ShortURLService) *GRPCServer {
-	server := grpc.NewServer(grpc.UnaryInterceptor(loggingUnaryInterceptor))
-
+func initGRPCServer(urlService ShortURLService, options ...GRPCServerOption) *GRPCServer {
 	serviceServer := &GRPCServer{
-		server:     server,
 		urlService: urlService,
 	}
 
+	for _, option := range options {
+		option(serviceServer)
+	}
+
+	serverOptions := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor, serviceServer.tracingUnaryInterceptor, serviceServer.metricsUnaryInterceptor,
+			serviceServer.authUnaryInterceptor, serviceServer.rateLimitUnaryInterceptor),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor, serviceServer.tracingStreamInterceptor, serviceServer.metricsStreamInterceptor,
+			serviceServer.authStreamInterceptor, serviceServer.rateLimitStreamInterceptor),
+	}
+
+	if serviceServer.tlsConfig != nil {
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(serviceServer.tlsConfig)))
+	}
+
+	server := grpc.NewServer(serverOptions...)
+	serviceServer.server = server
+
 	pb.RegisterShortURLServiceServer(server, serviceServer)
+	serviceServer.registerHealthServer()
 	reflection.Register(server)
 	return serviceServer
 }
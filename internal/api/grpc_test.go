@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -11,9 +14,13 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"shorturl/internal/auth"
 	"shorturl/internal/pb"
 	"shorturl/internal/urlservice"
 )
@@ -58,6 +65,9 @@ func TestGetOriginalURLMethod(t *testing.T) {
 					}).
 					Once()
 			}
+			if tt.expectedCode == codes.OK {
+				urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+			}
 
 			client := grpcClient(t, urlServiceMock)
 			originalURL, err := client.GetOriginalURL(context.Background(), &pb.ShortURL{Url: tt.shortURL})
@@ -92,6 +102,11 @@ func TestCreateShortURLMethod(t *testing.T) {
 			originalURL:  "https://examp  le.com/",
 			expectedCode: codes.InvalidArgument,
 		},
+		{
+			name:         "relative url in request body",
+			originalURL:  "relative/path",
+			expectedCode: codes.InvalidArgument,
+		},
 	}
 
 	for _, tt := range tests {
@@ -99,7 +114,7 @@ func TestCreateShortURLMethod(t *testing.T) {
 			urlServiceMock := NewMockshortURLService(t)
 			if tt.expectedCode != codes.InvalidArgument {
 				urlServiceMock.EXPECT().
-					ShortURL(mock.Anything, mock.Anything).
+					ShortURL(mock.Anything, mock.Anything, urlservice.LinkOptions{}).
 					Return("1111111111", nil).
 					Once()
 			}
@@ -116,6 +131,87 @@ func TestCreateShortURLMethod(t *testing.T) {
 	}
 }
 
+func TestCreateShortURLMethodWithAlias(t *testing.T) {
+	tests := []struct {
+		name         string
+		aliasError   error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "alias is free",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "alias is invalid",
+			aliasError:   urlservice.ErrInvalidAlias,
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "alias is taken",
+			aliasError:   urlservice.ErrAliasTaken,
+			expectedCode: codes.AlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const alias = "launch-2026"
+
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				ShortURL(mock.Anything, mock.Anything, urlservice.LinkOptions{Alias: alias}).
+				RunAndReturn(func(_ context.Context, _ string, options urlservice.LinkOptions) (string, error) {
+					if tt.aliasError != nil {
+						return "", tt.aliasError
+					}
+
+					return options.Alias, nil
+				}).
+				Once()
+
+			client := grpcClient(t, urlServiceMock)
+			shortURL, err := client.CreateShortURL(context.Background(), &pb.OriginalURL{Url: "https://example.com/", Alias: alias})
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+			if status.Code(err) != codes.OK {
+				return
+			}
+
+			assert.Equal(t, alias, shortURL.Url)
+		})
+	}
+}
+
+func TestCreateShortURLMethodWithExpiration(t *testing.T) {
+	expiresAt := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ShortURL(mock.Anything, mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, _ string, options urlservice.LinkOptions) (string, error) {
+			assert.True(t, expiresAt.Equal(options.ExpiresAt), "Unexpected expiration time")
+			assert.Equal(t, time.Hour, options.TTL)
+			return "1111111111", nil
+		}).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	req := &pb.OriginalURL{Url: "https://example.com/", ExpiresAt: timestamppb.New(expiresAt), TtlSeconds: 3600}
+	_, err := client.CreateShortURL(context.Background(), req)
+	assertCorrectGRPCCode(t, err, codes.OK)
+}
+
+func TestGetOriginalURLMethodForExpiredURL(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, mock.Anything).
+		Return("", urlservice.ErrURLExpired).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	_, err := client.GetOriginalURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	assertCorrectGRPCCode(t, err, codes.FailedPrecondition)
+}
+
 func assertCorrectGRPCCode(t *testing.T, err error, expectedCode codes.Code) {
 	respStatus, _ := status.FromError(err)
 	require.Equal(t, expectedCode, respStatus.Code())
@@ -126,16 +222,21 @@ func assertCorrectGRPCCode(t *testing.T, err error, expectedCode codes.Code) {
 	assert.NotEmpty(t, respStatus.Message(), "Error message must be set")
 }
 
-func grpcClient(t *testing.T, urlService ShortURLService) pb.ShortURLServiceClient {
+func grpcClient(t *testing.T, urlService ShortURLService, options ...GRPCServerOption) pb.ShortURLServiceClient {
 	const bufSize = 1 << 20
 
 	t.Helper()
 	listener := bufconn.Listen(bufSize)
-	runTestGRPCServer(t, urlService, listener)
+	runTestGRPCServer(t, urlService, listener, options...)
 	return connectGRPCClient(t, listener)
 }
 
 func connectGRPCClient(t *testing.T, listener *bufconn.Listener) pb.ShortURLServiceClient {
+	t.Helper()
+	return pb.NewShortURLServiceClient(dialGRPC(t, listener))
+}
+
+func dialGRPC(t *testing.T, listener *bufconn.Listener) *grpc.ClientConn {
 	t.Helper()
 	dialOptionFunc := func(_ context.Context, _ string) (net.Conn, error) {
 		return listener.Dial()
@@ -147,13 +248,13 @@ func connectGRPCClient(t *testing.T, listener *bufconn.Listener) pb.ShortURLServ
 		conn.Close()
 	})
 
-	return pb.NewShortURLServiceClient(conn)
+	return conn
 }
 
-func runTestGRPCServer(t *testing.T, urlService ShortURLService, listener *bufconn.Listener) {
+func runTestGRPCServer(t *testing.T, urlService ShortURLService, listener *bufconn.Listener, options ...GRPCServerOption) {
 	t.Helper()
 
-	serv := initGRPCServer(urlService)
+	serv := initGRPCServer(urlService, options...)
 	serv.listener = listener
 	go func() {
 		err := serv.Run()
@@ -162,3 +263,362 @@ func runTestGRPCServer(t *testing.T, urlService ShortURLService, listener *bufco
 
 	t.Cleanup(serv.Stop)
 }
+
+func TestGetStatsMethod(t *testing.T) {
+	const existingShortURL = "1234567890"
+	lastClickAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		shortURL     string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "short url exists",
+			shortURL:     existingShortURL,
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "short url does not exist",
+			shortURL:     "1111111111",
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "empty short url",
+			shortURL:     "",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedCode != codes.InvalidArgument {
+				urlServiceMock.EXPECT().
+					Stats(mock.Anything, "", mock.Anything).
+					RunAndReturn(func(_ context.Context, _, shortURL string) (urlservice.Stats, error) {
+						if shortURL != existingShortURL {
+							return urlservice.Stats{}, urlservice.ErrURLNotFound
+						}
+
+						return urlservice.Stats{
+							ShortURL:    shortURL,
+							Clicks:      2,
+							LastClickAt: lastClickAt,
+							Networks:    []urlservice.ClickCount{{Value: "192.0.2.0/24", Clicks: 2}},
+						}, nil
+					}).
+					Once()
+			}
+
+			client := grpcClient(t, urlServiceMock)
+			stats, err := client.GetStats(context.Background(), &pb.ShortURL{Url: tt.shortURL})
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+			if status.Code(err) != codes.OK {
+				return
+			}
+
+			assert.Equal(t, existingShortURL, stats.Url)
+			assert.Equal(t, int64(2), stats.Clicks)
+			assert.True(t, lastClickAt.Equal(stats.LastClickAt.AsTime()))
+			require.Len(t, stats.Networks, 1)
+			assert.Equal(t, "192.0.2.0/24", stats.Networks[0].Value)
+			assert.Empty(t, stats.Days)
+		})
+	}
+}
+
+func TestChangeShortURLMethods(t *testing.T) {
+	tests := []struct {
+		name         string
+		shortURL     string
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "short url exists",
+			shortURL:     "1234567890",
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "short url does not exist",
+			shortURL:     "1111111111",
+			serviceError: urlservice.ErrURLNotFound,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "empty short url",
+			shortURL:     "",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedCode != codes.InvalidArgument {
+				urlServiceMock.EXPECT().Delete(mock.Anything, "", tt.shortURL).Return(tt.serviceError).Once()
+				urlServiceMock.EXPECT().Disable(mock.Anything, "", tt.shortURL).Return(tt.serviceError).Once()
+				urlServiceMock.EXPECT().Enable(mock.Anything, "", tt.shortURL).Return(tt.serviceError).Once()
+			}
+
+			client := grpcClient(t, urlServiceMock)
+			req := &pb.ShortURL{Url: tt.shortURL}
+
+			_, err := client.DeleteShortURL(context.Background(), req)
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+
+			_, err = client.DisableShortURL(context.Background(), req)
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+
+			_, err = client.EnableShortURL(context.Background(), req)
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+		})
+	}
+}
+
+func TestGetOriginalURLMethodForDisabledURL(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, mock.Anything).
+		Return("", urlservice.ErrURLDisabled).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	_, err := client.GetOriginalURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	assertCorrectGRPCCode(t, err, codes.FailedPrecondition)
+}
+
+func TestUpdateShortURLMethod(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          *pb.UpdateShortURLRequest
+		serviceError error
+		expectedCode codes.Code
+	}{
+		{
+			name:         "short url exists",
+			req:          &pb.UpdateShortURLRequest{Url: "1234567890", OriginalUrl: "https://example.com/new"},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "short url does not exist",
+			req:          &pb.UpdateShortURLRequest{Url: "1234567890", OriginalUrl: "https://example.com/new"},
+			serviceError: urlservice.ErrURLNotFound,
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "empty original url",
+			req:          &pb.UpdateShortURLRequest{Url: "1234567890"},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "empty short url",
+			req:          &pb.UpdateShortURLRequest{OriginalUrl: "https://example.com/new"},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedCode != codes.InvalidArgument {
+				urlServiceMock.EXPECT().
+					UpdateOriginalURL(mock.Anything, "", tt.req.Url, tt.req.OriginalUrl).
+					Return(tt.serviceError).
+					Once()
+			}
+
+			client := grpcClient(t, urlServiceMock)
+			shortURL, err := client.UpdateShortURL(context.Background(), tt.req)
+			assertCorrectGRPCCode(t, err, tt.expectedCode)
+			if status.Code(err) != codes.OK {
+				return
+			}
+
+			assert.Equal(t, tt.req.Url, shortURL.Url)
+		})
+	}
+}
+
+func TestGetHistoryMethod(t *testing.T) {
+	changedAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		History(mock.Anything, "", "1234567890").
+		Return([]urlservice.DestinationChange{{PreviousURL: "https://example.com/old", NewURL: "https://example.com/new", ChangedAt: changedAt}}, nil).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	history, err := client.GetHistory(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	require.NoError(t, err)
+
+	require.Len(t, history.Changes, 1)
+	assert.Equal(t, "https://example.com/old", history.Changes[0].PreviousUrl)
+	assert.Equal(t, "https://example.com/new", history.Changes[0].NewUrl)
+	assert.True(t, changedAt.Equal(history.Changes[0].ChangedAt.AsTime()))
+}
+
+func TestBatchMethods(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		BatchShortURLs(mock.Anything, "", []string{"https://example.com/"}).
+		Return([]string{"1111111111"}, nil).
+		Once()
+	urlServiceMock.EXPECT().
+		BatchOriginalURLs(mock.Anything, []string{"1111111111", "2222222222"}).
+		Return([]urlservice.ResolveResult{
+			{ShortURL: "1111111111", OriginalURL: "https://example.com/"},
+			{ShortURL: "2222222222", Err: urlservice.ErrURLExpired},
+		}, nil).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	created, err := client.BatchCreateShortURLs(context.Background(), &pb.BatchRequest{Urls: []string{"https://example.com/", ""}})
+	require.NoError(t, err)
+	require.Len(t, created.Results, 2)
+	assert.Equal(t, "1111111111", created.Results[0].Url)
+	assert.Equal(t, int32(codes.OK), created.Results[0].Code)
+	assert.Equal(t, int32(codes.InvalidArgument), created.Results[1].Code)
+	assert.NotEmpty(t, created.Results[1].Error)
+
+	resolved, err := client.BatchGetOriginalURLs(context.Background(), &pb.BatchRequest{Urls: []string{"1111111111", "2222222222"}})
+	require.NoError(t, err)
+	require.Len(t, resolved.Results, 2)
+	assert.Equal(t, "https://example.com/", resolved.Results[0].OriginalUrl)
+	assert.Equal(t, int32(codes.FailedPrecondition), resolved.Results[1].Code)
+
+	_, err = client.BatchCreateShortURLs(context.Background(), &pb.BatchRequest{Urls: make([]string, urlservice.MaxBatchSize+1)})
+	assertCorrectGRPCCode(t, err, codes.InvalidArgument)
+}
+
+func TestImportURLsMethod(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ImportLink(mock.Anything, urlservice.Link{ShortURL: "1234567890", OriginalURL: "https://example.com/1", ExpiresAt: expiresAt, Disabled: true}).
+		Return("1234567890", nil).
+		Once()
+	urlServiceMock.EXPECT().
+		ImportLink(mock.Anything, urlservice.Link{ShortURL: "taken", OriginalURL: "https://example.com/2"}).
+		Return("", urlservice.ErrAliasTaken).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	stream, err := client.ImportURLs(context.Background())
+	require.NoError(t, err)
+
+	links := []*pb.Link{
+		{Url: "1234567890", OriginalUrl: "https://example.com/1", ExpiresAt: timestamppb.New(expiresAt), Disabled: true},
+		{Url: "taken", OriginalUrl: "https://example.com/2"},
+		{Url: "invalid"},
+	}
+	for _, link := range links {
+		require.NoError(t, stream.Send(link))
+	}
+
+	summary, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), summary.Imported)
+	assert.Equal(t, int64(2), summary.Failed)
+	require.Len(t, summary.Errors, 2)
+	assert.Equal(t, int64(1), summary.Errors[0].Index)
+	assert.Equal(t, int32(codes.AlreadyExists), summary.Errors[0].Code)
+	assert.Equal(t, int64(2), summary.Errors[1].Index)
+	assert.Equal(t, int32(codes.InvalidArgument), summary.Errors[1].Code)
+}
+
+func TestExportURLsMethod(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	exported := []urlservice.Link{
+		{ShortURL: "1111111111", OriginalURL: "https://example.com/1"},
+		{ShortURL: "2222222222", OriginalURL: "https://example.com/2", ExpiresAt: expiresAt, Disabled: true, Owner: "owner"},
+	}
+
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ExportLinks(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(urlservice.Link) error) error {
+			for _, link := range exported {
+				if err := fn(link); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	stream, err := client.ExportURLs(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+
+	var received []*pb.Link
+	for {
+		link, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+		received = append(received, link)
+	}
+
+	require.Len(t, received, 2)
+	assert.Equal(t, "1111111111", received[0].Url)
+	assert.Nil(t, received[0].ExpiresAt)
+	assert.Equal(t, "https://example.com/2", received[1].OriginalUrl)
+	assert.True(t, expiresAt.Equal(received[1].ExpiresAt.AsTime()))
+	assert.True(t, received[1].Disabled)
+	assert.Equal(t, "owner", received[1].Owner, "Links of every owner must be exported")
+}
+
+func TestExportURLsMethodWithError(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ExportLinks(mock.Anything, mock.Anything).
+		Return(errors.New("some error")).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	stream, err := client.ExportURLs(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assertCorrectGRPCCode(t, err, codes.Internal)
+}
+
+func TestListShortURLsMethod(t *testing.T) {
+	owner := auth.HashKey(testAPIKey)
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ListShortURLs(mock.Anything, owner, "cursor", 2).
+		Return(urlservice.LinkPage{
+			Links:      []urlservice.Link{{ShortURL: "1234567890", OriginalURL: "https://example.com/", Owner: owner}},
+			NextCursor: "next",
+		}, nil).
+		Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCAuthenticator(testAuthenticator()))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testAPIKey)
+	resp, err := client.ListShortURLs(ctx, &pb.ListShortURLsRequest{Cursor: "cursor", PageSize: 2})
+	require.NoError(t, err)
+
+	require.Len(t, resp.Links, 1)
+	assert.Equal(t, "1234567890", resp.Links[0].Url)
+	assert.Equal(t, owner, resp.Links[0].Owner)
+	assert.Equal(t, "next", resp.NextCursor)
+}
+
+func TestListShortURLsMethodWithoutOwner(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ListShortURLs(mock.Anything, "", "", 0).
+		Return(urlservice.LinkPage{}, urlservice.ErrOwnerRequired).
+		Once()
+
+	client := grpcClient(t, urlServiceMock)
+	_, err := client.ListShortURLs(context.Background(), &pb.ListShortURLsRequest{})
+	assertCorrectGRPCCode(t, err, codes.Unauthenticated)
+}
@@ -6,21 +6,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+
+	"shorturl/internal/urlservice"
+	"shorturl/internal/urlservice/analytics"
 )
 
 // ShortURLService is a definition of service that exchanges and stores URLs.
 type ShortURLService interface {
 	OriginalURL(ctx context.Context, shortURL string) (string, error)
-	ShortURL(ctx context.Context, originalURL string) (string, error)
+	ShortURL(ctx context.Context, originalURL string, options urlservice.LinkOptions) (string, error)
+	Delete(ctx context.Context, owner, shortURL string) error
+	Disable(ctx context.Context, owner, shortURL string) error
+	Enable(ctx context.Context, owner, shortURL string) error
+	UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error
+	History(ctx context.Context, owner, shortURL string) ([]urlservice.DestinationChange, error)
+	BatchShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error)
+	BatchOriginalURLs(ctx context.Context, shortURLs []string) ([]urlservice.ResolveResult, error)
+	ImportLink(ctx context.Context, link urlservice.Link) (string, error)
+	ExportLinks(ctx context.Context, fn func(link urlservice.Link) error) error
+	ListShortURLs(ctx context.Context, owner, cursor string, size int) (urlservice.LinkPage, error)
+	RecordClick(click analytics.Click)
+	Stats(ctx context.Context, owner, shortURL string) (urlservice.Stats, error)
+	Ping(ctx context.Context) error
 }
 
-func handleCreationShortURL(ctx context.Context, originalURL string, urlService ShortURLService) (string, error) {
-	parsedURL, err := validateURL(originalURL)
+// handleCreationShortURL validates the original URL and requests short URL for it with the options.
+// Short URL is owned by the owner of API key of the request.
+func handleCreationShortURL(ctx context.Context, originalURL string, options urlservice.LinkOptions, urlService ShortURLService) (string, error) {
+	parsedURL, err := urlservice.NormalizeURL(originalURL)
 	if err != nil {
 		return "", errors.Join(errInvalidRequest, err)
 	}
 
-	shortURL, err := urlService.ShortURL(ctx, parsedURL)
+	options.Owner = ownerFromContext(ctx)
+	shortURL, err := urlService.ShortURL(ctx, parsedURL, options)
 	if err != nil {
 		return "", err
 	}
@@ -35,3 +54,160 @@ func handleGetOriginalURL(ctx context.Context, shortURL string, urlService Short
 
 	return urlService.OriginalURL(ctx, shortURL)
 }
+
+// handleResolveShortURL is looking for the original URL of the clicked short URL.
+// The click is recorded only if the original URL is found.
+func handleResolveShortURL(ctx context.Context, click analytics.Click, urlService ShortURLService) (string, error) {
+	originalURL, err := handleGetOriginalURL(ctx, click.ShortURL, urlService)
+	if err != nil {
+		return "", err
+	}
+
+	urlService.RecordClick(click)
+	return originalURL, nil
+}
+
+// handleGetStats requests statistics of the short URL owned by the owner of API key of the request.
+func handleGetStats(ctx context.Context, shortURL string, urlService ShortURLService) (urlservice.Stats, error) {
+	if shortURL == "" {
+		return urlservice.Stats{}, fmt.Errorf("%w: short url is not provided", errInvalidRequest)
+	}
+
+	return urlService.Stats(ctx, ownerFromContext(ctx), shortURL)
+}
+
+// handleDeleteShortURL deletes the short URL owned by the owner of API key of the request.
+func handleDeleteShortURL(ctx context.Context, shortURL string, urlService ShortURLService) error {
+	if shortURL == "" {
+		return fmt.Errorf("%w: short url is not provided", errInvalidRequest)
+	}
+
+	return urlService.Delete(ctx, ownerFromContext(ctx), shortURL)
+}
+
+// handleSetDisabled disables or enables the short URL owned by the owner of API key of the request.
+func handleSetDisabled(ctx context.Context, shortURL string, disabled bool, urlService ShortURLService) error {
+	if shortURL == "" {
+		return fmt.Errorf("%w: short url is not provided", errInvalidRequest)
+	}
+
+	if disabled {
+		return urlService.Disable(ctx, ownerFromContext(ctx), shortURL)
+	}
+
+	return urlService.Enable(ctx, ownerFromContext(ctx), shortURL)
+}
+
+// handleUpdateShortURL validates the original URL and maps the short URL owned by the owner
+// of API key of the request with it.
+func handleUpdateShortURL(ctx context.Context, shortURL, originalURL string, urlService ShortURLService) error {
+	if shortURL == "" {
+		return fmt.Errorf("%w: short url is not provided", errInvalidRequest)
+	}
+
+	parsedURL, err := urlservice.NormalizeURL(originalURL)
+	if err != nil {
+		return errors.Join(errInvalidRequest, err)
+	}
+
+	return urlService.UpdateOriginalURL(ctx, ownerFromContext(ctx), shortURL, parsedURL)
+}
+
+// handleGetHistory requests history of the short URL owned by the owner of API key of the request.
+func handleGetHistory(ctx context.Context, shortURL string, urlService ShortURLService) ([]urlservice.DestinationChange, error) {
+	if shortURL == "" {
+		return nil, fmt.Errorf("%w: short url is not provided", errInvalidRequest)
+	}
+
+	return urlService.History(ctx, ownerFromContext(ctx), shortURL)
+}
+
+// batchResult is a result of single URL of batch request. Error is set if the URL is not processed.
+type batchResult struct {
+	shortURL    string
+	originalURL string
+	err         error
+}
+
+// handleBatchCreation validates every original URL of the batch and requests short URLs for
+// valid ones. Invalid URLs get errors in their results, other results keep the order of URLs.
+// Short URLs are owned by the owner of API key of the request.
+func handleBatchCreation(ctx context.Context, originalURLs []string, urlService ShortURLService) ([]batchResult, error) {
+	if len(originalURLs) > urlservice.MaxBatchSize {
+		return nil, urlservice.ErrBatchTooLarge
+	}
+
+	results := make([]batchResult, len(originalURLs))
+	validURLs := make([]string, 0, len(originalURLs))
+	validIndexes := make([]int, 0, len(originalURLs))
+	for i, originalURL := range originalURLs {
+		results[i].originalURL = originalURL
+		parsedURL, err := urlservice.NormalizeURL(originalURL)
+		if err != nil {
+			results[i].err = errors.Join(errInvalidRequest, err)
+			continue
+		}
+
+		validURLs = append(validURLs, parsedURL)
+		validIndexes = append(validIndexes, i)
+	}
+
+	shortURLs, err := urlService.BatchShortURLs(ctx, ownerFromContext(ctx), validURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, shortURL := range shortURLs {
+		results[validIndexes[i]].shortURL = shortURL
+	}
+
+	return results, nil
+}
+
+// handleBatchResolving requests original URLs for all short URLs of the batch. Results keep the order of URLs.
+func handleBatchResolving(ctx context.Context, shortURLs []string, urlService ShortURLService) ([]batchResult, error) {
+	resolved, err := urlService.BatchOriginalURLs(ctx, shortURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]batchResult, 0, len(resolved))
+	for _, result := range resolved {
+		results = append(results, batchResult{
+			shortURL:    result.ShortURL,
+			originalURL: result.OriginalURL,
+			err:         result.Err,
+		})
+	}
+
+	return results, nil
+}
+
+// handleImportLink validates the original URL of the link and imports it. Imported link is
+// always owned by the owner of API key of the request, owner sent by the client is ignored.
+func handleImportLink(ctx context.Context, link urlservice.Link, urlService ShortURLService) (string, error) {
+	parsedURL, err := urlservice.NormalizeURL(link.OriginalURL)
+	if err != nil {
+		return "", errors.Join(errInvalidRequest, err)
+	}
+
+	link.OriginalURL = parsedURL
+	link.Owner = ownerFromContext(ctx)
+
+	return urlService.ImportLink(ctx, link)
+}
+
+// handleExportLinks calls fn for every link of the storage. Export of all links is an administrative
+// operation, so it returns errExportForbidden if the request has API key.
+func handleExportLinks(ctx context.Context, urlService ShortURLService, fn func(link urlservice.Link) error) error {
+	if ownerFromContext(ctx) != "" {
+		return errExportForbidden
+	}
+
+	return urlService.ExportLinks(ctx, fn)
+}
+
+// handleListShortURLs requests a page of links owned by the owner of API key of the request.
+func handleListShortURLs(ctx context.Context, cursor string, size int, urlService ShortURLService) (urlservice.LinkPage, error) {
+	return urlService.ListShortURLs(ctx, ownerFromContext(ctx), cursor, size)
+}
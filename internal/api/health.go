@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"shorturl/internal/pb"
+)
+
+// readinessTimeout is a maximum duration of the storage check on readiness probe.
+const readinessTimeout = 2 * time.Second
+
+// Drain marks the server as not ready, so load balancers stop sending new requests to it.
+// Readiness probe is responded with code 503, but requests are handled until Shutdown.
+func (s *RESTServer) Drain() {
+	s.draining.Store(true)
+}
+
+// handleReadiness is responding that the server is ready to handle requests. The server is
+// not ready if it is draining or its storage is unavailable, then it responds with code 503.
+func (s *RESTServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	respStatus := "ok"
+	switch {
+	case s.draining.Load():
+		respStatus = "draining"
+	case !isStorageReady(r.Context(), s.urlService):
+		respStatus = "unavailable"
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	if respStatus != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	respBody := struct {
+		Status string `json:"status"`
+	}{respStatus}
+
+	writeBody(w, respBody)
+}
+
+// Drain sets status NOT_SERVING for all services of gRPC health-checking protocol, so load
+// balancers stop sending new requests to the server. Requests are handled until Stop.
+func (s *GRPCServer) Drain() {
+	s.health.Shutdown()
+}
+
+// registerHealthServer registers gRPC health-checking service. The whole server and
+// short URL service are serving until Drain, but checks of short URL service fail
+// while its storage is unavailable.
+func (s *GRPCServer) registerHealthServer() {
+	s.health = health.NewServer()
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(pb.ShortURLService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s.server, storageHealthServer{Server: s.health, urlService: s.urlService})
+}
+
+// storageHealthServer is a health server that checks the storage on Check requests of short URL service.
+type storageHealthServer struct {
+	*health.Server
+	urlService ShortURLService
+}
+
+func (h storageHealthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	resp, err := h.Server.Check(ctx, req)
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return resp, err
+	}
+
+	if req.Service == pb.ShortURLService_ServiceDesc.ServiceName && !isStorageReady(ctx, h.urlService) {
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	}
+
+	return resp, nil
+}
+
+// isStorageReady pings the storage of the service with readinessTimeout.
+func isStorageReady(ctx context.Context, urlService ShortURLService) bool {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	if err := urlService.Ping(ctx); err != nil {
+		slog.Warn("Storage is not ready", slog.String("error", err.Error()))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"shorturl/internal/pb"
+)
+
+func TestReadinessRequest(t *testing.T) {
+	tests := []struct {
+		name               string
+		pingError          error
+		drain              bool
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "ready",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"status":"ok"}`,
+		},
+		{
+			name:               "storage is unavailable",
+			pingError:          errors.New("some error"),
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"status":"unavailable"}`,
+		},
+		{
+			name:               "draining",
+			drain:              true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"status":"draining"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if !tt.drain {
+				urlServiceMock.EXPECT().Ping(mock.Anything).Return(tt.pingError).Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+			if tt.drain {
+				sut.Drain()
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestLivenessRequest(t *testing.T) {
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, NewMockshortURLService(t))
+	sut.Drain()
+
+	request := httptest.NewRequest(http.MethodGet, "/livez", nil)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestGRPCHealthCheck(t *testing.T) {
+	const bufSize = 1 << 20
+
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().Ping(mock.Anything).Return(nil).Once()
+	urlServiceMock.EXPECT().Ping(mock.Anything).Return(errors.New("some error")).Once()
+
+	listener := bufconn.Listen(bufSize)
+	sut := initGRPCServer(urlServiceMock)
+	sut.listener = listener
+	go func() {
+		err := sut.Run()
+		require.NoError(t, err)
+	}()
+	t.Cleanup(sut.Stop)
+
+	client := healthpb.NewHealthClient(dialGRPC(t, listener))
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	serviceName := pb.ShortURLService_ServiceDesc.ServiceName
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(serviceName))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(serviceName))
+
+	sut.Drain()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(serviceName))
+}
@@ -21,6 +21,16 @@ func loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.Un
 	return resp, err
 }
 
+func loggingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	startingTime := time.Now()
+	err := handler(srv, stream)
+
+	rpcStatus, _ := status.FromError(err)
+	logGRPCRequest(stream.Context(), info.FullMethod, rpcStatus, time.Since(startingTime))
+
+	return err
+}
+
 func logGRPCRequest(ctx context.Context, method string, rpcStatus *status.Status, elapsedTime time.Duration) {
 	lvl := levelByGRPCCode(rpcStatus.Code())
 	slog.Log(ctx, lvl, "Request handled", slog.String("handler_type", "gRPC"),
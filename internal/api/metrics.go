@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	transportREST = "rest"
+	transportGRPC = "grpc"
+)
+
+// unmatchedRoute is a pattern label of requests to unknown paths.
+const unmatchedRoute = "unmatched"
+
+// RequestMetrics records handled requests. Method is a route of REST API with HTTP method
+// or a full name of gRPC method, code is HTTP status code or name of gRPC code.
+type RequestMetrics interface {
+	ObserveRequest(transport, method, code string, duration time.Duration)
+}
+
+// WithMetrics returns an option that records metrics of all REST API requests.
+func WithMetrics(metrics RequestMetrics) RESTServerOption {
+	return func(server *RESTServer) {
+		server.metrics = metrics
+	}
+}
+
+// WithMetricsHandler returns an option that serves the handler on path /metrics.
+// Requests to it are not recorded in metrics.
+func WithMetricsHandler(handler http.Handler) RESTServerOption {
+	return func(server *RESTServer) {
+		server.metricsHandler = handler
+	}
+}
+
+// WithGRPCMetrics returns an option that records metrics of all gRPC requests.
+func WithGRPCMetrics(metrics RequestMetrics) GRPCServerOption {
+	return func(server *GRPCServer) {
+		server.metrics = metrics
+	}
+}
+
+// knownHTTPMethods are methods used as labels as is, other methods are replaced
+// with "OTHER" to keep count of label values low.
+var knownHTTPMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+// metricsMiddleware returns a handler that records the request with the route pattern.
+// If metrics is nil, the handler is returned as is.
+func metricsMiddleware(metrics RequestMetrics, pattern string, handler http.HandlerFunc) http.HandlerFunc {
+	if metrics == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		startingTime := time.Now()
+		wrappedWriter := &responseWriterWrapper{
+			ResponseWriter: w,
+		}
+
+		handler(wrappedWriter, r)
+
+		statusCode := wrappedWriter.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		method := r.Method
+		if !knownHTTPMethods[method] {
+			method = "OTHER"
+		}
+
+		metrics.ObserveRequest(transportREST, method+" "+pattern, strconv.Itoa(statusCode), time.Since(startingTime))
+	}
+}
+
+func (s *GRPCServer) metricsUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.metrics == nil {
+		return handler(ctx, req)
+	}
+
+	startingTime := time.Now()
+	resp, err := handler(ctx, req)
+	s.metrics.ObserveRequest(transportGRPC, info.FullMethod, status.Code(err).String(), time.Since(startingTime))
+
+	return resp, err
+}
+
+func (s *GRPCServer) metricsStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.metrics == nil {
+		return handler(srv, stream)
+	}
+
+	startingTime := time.Now()
+	err := handler(srv, stream)
+	s.metrics.ObserveRequest(transportGRPC, info.FullMethod, status.Code(err).String(), time.Since(startingTime))
+
+	return err
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+
+	"shorturl/internal/pb"
+	"shorturl/internal/urlservice"
+)
+
+func TestRequestMetrics(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedMethod string
+		expectedCode   string
+	}{
+		{
+			name:           "route",
+			method:         http.MethodGet,
+			path:           "/1234567890",
+			expectedMethod: "GET /{short}",
+			expectedCode:   "404",
+		},
+		{
+			name:           "not allowed method",
+			method:         http.MethodPost,
+			path:           "/healthz",
+			expectedMethod: "POST /healthz",
+			expectedCode:   "405",
+		},
+		{
+			name:           "unknown method",
+			method:         "BREW",
+			path:           "/healthz",
+			expectedMethod: "OTHER /healthz",
+			expectedCode:   "405",
+		},
+		{
+			name:           "unmatched route",
+			method:         http.MethodGet,
+			path:           "/1234567890/unknown",
+			expectedMethod: "GET unmatched",
+			expectedCode:   "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().OriginalURL(mock.Anything, mock.Anything).Return("", urlservice.ErrURLNotFound).Maybe()
+
+			metricsMock := NewMockRequestMetrics(t)
+			metricsMock.EXPECT().ObserveRequest(transportREST, tt.expectedMethod, tt.expectedCode, mock.Anything).Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, WithMetrics(metricsMock))
+
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.path, nil))
+		})
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("shorturl_links_created_total 1\n"))
+	})
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, NewMockshortURLService(t), WithMetrics(NewMockRequestMetrics(t)), WithMetricsHandler(metricsHandler))
+
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "shorturl_links_created_total 1\n", recorder.Body.String())
+}
+
+func TestGRPCRequestMetrics(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		Return("", urlservice.ErrURLExpired).
+		Once()
+
+	metricsMock := NewMockRequestMetrics(t)
+	metricsMock.EXPECT().
+		ObserveRequest(transportGRPC, pb.ShortURLService_GetOriginalURL_FullMethodName, codes.FailedPrecondition.String(), mock.Anything).
+		Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCMetrics(metricsMock))
+	_, err := client.GetOriginalURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	assertCorrectGRPCCode(t, err, codes.FailedPrecondition)
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package api
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockAuthenticator is an autogenerated mock type for the Authenticator type
+type MockAuthenticator struct {
+	mock.Mock
+}
+
+type MockAuthenticator_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAuthenticator) EXPECT() *MockAuthenticator_Expecter {
+	return &MockAuthenticator_Expecter{mock: &_m.Mock}
+}
+
+// Authenticate provides a mock function with given fields: ctx, key
+func (_m *MockAuthenticator) Authenticate(ctx context.Context, key string) (string, error) {
+	ret := _m.Called(ctx, key)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+		return rf(ctx, key)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+		r0 = rf(ctx, key)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockAuthenticator_Authenticate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Authenticate'
+type MockAuthenticator_Authenticate_Call struct {
+	*mock.Call
+}
+
+// Authenticate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+func (_e *MockAuthenticator_Expecter) Authenticate(ctx interface{}, key interface{}) *MockAuthenticator_Authenticate_Call {
+	return &MockAuthenticator_Authenticate_Call{Call: _e.mock.On("Authenticate", ctx, key)}
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) Run(run func(ctx context.Context, key string)) *MockAuthenticator_Authenticate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) Return(_a0 string, _a1 error) *MockAuthenticator_Authenticate_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockAuthenticator_Authenticate_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockAuthenticator_Authenticate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockAuthenticator creates a new instance of MockAuthenticator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAuthenticator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAuthenticator {
+	mock := &MockAuthenticator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
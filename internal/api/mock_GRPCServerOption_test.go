@@ -0,0 +1,65 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package api
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockGRPCServerOption is an autogenerated mock type for the GRPCServerOption type
+type MockGRPCServerOption struct {
+	mock.Mock
+}
+
+type MockGRPCServerOption_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockGRPCServerOption) EXPECT() *MockGRPCServerOption_Expecter {
+	return &MockGRPCServerOption_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: server
+func (_m *MockGRPCServerOption) Execute(server *GRPCServer) {
+	_m.Called(server)
+}
+
+// MockGRPCServerOption_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockGRPCServerOption_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - server *GRPCServer
+func (_e *MockGRPCServerOption_Expecter) Execute(server interface{}) *MockGRPCServerOption_Execute_Call {
+	return &MockGRPCServerOption_Execute_Call{Call: _e.mock.On("Execute", server)}
+}
+
+func (_c *MockGRPCServerOption_Execute_Call) Run(run func(server *GRPCServer)) *MockGRPCServerOption_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*GRPCServer))
+	})
+	return _c
+}
+
+func (_c *MockGRPCServerOption_Execute_Call) Return() *MockGRPCServerOption_Execute_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockGRPCServerOption_Execute_Call) RunAndReturn(run func(*GRPCServer)) *MockGRPCServerOption_Execute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockGRPCServerOption creates a new instance of MockGRPCServerOption. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockGRPCServerOption(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockGRPCServerOption {
+	mock := &MockGRPCServerOption{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
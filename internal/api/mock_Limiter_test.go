@@ -0,0 +1,91 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package api
+
+import (
+	context "context"
+	ratelimit "shorturl/internal/ratelimit"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockLimiter is an autogenerated mock type for the Limiter type
+type MockLimiter struct {
+	mock.Mock
+}
+
+type MockLimiter_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockLimiter) EXPECT() *MockLimiter_Expecter {
+	return &MockLimiter_Expecter{mock: &_m.Mock}
+}
+
+// AllowN provides a mock function with given fields: ctx, key, cost
+func (_m *MockLimiter) AllowN(ctx context.Context, key string, cost int) (ratelimit.Decision, error) {
+	ret := _m.Called(ctx, key, cost)
+
+	var r0 ratelimit.Decision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (ratelimit.Decision, error)); ok {
+		return rf(ctx, key, cost)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ratelimit.Decision); ok {
+		r0 = rf(ctx, key, cost)
+	} else {
+		r0 = ret.Get(0).(ratelimit.Decision)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, key, cost)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockLimiter_AllowN_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllowN'
+type MockLimiter_AllowN_Call struct {
+	*mock.Call
+}
+
+// AllowN is a helper method to define mock.On call
+//   - ctx context.Context
+//   - key string
+//   - cost int
+func (_e *MockLimiter_Expecter) AllowN(ctx interface{}, key interface{}, cost interface{}) *MockLimiter_AllowN_Call {
+	return &MockLimiter_AllowN_Call{Call: _e.mock.On("AllowN", ctx, key, cost)}
+}
+
+func (_c *MockLimiter_AllowN_Call) Run(run func(ctx context.Context, key string, cost int)) *MockLimiter_AllowN_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockLimiter_AllowN_Call) Return(_a0 ratelimit.Decision, _a1 error) *MockLimiter_AllowN_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockLimiter_AllowN_Call) RunAndReturn(run func(context.Context, string, int) (ratelimit.Decision, error)) *MockLimiter_AllowN_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockLimiter creates a new instance of MockLimiter. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockLimiter(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockLimiter {
+	mock := &MockLimiter{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
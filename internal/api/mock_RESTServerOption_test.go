@@ -0,0 +1,65 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package api
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockRESTServerOption is an autogenerated mock type for the RESTServerOption type
+type MockRESTServerOption struct {
+	mock.Mock
+}
+
+type MockRESTServerOption_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRESTServerOption) EXPECT() *MockRESTServerOption_Expecter {
+	return &MockRESTServerOption_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: server
+func (_m *MockRESTServerOption) Execute(server *RESTServer) {
+	_m.Called(server)
+}
+
+// MockRESTServerOption_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockRESTServerOption_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - server *RESTServer
+func (_e *MockRESTServerOption_Expecter) Execute(server interface{}) *MockRESTServerOption_Execute_Call {
+	return &MockRESTServerOption_Execute_Call{Call: _e.mock.On("Execute", server)}
+}
+
+func (_c *MockRESTServerOption_Execute_Call) Run(run func(server *RESTServer)) *MockRESTServerOption_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*RESTServer))
+	})
+	return _c
+}
+
+func (_c *MockRESTServerOption_Execute_Call) Return() *MockRESTServerOption_Execute_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockRESTServerOption_Execute_Call) RunAndReturn(run func(*RESTServer)) *MockRESTServerOption_Execute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRESTServerOption creates a new instance of MockRESTServerOption. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRESTServerOption(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRESTServerOption {
+	mock := &MockRESTServerOption{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
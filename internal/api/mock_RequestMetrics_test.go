@@ -0,0 +1,72 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package api
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockRequestMetrics is an autogenerated mock type for the RequestMetrics type
+type MockRequestMetrics struct {
+	mock.Mock
+}
+
+type MockRequestMetrics_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRequestMetrics) EXPECT() *MockRequestMetrics_Expecter {
+	return &MockRequestMetrics_Expecter{mock: &_m.Mock}
+}
+
+// ObserveRequest provides a mock function with given fields: transport, method, code, duration
+func (_m *MockRequestMetrics) ObserveRequest(transport string, method string, code string, duration time.Duration) {
+	_m.Called(transport, method, code, duration)
+}
+
+// MockRequestMetrics_ObserveRequest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ObserveRequest'
+type MockRequestMetrics_ObserveRequest_Call struct {
+	*mock.Call
+}
+
+// ObserveRequest is a helper method to define mock.On call
+//   - transport string
+//   - method string
+//   - code string
+//   - duration time.Duration
+func (_e *MockRequestMetrics_Expecter) ObserveRequest(transport interface{}, method interface{}, code interface{}, duration interface{}) *MockRequestMetrics_ObserveRequest_Call {
+	return &MockRequestMetrics_ObserveRequest_Call{Call: _e.mock.On("ObserveRequest", transport, method, code, duration)}
+}
+
+func (_c *MockRequestMetrics_ObserveRequest_Call) Run(run func(transport string, method string, code string, duration time.Duration)) *MockRequestMetrics_ObserveRequest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *MockRequestMetrics_ObserveRequest_Call) Return() *MockRequestMetrics_ObserveRequest_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockRequestMetrics_ObserveRequest_Call) RunAndReturn(run func(string, string, string, time.Duration)) *MockRequestMetrics_ObserveRequest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockRequestMetrics creates a new instance of MockRequestMetrics. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRequestMetrics(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRequestMetrics {
+	mock := &MockRequestMetrics{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -4,6 +4,8 @@ package api
 
 import (
 	context "context"
+	urlservice "shorturl/internal/urlservice"
+	analytics "shorturl/internal/urlservice/analytics"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -21,6 +23,456 @@ func (_m *MockshortURLService) EXPECT() *MockshortURLService_Expecter {
 	return &MockshortURLService_Expecter{mock: &_m.Mock}
 }
 
+// BatchOriginalURLs provides a mock function with given fields: ctx, shortURLs
+func (_m *MockshortURLService) BatchOriginalURLs(ctx context.Context, shortURLs []string) ([]urlservice.ResolveResult, error) {
+	ret := _m.Called(ctx, shortURLs)
+
+	var r0 []urlservice.ResolveResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]urlservice.ResolveResult, error)); ok {
+		return rf(ctx, shortURLs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []urlservice.ResolveResult); ok {
+		r0 = rf(ctx, shortURLs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]urlservice.ResolveResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortURLs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_BatchOriginalURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BatchOriginalURLs'
+type MockshortURLService_BatchOriginalURLs_Call struct {
+	*mock.Call
+}
+
+// BatchOriginalURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURLs []string
+func (_e *MockshortURLService_Expecter) BatchOriginalURLs(ctx interface{}, shortURLs interface{}) *MockshortURLService_BatchOriginalURLs_Call {
+	return &MockshortURLService_BatchOriginalURLs_Call{Call: _e.mock.On("BatchOriginalURLs", ctx, shortURLs)}
+}
+
+func (_c *MockshortURLService_BatchOriginalURLs_Call) Run(run func(ctx context.Context, shortURLs []string)) *MockshortURLService_BatchOriginalURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_BatchOriginalURLs_Call) Return(_a0 []urlservice.ResolveResult, _a1 error) *MockshortURLService_BatchOriginalURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_BatchOriginalURLs_Call) RunAndReturn(run func(context.Context, []string) ([]urlservice.ResolveResult, error)) *MockshortURLService_BatchOriginalURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BatchShortURLs provides a mock function with given fields: ctx, owner, originalURLs
+func (_m *MockshortURLService) BatchShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	ret := _m.Called(ctx, owner, originalURLs)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]string, error)); ok {
+		return rf(ctx, owner, originalURLs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []string); ok {
+		r0 = rf(ctx, owner, originalURLs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, owner, originalURLs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_BatchShortURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BatchShortURLs'
+type MockshortURLService_BatchShortURLs_Call struct {
+	*mock.Call
+}
+
+// BatchShortURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - originalURLs []string
+func (_e *MockshortURLService_Expecter) BatchShortURLs(ctx interface{}, owner interface{}, originalURLs interface{}) *MockshortURLService_BatchShortURLs_Call {
+	return &MockshortURLService_BatchShortURLs_Call{Call: _e.mock.On("BatchShortURLs", ctx, owner, originalURLs)}
+}
+
+func (_c *MockshortURLService_BatchShortURLs_Call) Run(run func(ctx context.Context, owner string, originalURLs []string)) *MockshortURLService_BatchShortURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_BatchShortURLs_Call) Return(_a0 []string, _a1 error) *MockshortURLService_BatchShortURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_BatchShortURLs_Call) RunAndReturn(run func(context.Context, string, []string) ([]string, error)) *MockshortURLService_BatchShortURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockshortURLService) Delete(ctx context.Context, owner string, shortURL string) error {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockshortURLService_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockshortURLService_Expecter) Delete(ctx interface{}, owner interface{}, shortURL interface{}) *MockshortURLService_Delete_Call {
+	return &MockshortURLService_Delete_Call{Call: _e.mock.On("Delete", ctx, owner, shortURL)}
+}
+
+func (_c *MockshortURLService_Delete_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockshortURLService_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_Delete_Call) Return(_a0 error) *MockshortURLService_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_Delete_Call) RunAndReturn(run func(context.Context, string, string) error) *MockshortURLService_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Disable provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockshortURLService) Disable(ctx context.Context, owner string, shortURL string) error {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_Disable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Disable'
+type MockshortURLService_Disable_Call struct {
+	*mock.Call
+}
+
+// Disable is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockshortURLService_Expecter) Disable(ctx interface{}, owner interface{}, shortURL interface{}) *MockshortURLService_Disable_Call {
+	return &MockshortURLService_Disable_Call{Call: _e.mock.On("Disable", ctx, owner, shortURL)}
+}
+
+func (_c *MockshortURLService_Disable_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockshortURLService_Disable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_Disable_Call) Return(_a0 error) *MockshortURLService_Disable_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_Disable_Call) RunAndReturn(run func(context.Context, string, string) error) *MockshortURLService_Disable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Enable provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockshortURLService) Enable(ctx context.Context, owner string, shortURL string) error {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_Enable_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Enable'
+type MockshortURLService_Enable_Call struct {
+	*mock.Call
+}
+
+// Enable is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockshortURLService_Expecter) Enable(ctx interface{}, owner interface{}, shortURL interface{}) *MockshortURLService_Enable_Call {
+	return &MockshortURLService_Enable_Call{Call: _e.mock.On("Enable", ctx, owner, shortURL)}
+}
+
+func (_c *MockshortURLService_Enable_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockshortURLService_Enable_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_Enable_Call) Return(_a0 error) *MockshortURLService_Enable_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_Enable_Call) RunAndReturn(run func(context.Context, string, string) error) *MockshortURLService_Enable_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ExportLinks provides a mock function with given fields: ctx, fn
+func (_m *MockshortURLService) ExportLinks(ctx context.Context, fn func(urlservice.Link) error) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(urlservice.Link) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_ExportLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ExportLinks'
+type MockshortURLService_ExportLinks_Call struct {
+	*mock.Call
+}
+
+// ExportLinks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(urlservice.Link) error
+func (_e *MockshortURLService_Expecter) ExportLinks(ctx interface{}, fn interface{}) *MockshortURLService_ExportLinks_Call {
+	return &MockshortURLService_ExportLinks_Call{Call: _e.mock.On("ExportLinks", ctx, fn)}
+}
+
+func (_c *MockshortURLService_ExportLinks_Call) Run(run func(ctx context.Context, fn func(urlservice.Link) error)) *MockshortURLService_ExportLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(urlservice.Link) error))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_ExportLinks_Call) Return(_a0 error) *MockshortURLService_ExportLinks_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_ExportLinks_Call) RunAndReturn(run func(context.Context, func(urlservice.Link) error) error) *MockshortURLService_ExportLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// History provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockshortURLService) History(ctx context.Context, owner string, shortURL string) ([]urlservice.DestinationChange, error) {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 []urlservice.DestinationChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]urlservice.DestinationChange, error)); ok {
+		return rf(ctx, owner, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []urlservice.DestinationChange); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]urlservice.DestinationChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type MockshortURLService_History_Call struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockshortURLService_Expecter) History(ctx interface{}, owner interface{}, shortURL interface{}) *MockshortURLService_History_Call {
+	return &MockshortURLService_History_Call{Call: _e.mock.On("History", ctx, owner, shortURL)}
+}
+
+func (_c *MockshortURLService_History_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockshortURLService_History_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_History_Call) Return(_a0 []urlservice.DestinationChange, _a1 error) *MockshortURLService_History_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_History_Call) RunAndReturn(run func(context.Context, string, string) ([]urlservice.DestinationChange, error)) *MockshortURLService_History_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ImportLink provides a mock function with given fields: ctx, link
+func (_m *MockshortURLService) ImportLink(ctx context.Context, link urlservice.Link) (string, error) {
+	ret := _m.Called(ctx, link)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, urlservice.Link) (string, error)); ok {
+		return rf(ctx, link)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, urlservice.Link) string); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, urlservice.Link) error); ok {
+		r1 = rf(ctx, link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_ImportLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ImportLink'
+type MockshortURLService_ImportLink_Call struct {
+	*mock.Call
+}
+
+// ImportLink is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link urlservice.Link
+func (_e *MockshortURLService_Expecter) ImportLink(ctx interface{}, link interface{}) *MockshortURLService_ImportLink_Call {
+	return &MockshortURLService_ImportLink_Call{Call: _e.mock.On("ImportLink", ctx, link)}
+}
+
+func (_c *MockshortURLService_ImportLink_Call) Run(run func(ctx context.Context, link urlservice.Link)) *MockshortURLService_ImportLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(urlservice.Link))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_ImportLink_Call) Return(_a0 string, _a1 error) *MockshortURLService_ImportLink_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_ImportLink_Call) RunAndReturn(run func(context.Context, urlservice.Link) (string, error)) *MockshortURLService_ImportLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListShortURLs provides a mock function with given fields: ctx, owner, cursor, size
+func (_m *MockshortURLService) ListShortURLs(ctx context.Context, owner string, cursor string, size int) (urlservice.LinkPage, error) {
+	ret := _m.Called(ctx, owner, cursor, size)
+
+	var r0 urlservice.LinkPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) (urlservice.LinkPage, error)); ok {
+		return rf(ctx, owner, cursor, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) urlservice.LinkPage); ok {
+		r0 = rf(ctx, owner, cursor, size)
+	} else {
+		r0 = ret.Get(0).(urlservice.LinkPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, owner, cursor, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_ListShortURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListShortURLs'
+type MockshortURLService_ListShortURLs_Call struct {
+	*mock.Call
+}
+
+// ListShortURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - cursor string
+//   - size int
+func (_e *MockshortURLService_Expecter) ListShortURLs(ctx interface{}, owner interface{}, cursor interface{}, size interface{}) *MockshortURLService_ListShortURLs_Call {
+	return &MockshortURLService_ListShortURLs_Call{Call: _e.mock.On("ListShortURLs", ctx, owner, cursor, size)}
+}
+
+func (_c *MockshortURLService_ListShortURLs_Call) Run(run func(ctx context.Context, owner string, cursor string, size int)) *MockshortURLService_ListShortURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_ListShortURLs_Call) Return(_a0 urlservice.LinkPage, _a1 error) *MockshortURLService_ListShortURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_ListShortURLs_Call) RunAndReturn(run func(context.Context, string, string, int) (urlservice.LinkPage, error)) *MockshortURLService_ListShortURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // OriginalURL provides a mock function with given fields: ctx, shortURL
 func (_m *MockshortURLService) OriginalURL(ctx context.Context, shortURL string) (string, error) {
 	ret := _m.Called(ctx, shortURL)
@@ -74,23 +526,98 @@ func (_c *MockshortURLService_OriginalURL_Call) RunAndReturn(run func(context.Co
 	return _c
 }
 
-// ShortURL provides a mock function with given fields: ctx, originalURL
-func (_m *MockshortURLService) ShortURL(ctx context.Context, originalURL string) (string, error) {
-	ret := _m.Called(ctx, originalURL)
+// Ping provides a mock function with given fields: ctx
+func (_m *MockshortURLService) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type MockshortURLService_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockshortURLService_Expecter) Ping(ctx interface{}) *MockshortURLService_Ping_Call {
+	return &MockshortURLService_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *MockshortURLService_Ping_Call) Run(run func(ctx context.Context)) *MockshortURLService_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_Ping_Call) Return(_a0 error) *MockshortURLService_Ping_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_Ping_Call) RunAndReturn(run func(context.Context) error) *MockshortURLService_Ping_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordClick provides a mock function with given fields: click
+func (_m *MockshortURLService) RecordClick(click analytics.Click) {
+	_m.Called(click)
+}
+
+// MockshortURLService_RecordClick_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordClick'
+type MockshortURLService_RecordClick_Call struct {
+	*mock.Call
+}
+
+// RecordClick is a helper method to define mock.On call
+//   - click analytics.Click
+func (_e *MockshortURLService_Expecter) RecordClick(click interface{}) *MockshortURLService_RecordClick_Call {
+	return &MockshortURLService_RecordClick_Call{Call: _e.mock.On("RecordClick", click)}
+}
+
+func (_c *MockshortURLService_RecordClick_Call) Run(run func(click analytics.Click)) *MockshortURLService_RecordClick_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(analytics.Click))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_RecordClick_Call) Return() *MockshortURLService_RecordClick_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockshortURLService_RecordClick_Call) RunAndReturn(run func(analytics.Click)) *MockshortURLService_RecordClick_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShortURL provides a mock function with given fields: ctx, originalURL, options
+func (_m *MockshortURLService) ShortURL(ctx context.Context, originalURL string, options urlservice.LinkOptions) (string, error) {
+	ret := _m.Called(ctx, originalURL, options)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, urlservice.LinkOptions) (string, error)); ok {
+		return rf(ctx, originalURL, options)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, urlservice.LinkOptions) string); ok {
+		r0 = rf(ctx, originalURL, options)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(1).(func(context.Context, string, urlservice.LinkOptions) error); ok {
+		r1 = rf(ctx, originalURL, options)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -106,13 +633,14 @@ type MockshortURLService_ShortURL_Call struct {
 // ShortURL is a helper method to define mock.On call
 //   - ctx context.Context
 //   - originalURL string
-func (_e *MockshortURLService_Expecter) ShortURL(ctx interface{}, originalURL interface{}) *MockshortURLService_ShortURL_Call {
-	return &MockshortURLService_ShortURL_Call{Call: _e.mock.On("ShortURL", ctx, originalURL)}
+//   - options urlservice.LinkOptions
+func (_e *MockshortURLService_Expecter) ShortURL(ctx interface{}, originalURL interface{}, options interface{}) *MockshortURLService_ShortURL_Call {
+	return &MockshortURLService_ShortURL_Call{Call: _e.mock.On("ShortURL", ctx, originalURL, options)}
 }
 
-func (_c *MockshortURLService_ShortURL_Call) Run(run func(ctx context.Context, originalURL string)) *MockshortURLService_ShortURL_Call {
+func (_c *MockshortURLService_ShortURL_Call) Run(run func(ctx context.Context, originalURL string, options urlservice.LinkOptions)) *MockshortURLService_ShortURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(urlservice.LinkOptions))
 	})
 	return _c
 }
@@ -122,7 +650,106 @@ func (_c *MockshortURLService_ShortURL_Call) Return(_a0 string, _a1 error) *Mock
 	return _c
 }
 
-func (_c *MockshortURLService_ShortURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockshortURLService_ShortURL_Call {
+func (_c *MockshortURLService_ShortURL_Call) RunAndReturn(run func(context.Context, string, urlservice.LinkOptions) (string, error)) *MockshortURLService_ShortURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Stats provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockshortURLService) Stats(ctx context.Context, owner string, shortURL string) (urlservice.Stats, error) {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 urlservice.Stats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (urlservice.Stats, error)); ok {
+		return rf(ctx, owner, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) urlservice.Stats); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		r0 = ret.Get(0).(urlservice.Stats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockshortURLService_Stats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Stats'
+type MockshortURLService_Stats_Call struct {
+	*mock.Call
+}
+
+// Stats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockshortURLService_Expecter) Stats(ctx interface{}, owner interface{}, shortURL interface{}) *MockshortURLService_Stats_Call {
+	return &MockshortURLService_Stats_Call{Call: _e.mock.On("Stats", ctx, owner, shortURL)}
+}
+
+func (_c *MockshortURLService_Stats_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockshortURLService_Stats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_Stats_Call) Return(_a0 urlservice.Stats, _a1 error) *MockshortURLService_Stats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockshortURLService_Stats_Call) RunAndReturn(run func(context.Context, string, string) (urlservice.Stats, error)) *MockshortURLService_Stats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateOriginalURL provides a mock function with given fields: ctx, owner, shortURL, originalURL
+func (_m *MockshortURLService) UpdateOriginalURL(ctx context.Context, owner string, shortURL string, originalURL string) error {
+	ret := _m.Called(ctx, owner, shortURL, originalURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL, originalURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockshortURLService_UpdateOriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOriginalURL'
+type MockshortURLService_UpdateOriginalURL_Call struct {
+	*mock.Call
+}
+
+// UpdateOriginalURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+//   - originalURL string
+func (_e *MockshortURLService_Expecter) UpdateOriginalURL(ctx interface{}, owner interface{}, shortURL interface{}, originalURL interface{}) *MockshortURLService_UpdateOriginalURL_Call {
+	return &MockshortURLService_UpdateOriginalURL_Call{Call: _e.mock.On("UpdateOriginalURL", ctx, owner, shortURL, originalURL)}
+}
+
+func (_c *MockshortURLService_UpdateOriginalURL_Call) Run(run func(ctx context.Context, owner string, shortURL string, originalURL string)) *MockshortURLService_UpdateOriginalURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockshortURLService_UpdateOriginalURL_Call) Return(_a0 error) *MockshortURLService_UpdateOriginalURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockshortURLService_UpdateOriginalURL_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockshortURLService_UpdateOriginalURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
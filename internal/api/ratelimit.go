@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"shorturl/internal/pb"
+	"shorturl/internal/ratelimit"
+)
+
+// errRateLimited is returned when the client has made too many requests.
+var errRateLimited = errors.New("rate limit exceeded")
+
+// rateLimits are limiters of requests creating and resolving short URLs. Nil limiter does not limit requests.
+type rateLimits struct {
+	create  ratelimit.Limiter
+	resolve ratelimit.Limiter
+}
+
+// WithRateLimits returns an option that limits rates of REST API requests creating and resolving
+// short URLs with separate limiters. Clients are identified by owners of API keys or by IP addresses.
+// Batch requests are charged for every URL of the batch.
+func WithRateLimits(create, resolve ratelimit.Limiter) RESTServerOption {
+	return func(server *RESTServer) {
+		server.rateLimits = rateLimits{create: create, resolve: resolve}
+	}
+}
+
+// WithGRPCRateLimits returns an option that limits rates of gRPC requests creating and resolving
+// short URLs with separate limiters. Clients are identified by owners of API keys or by IP addresses.
+// Batch requests are charged for every URL of the batch, streaming import is charged for every link.
+func WithGRPCRateLimits(create, resolve ratelimit.Limiter) GRPCServerOption {
+	return func(server *GRPCServer) {
+		server.rateLimits = rateLimits{create: create, resolve: resolve}
+	}
+}
+
+// createGRPCMethods and resolveGRPCMethods are methods of short URL service limited by create and
+// resolve limiters. Streaming import is limited for every received link.
+var (
+	createGRPCMethods = map[string]bool{
+		pb.ShortURLService_CreateShortURL_FullMethodName:       true,
+		pb.ShortURLService_BatchCreateShortURLs_FullMethodName: true,
+		pb.ShortURLService_ImportURLs_FullMethodName:           true,
+	}
+	resolveGRPCMethods = map[string]bool{
+		pb.ShortURLService_GetOriginalURL_FullMethodName:       true,
+		pb.ShortURLService_BatchGetOriginalURLs_FullMethodName: true,
+	}
+)
+
+// limiter returns the limiter of the gRPC method or nil if the method is not limited.
+func (l rateLimits) limiter(method string) ratelimit.Limiter {
+	switch {
+	case createGRPCMethods[method]:
+		return l.create
+	case resolveGRPCMethods[method]:
+		return l.resolve
+	default:
+		return nil
+	}
+}
+
+// rateLimitKey returns the key of the client: owner of API key if the request is authenticated,
+// otherwise IP address of the client.
+func rateLimitKey(ctx context.Context, clientIP string) string {
+	if owner := ownerFromContext(ctx); owner != "" {
+		return "owner:" + owner
+	}
+
+	return "ip:" + clientIP
+}
+
+// requestCost returns the cost of the gRPC request that is a count of URLs of batch requests.
+// Other requests cost one.
+func requestCost(req interface{}) int {
+	if batch, ok := req.(*pb.BatchRequest); ok {
+		return max(len(batch.Urls), 1)
+	}
+
+	return 1
+}
+
+// allowRequest asks the limiter whether the request of the cost is allowed. Errors of the limiter are logged
+// and the request is allowed, so the service keeps working if a shared limiter is unavailable.
+func allowRequest(ctx context.Context, limiter ratelimit.Limiter, key string, cost int) (ratelimit.Decision, bool) {
+	if limiter == nil {
+		return ratelimit.Decision{}, false
+	}
+
+	decision, err := limiter.AllowN(ctx, key, cost)
+	if err != nil {
+		slog.Warn("Failed to check rate limit", slog.String("error", err.Error()))
+		return ratelimit.Decision{}, false
+	}
+
+	return decision, true
+}
+
+// rateLimitMiddleware returns a handler that calls the handler only if the limiter allows the request.
+// Otherwise, it responds with code 429. If limiter is nil, the handler is returned as is.
+func rateLimitMiddleware(limiter ratelimit.Limiter, handler http.HandlerFunc) http.HandlerFunc {
+	if limiter == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !checkRateLimit(w, r, limiter, 1) {
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+// checkRateLimit sets RateLimit headers of the limiter decision and reports whether the request of
+// the cost is allowed. If it is not allowed, it also sets Retry-After header and writes an error message.
+func checkRateLimit(w http.ResponseWriter, r *http.Request, limiter ratelimit.Limiter, cost int) bool {
+	decision, isDecided := allowRequest(r.Context(), limiter, rateLimitKey(r.Context(), remoteIP(r.RemoteAddr)), cost)
+	if !isDecided {
+		return true
+	}
+
+	for name, value := range rateLimitHeaders(decision) {
+		w.Header().Set(name, value)
+	}
+
+	if !decision.Allowed {
+		writeResponse(w, "", errRateLimited)
+		return false
+	}
+
+	return true
+}
+
+// createLimited and resolveLimited return the handler limited by limiter of creating or resolving.
+func (s *RESTServer) createLimited(handler http.HandlerFunc) http.HandlerFunc {
+	return rateLimitMiddleware(s.rateLimits.create, handler)
+}
+
+func (s *RESTServer) resolveLimited(handler http.HandlerFunc) http.HandlerFunc {
+	return rateLimitMiddleware(s.rateLimits.resolve, handler)
+}
+
+func (s *GRPCServer) rateLimitUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.checkRateLimit(ctx, info.FullMethod, requestCost(req), grpc.SetHeader); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (s *GRPCServer) rateLimitStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	setHeader := func(_ context.Context, md metadata.MD) error {
+		return stream.SetHeader(md)
+	}
+
+	if info.IsClientStream {
+		return handler(srv, &rateLimitedStream{ServerStream: stream, server: s, method: info.FullMethod, setHeader: setHeader})
+	}
+
+	if err := s.checkRateLimit(stream.Context(), info.FullMethod, 1, setHeader); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// rateLimitedStream is a server stream that charges the limiter of its method for every received message.
+type rateLimitedStream struct {
+	grpc.ServerStream
+	server    *GRPCServer
+	method    string
+	setHeader func(context.Context, metadata.MD) error
+}
+
+// RecvMsg receives the message and returns an error with codes.ResourceExhausted if it is not allowed.
+func (s *rateLimitedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return s.server.checkRateLimit(s.Context(), s.method, 1, s.setHeader)
+}
+
+// checkRateLimit asks the limiter of the method whether the request of the cost is allowed and sets RateLimit
+// metadata of the decision in response header. It returns an error with codes.ResourceExhausted
+// and sets retry-after metadata if the request is not allowed.
+func (s *GRPCServer) checkRateLimit(ctx context.Context, method string, cost int, setHeader func(context.Context, metadata.MD) error) error {
+	var clientIP string
+	if p, isFound := peer.FromContext(ctx); isFound && p.Addr != nil {
+		clientIP = remoteIP(p.Addr.String())
+	}
+
+	decision, isDecided := allowRequest(ctx, s.rateLimits.limiter(method), rateLimitKey(ctx, clientIP), cost)
+	if !isDecided {
+		return nil
+	}
+
+	md := metadata.MD{}
+	for name, value := range rateLimitHeaders(decision) {
+		md.Set(name, value)
+	}
+
+	if err := setHeader(ctx, md); err != nil {
+		slog.Warn("Failed to set rate limit metadata", slog.String("error", err.Error()))
+	}
+
+	if !decision.Allowed {
+		_, code := errorStatusCodes(errRateLimited)
+		return status.Error(code, errRateLimited.Error())
+	}
+
+	return nil
+}
+
+// rateLimitHeaders returns RateLimit headers of the decision, and Retry-After header
+// if the request is not allowed. Durations are rounded up to seconds.
+func rateLimitHeaders(decision ratelimit.Decision) map[string]string {
+	headers := map[string]string{
+		"RateLimit-Limit":     strconv.Itoa(decision.Limit),
+		"RateLimit-Remaining": strconv.Itoa(decision.Remaining),
+		"RateLimit-Reset":     strconv.Itoa(ceilSeconds(decision.ResetAfter)),
+	}
+
+	if !decision.Allowed {
+		headers["Retry-After"] = strconv.Itoa(ceilSeconds(decision.RetryAfter))
+	}
+
+	return headers
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// remoteIP returns IP address of the remote address, that may contain a port.
+func remoteIP(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
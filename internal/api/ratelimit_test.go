@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"shorturl/internal/auth"
+	"shorturl/internal/pb"
+	"shorturl/internal/ratelimit"
+)
+
+var (
+	allowedDecision  = ratelimit.Decision{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 100 * time.Millisecond}
+	rejectedDecision = ratelimit.Decision{Limit: 10, ResetAfter: 2 * time.Second, RetryAfter: 1500 * time.Millisecond}
+)
+
+func TestRateLimitedRequest(t *testing.T) {
+	tests := []struct {
+		name               string
+		decision           ratelimit.Decision
+		expectedStatusCode int
+	}{
+		{
+			name:               "allowed",
+			decision:           allowedDecision,
+			expectedStatusCode: http.StatusFound,
+		},
+		{
+			name:               "rejected",
+			decision:           rejectedDecision,
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.decision.Allowed {
+				urlServiceMock.EXPECT().OriginalURL(mock.Anything, "1234567890").Return("https://example.com", nil).Once()
+				urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+			}
+
+			resolveLimiterMock := NewMockLimiter(t)
+			resolveLimiterMock.EXPECT().
+				AllowN(mock.Anything, "ip:192.0.2.1", 1).
+				Return(tt.decision, nil).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, WithRateLimits(NewMockLimiter(t), resolveLimiterMock))
+
+			request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+			request.RemoteAddr = "192.0.2.1:1234"
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assert.Equal(t, "10", recorder.Header().Get("RateLimit-Limit"))
+			if tt.decision.Allowed {
+				assert.Equal(t, "9", recorder.Header().Get("RateLimit-Remaining"))
+				assert.Equal(t, "1", recorder.Header().Get("RateLimit-Reset"))
+				assert.Empty(t, recorder.Header().Get("Retry-After"))
+				return
+			}
+
+			assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+			assert.Equal(t, "2", recorder.Header().Get("RateLimit-Reset"))
+			assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+			assertBodyContent(t, recorder)
+		})
+	}
+}
+
+func TestRateLimitedRequestByOwner(t *testing.T) {
+	createLimiterMock := NewMockLimiter(t)
+	createLimiterMock.EXPECT().
+		AllowN(mock.Anything, "owner:"+auth.HashKey(testAPIKey), 1).
+		Return(rejectedDecision, nil).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, NewMockshortURLService(t),
+		WithAuthenticator(testAuthenticator()), WithRateLimits(createLimiterMock, NewMockLimiter(t)))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(`{"url":"https://example.com"}`))
+	request.Header.Set("Authorization", "Bearer "+testAPIKey)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestRateLimitedBatchRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+	}{
+		{
+			name:   "create",
+			action: batchActionCreate,
+		},
+		{
+			name:   "resolve",
+			action: batchActionResolve,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createLimiterMock := NewMockLimiter(t)
+			resolveLimiterMock := NewMockLimiter(t)
+			limiterMock := createLimiterMock
+			if tt.action == batchActionResolve {
+				limiterMock = resolveLimiterMock
+			}
+
+			limiterMock.EXPECT().
+				AllowN(mock.Anything, mock.Anything, 2).
+				Return(rejectedDecision, nil).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, NewMockshortURLService(t), WithRateLimits(createLimiterMock, resolveLimiterMock))
+
+			body := `{"action":"` + tt.action + `","urls":["https://example.com","https://example.org"]}`
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls:batch", strings.NewReader(body))
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		})
+	}
+}
+
+func TestRateLimitedRequestWithLimiterError(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().OriginalURL(mock.Anything, "1234567890").Return("https://example.com", nil).Once()
+	urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+
+	resolveLimiterMock := NewMockLimiter(t)
+	resolveLimiterMock.EXPECT().
+		AllowN(mock.Anything, mock.Anything, 1).
+		Return(ratelimit.Decision{}, assert.AnError).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithRateLimits(nil, resolveLimiterMock))
+
+	request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusFound, recorder.Code, "Request should be allowed if limiter fails")
+	assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+}
+
+func TestRateLimitedMethod(t *testing.T) {
+	createLimiterMock := NewMockLimiter(t)
+	createLimiterMock.EXPECT().
+		AllowN(mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "ip:")
+		}), 1).
+		Return(rejectedDecision, nil).
+		Once()
+
+	client := grpcClient(t, NewMockshortURLService(t), WithGRPCRateLimits(createLimiterMock, NewMockLimiter(t)))
+
+	var header metadata.MD
+	_, err := client.CreateShortURL(context.Background(), &pb.OriginalURL{Url: "https://example.com"}, grpc.Header(&header))
+	assertCorrectGRPCCode(t, err, codes.ResourceExhausted)
+	assert.Equal(t, []string{"2"}, header.Get("retry-after"))
+	assert.Equal(t, []string{"10"}, header.Get("ratelimit-limit"))
+}
+
+func TestRateLimitedBatchMethod(t *testing.T) {
+	resolveLimiterMock := NewMockLimiter(t)
+	resolveLimiterMock.EXPECT().
+		AllowN(mock.Anything, mock.Anything, 3).
+		Return(rejectedDecision, nil).
+		Once()
+
+	client := grpcClient(t, NewMockshortURLService(t), WithGRPCRateLimits(NewMockLimiter(t), resolveLimiterMock))
+	_, err := client.BatchGetOriginalURLs(context.Background(), &pb.BatchRequest{Urls: []string{"first", "second", "third"}})
+	assertCorrectGRPCCode(t, err, codes.ResourceExhausted)
+}
+
+func TestRateLimitedStreamMethod(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().ImportLink(mock.Anything, mock.Anything).Return("1234567890", nil).Twice()
+
+	createLimiterMock := NewMockLimiter(t)
+	createLimiterMock.EXPECT().AllowN(mock.Anything, mock.Anything, 1).Return(allowedDecision, nil).Twice()
+	createLimiterMock.EXPECT().AllowN(mock.Anything, mock.Anything, 1).Return(rejectedDecision, nil).Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCRateLimits(createLimiterMock, NewMockLimiter(t)))
+	stream, err := client.ImportURLs(context.Background())
+	require.NoError(t, err)
+	for range 3 {
+		require.NoError(t, stream.Send(&pb.Link{OriginalUrl: "https://example.com"}))
+	}
+
+	_, err = stream.CloseAndRecv()
+	assertCorrectGRPCCode(t, err, codes.ResourceExhausted)
+}
+
+func TestNotRateLimitedMethod(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().Delete(mock.Anything, "", "1234567890").Return(nil).Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCRateLimits(NewMockLimiter(t), NewMockLimiter(t)))
+	_, err := client.DeleteShortURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+	assertCorrectGRPCCode(t, err, codes.OK)
+}
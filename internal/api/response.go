@@ -6,15 +6,23 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 
+	"shorturl/internal/auth"
 	"shorturl/internal/urlservice"
 )
 
 // errInvalidRequest is returned when server should respond with http.StatusBadRequest or codes.InvalidArgument
 var errInvalidRequest = errors.New("request contains invalid data")
 
+// errRouteNotFound is returned when REST API has no route for requested path.
+var errRouteNotFound = errors.New("requested path does not exist")
+
+// errExportForbidden is returned when export of all links is requested with API key.
+var errExportForbidden = errors.New("export of all links is not allowed with API key, use command export of the program")
+
 func writeNotAllowed(w http.ResponseWriter, allowedMethods []string) {
 	allowHeaderValue := strings.Join(allowedMethods, ", ")
 	w.Header().Add("Allow", allowHeaderValue)
@@ -33,6 +41,66 @@ func writeResponse(w http.ResponseWriter, requestedURL string, requestHandlingEr
 	writeResult(w, requestedURL)
 }
 
+// writeNoContentResponse sets status code 204 without body or writes JSON body with the request error
+func writeNoContentResponse(w http.ResponseWriter, requestHandlingError error) {
+	if requestHandlingError != nil {
+		w.Header().Add("Content-Type", "application/json")
+		writeError(w, requestHandlingError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeBatchResponse sets status code and writes JSON body with results of batch depending on the request error.
+// Errors of single URLs do not change status code, they are written to their results.
+func writeBatchResponse(w http.ResponseWriter, results []batchResult, requestHandlingError error) {
+	w.Header().Add("Content-Type", "application/json")
+
+	if requestHandlingError != nil {
+		writeError(w, requestHandlingError)
+		return
+	}
+
+	writeBody(w, newBatchResponse(results))
+}
+
+// writeHistoryResponse sets status code and writes JSON body with changes of original URL depending on the request error
+func writeHistoryResponse(w http.ResponseWriter, shortURL string, changes []urlservice.DestinationChange, requestHandlingError error) {
+	w.Header().Add("Content-Type", "application/json")
+
+	if requestHandlingError != nil {
+		writeError(w, requestHandlingError)
+		return
+	}
+
+	writeBody(w, newHistoryResponse(shortURL, changes))
+}
+
+// writeLinkPageResponse sets status code and writes JSON body with the page of links depending on the request error
+func writeLinkPageResponse(w http.ResponseWriter, page urlservice.LinkPage, requestHandlingError error) {
+	w.Header().Add("Content-Type", "application/json")
+
+	if requestHandlingError != nil {
+		writeError(w, requestHandlingError)
+		return
+	}
+
+	writeBody(w, newLinkPageResponse(page))
+}
+
+// writeStatsResponse sets status code and writes JSON body with statistics depending on the request error
+func writeStatsResponse(w http.ResponseWriter, stats urlservice.Stats, requestHandlingError error) {
+	w.Header().Add("Content-Type", "application/json")
+
+	if requestHandlingError != nil {
+		writeError(w, requestHandlingError)
+		return
+	}
+
+	writeBody(w, newStatsResponse(stats))
+}
+
 func writeError(w http.ResponseWriter, requestHandlingError error) {
 	statusCode, _ := errorStatusCodes(requestHandlingError)
 	w.WriteHeader(statusCode)
@@ -56,9 +124,23 @@ func writeResult(w http.ResponseWriter, result string) {
 // and gRPC error codes that should be set in response.
 func errorStatusCodes(requestHandlingError error) (httpCode int, gRPCCode codes.Code) {
 	switch {
-	case errors.Is(requestHandlingError, errInvalidRequest):
+	case errors.Is(requestHandlingError, errInvalidRequest), errors.Is(requestHandlingError, urlservice.ErrInvalidAlias),
+		errors.Is(requestHandlingError, urlservice.ErrInvalidExpiration), errors.Is(requestHandlingError, urlservice.ErrBatchTooLarge),
+		errors.Is(requestHandlingError, urlservice.ErrInvalidPage):
 		return http.StatusBadRequest, codes.InvalidArgument
-	case errors.Is(requestHandlingError, urlservice.ErrURLNotFound):
+	case errors.Is(requestHandlingError, urlservice.ErrAliasTaken):
+		return http.StatusConflict, codes.AlreadyExists
+	case errors.Is(requestHandlingError, urlservice.ErrURLExpired):
+		return http.StatusGone, codes.FailedPrecondition
+	case errors.Is(requestHandlingError, urlservice.ErrURLDisabled):
+		return http.StatusForbidden, codes.FailedPrecondition
+	case errors.Is(requestHandlingError, auth.ErrUnauthenticated), errors.Is(requestHandlingError, urlservice.ErrOwnerRequired):
+		return http.StatusUnauthorized, codes.Unauthenticated
+	case errors.Is(requestHandlingError, errExportForbidden):
+		return http.StatusForbidden, codes.PermissionDenied
+	case errors.Is(requestHandlingError, errRateLimited):
+		return http.StatusTooManyRequests, codes.ResourceExhausted
+	case errors.Is(requestHandlingError, urlservice.ErrURLNotFound), errors.Is(requestHandlingError, errRouteNotFound):
 		return http.StatusNotFound, codes.NotFound
 	default:
 		return http.StatusInternalServerError, codes.Internal
@@ -80,3 +162,146 @@ func writeBody(w http.ResponseWriter, respBody any) {
 func logError(msg string, err error) {
 	slog.Error(msg, slog.String("error", err.Error()))
 }
+
+// statsResponse is a JSON body of response with click statistics. LastClickAt is omitted if there were no clicks.
+type statsResponse struct {
+	URL         string          `json:"url"`
+	Clicks      int64           `json:"clicks"`
+	LastClickAt *time.Time      `json:"last_click_at,omitempty"`
+	Days        []clickCountDTO `json:"days"`
+	Referrers   []clickCountDTO `json:"referrers"`
+	UserAgents  []clickCountDTO `json:"user_agents"`
+	Networks    []clickCountDTO `json:"networks"`
+}
+
+type clickCountDTO struct {
+	Value  string `json:"value"`
+	Clicks int64  `json:"clicks"`
+}
+
+func newStatsResponse(stats urlservice.Stats) statsResponse {
+	resp := statsResponse{
+		URL:        stats.ShortURL,
+		Clicks:     stats.Clicks,
+		Days:       newClickCountDTOs(stats.Days),
+		Referrers:  newClickCountDTOs(stats.Referrers),
+		UserAgents: newClickCountDTOs(stats.UserAgents),
+		Networks:   newClickCountDTOs(stats.Networks),
+	}
+
+	if !stats.LastClickAt.IsZero() {
+		resp.LastClickAt = &stats.LastClickAt
+	}
+
+	return resp
+}
+
+func newClickCountDTOs(counts []urlservice.ClickCount) []clickCountDTO {
+	result := make([]clickCountDTO, 0, len(counts))
+	for _, count := range counts {
+		result = append(result, clickCountDTO{Value: count.Value, Clicks: count.Clicks})
+	}
+
+	return result
+}
+
+// historyResponse is a JSON body of response with changes of original URL, the oldest first.
+type historyResponse struct {
+	URL     string                 `json:"url"`
+	Changes []destinationChangeDTO `json:"changes"`
+}
+
+type destinationChangeDTO struct {
+	PreviousURL string    `json:"previous_url"`
+	NewURL      string    `json:"new_url"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+func newHistoryResponse(shortURL string, changes []urlservice.DestinationChange) historyResponse {
+	resp := historyResponse{
+		URL:     shortURL,
+		Changes: make([]destinationChangeDTO, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, destinationChangeDTO{
+			PreviousURL: change.PreviousURL,
+			NewURL:      change.NewURL,
+			ChangedAt:   change.ChangedAt,
+		})
+	}
+
+	return resp
+}
+
+// linkPageResponse is a JSON body of response with a page of links. NextCursor is omitted on the last page.
+type linkPageResponse struct {
+	URLs       []linkDTO `json:"urls"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// linkDTO is a link in responses, expiration time is omitted for links that never expire.
+type linkDTO struct {
+	URL         string     `json:"url"`
+	OriginalURL string     `json:"original_url"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Disabled    bool       `json:"disabled"`
+}
+
+func newLinkPageResponse(page urlservice.LinkPage) linkPageResponse {
+	resp := linkPageResponse{
+		URLs:       make([]linkDTO, 0, len(page.Links)),
+		NextCursor: page.NextCursor,
+	}
+
+	for _, link := range page.Links {
+		dto := linkDTO{
+			URL:         link.ShortURL,
+			OriginalURL: link.OriginalURL,
+			Disabled:    link.Disabled,
+		}
+
+		if !link.ExpiresAt.IsZero() {
+			dto.ExpiresAt = &link.ExpiresAt
+		}
+
+		resp.URLs = append(resp.URLs, dto)
+	}
+
+	return resp
+}
+
+// batchResponse is a JSON body of response with results of batch in the order of requested URLs.
+// Error and status are set only for URLs that are not processed, status is HTTP status code of the error.
+type batchResponse struct {
+	Results []batchResultDTO `json:"results"`
+}
+
+type batchResultDTO struct {
+	URL         string `json:"url,omitempty"`
+	OriginalURL string `json:"original_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Status      int    `json:"status,omitempty"`
+}
+
+func newBatchResponse(results []batchResult) batchResponse {
+	resp := batchResponse{
+		Results: make([]batchResultDTO, 0, len(results)),
+	}
+
+	for _, result := range results {
+		dto := batchResultDTO{
+			URL:         result.shortURL,
+			OriginalURL: result.originalURL,
+		}
+
+		if result.err != nil {
+			dto.Error = result.err.Error()
+			dto.Status, _ = errorStatusCodes(result.err)
+		}
+
+		resp.Results = append(resp.Results, dto)
+	}
+
+	return resp
+}
@@ -2,111 +2,397 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	"net/url"
-	"strings"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"shorturl/internal/urlservice"
+	"shorturl/internal/urlservice/analytics"
 )
 
 // RESTServer is REST API server implementation that processing requests to short URL service.
 // It must be initialized with NewRESTServer
 type RESTServer struct {
-	server     *http.Server
-	urlService ShortURLService
+	server             *http.Server
+	urlService         ShortURLService
+	redirectStatusCode int
+	authenticator      Authenticator
+	rateLimits         rateLimits
+	metrics            RequestMetrics
+	metricsHandler     http.Handler
+	tracing            *tracing
+	draining           atomic.Bool
+
+	tlsConfig             *tls.Config
+	redirectListenAddress string
+	redirectServer        *http.Server
+}
+
+// RESTServerOption is used to set optional settings of RESTServer on its initialization.
+type RESTServerOption func(server *RESTServer)
+
+// WithRedirectStatusCode returns an option that sets status code of responses
+// redirecting short URLs to original ones. The code should be checked with
+// IsRedirectStatusCode before, the default value is http.StatusFound.
+func WithRedirectStatusCode(code int) RESTServerOption {
+	return func(server *RESTServer) {
+		server.redirectStatusCode = code
+	}
+}
+
+// IsRedirectStatusCode reports whether the code can be used by RESTServer to redirect requests.
+func IsRedirectStatusCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	default:
+		return false
+	}
 }
 
-// NewRESTServer initializes RESTServer with its address to listen, and short URL service.
-// It returns a pointer to object.
-func NewRESTServer(listenAddress string, urlService ShortURLService) *RESTServer {
+// NewRESTServer initializes RESTServer with its address to listen, short URL service
+// and optional settings. It returns a pointer to object.
+func NewRESTServer(listenAddress string, urlService ShortURLService, options ...RESTServerOption) *RESTServer {
 	server := &RESTServer{
-		urlService: urlService,
+		urlService:         urlService,
+		redirectStatusCode: http.StatusFound,
+	}
+
+	for _, option := range options {
+		option(server)
 	}
 
 	server.initHTTPServer(listenAddress)
 	return server
 }
 
-// Run is calling method ListenAndServe of object's http.Server and will return its error.
+// Run is calling method ListenAndServe of object's http.Server, or ListenAndServeTLS if TLS is configured,
+// and will return its error. If HTTPS redirect is configured, its server is run too and the first error is returned.
 func (s *RESTServer) Run() error {
-	return s.server.ListenAndServe()
+	if s.redirectServer == nil {
+		return s.listenAndServe()
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- s.listenAndServe()
+	}()
+
+	go func() {
+		errCh <- s.redirectServer.ListenAndServe()
+	}()
+
+	return <-errCh
 }
 
-// Shutdown is calling method Shutdown of object's http.Server and will return its error.
+// Shutdown is calling method Shutdown of object's http.Server and HTTPS redirect server and will return their errors.
 func (s *RESTServer) Shutdown(ctx context.Context) error {
-	return s.server.Shutdown(ctx)
+	if s.redirectServer == nil {
+		return s.server.Shutdown(ctx)
+	}
+
+	return errors.Join(s.server.Shutdown(ctx), s.redirectServer.Shutdown(ctx))
+}
+
+func (s *RESTServer) listenAndServe() error {
+	if s.server.TLSConfig != nil {
+		// Certificates are provided by TLS configuration.
+		return s.server.ListenAndServeTLS("", "")
+	}
+
+	return s.server.ListenAndServe()
 }
 
 // initHTTPServer is setting http.Server field of the object with its handler registration.
+// HTTPS redirect server is set if TLS is configured with redirect listen address.
 func (s *RESTServer) initHTTPServer(listenAddress string) {
-	mux := http.NewServeMux()
-	mux.Handle("/", loggingMiddleware(s.handleHTTP()))
-
 	s.server = &http.Server{
-		Handler: mux,
-		Addr:    listenAddress,
+		Handler:   s.newRouter(),
+		Addr:      listenAddress,
+		TLSConfig: s.tlsConfig,
+	}
+
+	if s.tlsConfig == nil || s.redirectListenAddress == "" {
+		return
+	}
+
+	port := httpsPort
+	if _, listenPort, err := net.SplitHostPort(listenAddress); err == nil {
+		port = listenPort
+	}
+
+	s.redirectServer = &http.Server{
+		Handler: newHTTPSRedirectHandler(port),
+		Addr:    s.redirectListenAddress,
 	}
 }
 
-// handleHTTP is a handler for "/" path. It determines the request method and
-// calls the corresponding handler. If the method is not allowed, it sets Allow
-// handler and returns code 405. Else it processes the request and handles its result.
+// handleCreateURL is processing a request to create a short URL and handles its result.
 // It will write needed status code and body with a result url or error message.
-func (s *RESTServer) handleHTTP() http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		var resultURL string
-		var err error
-		switch r.Method {
-		case http.MethodPost:
-			resultURL, err = s.handlePost(r)
-		case http.MethodGet:
-			resultURL, err = s.handleGet(r)
-		default:
-			allowedMethods := []string{http.MethodPost, http.MethodGet}
-			writeNotAllowed(w, allowedMethods)
-			return
-		}
+func (s *RESTServer) handleCreateURL(w http.ResponseWriter, r *http.Request) {
+	body, err := creationRequestFromBody(r)
+	if err != nil {
+		writeResponse(w, "", errors.Join(errInvalidRequest, err))
+		return
+	}
+
+	resultURL, err := handleCreationShortURL(r.Context(), body.URL, body.linkOptions(), s.urlService)
+	writeResponse(w, resultURL, err)
+}
 
-		writeResponse(w, resultURL, err)
+// handleListURLs is writing a page of short URLs owned by the owner of API key or an error message.
+// Owner must be requested as "me", because owners are identified by API keys.
+func (s *RESTServer) handleListURLs(w http.ResponseWriter, r *http.Request) {
+	query, err := listQueryFromRequest(r)
+	if err != nil {
+		writeLinkPageResponse(w, urlservice.LinkPage{}, errors.Join(errInvalidRequest, err))
+		return
 	}
+
+	page, err := handleListShortURLs(r.Context(), query.cursor, query.limit, s.urlService)
+	writeLinkPageResponse(w, page, err)
 }
 
-func (s *RESTServer) handlePost(r *http.Request) (string, error) {
-	rawURL, err := rawURLFromRequestBody(r)
+// handleBatch is creating or resolving all URLs of the batch depending on the requested action.
+// Creation requires API key if server has authenticator, resolving stays public like resolving of
+// single short URLs. Rate limit is charged for every URL of the batch. It writes results of all URLs
+// or an error message if the whole batch is failed.
+func (s *RESTServer) handleBatch(w http.ResponseWriter, r *http.Request) {
+	body, err := batchRequestFromBody(r)
 	if err != nil {
-		return "", errors.Join(errInvalidRequest, err)
+		writeBatchResponse(w, nil, errors.Join(errInvalidRequest, err))
+		return
 	}
 
-	return handleCreationShortURL(r.Context(), rawURL, s.urlService)
+	switch body.Action {
+	case batchActionCreate:
+		s.protected(func(w http.ResponseWriter, r *http.Request) {
+			s.handleBatchCreation(w, r, body.URLs)
+		})(w, r)
+	case batchActionResolve:
+		s.handleBatchResolving(w, r, body.URLs)
+	}
 }
 
-func (s *RESTServer) handleGet(r *http.Request) (string, error) {
-	shortURL := strings.TrimPrefix(r.URL.Path, "/")
-	return handleGetOriginalURL(r.Context(), shortURL, s.urlService)
+func (s *RESTServer) handleBatchCreation(w http.ResponseWriter, r *http.Request, originalURLs []string) {
+	if !checkRateLimit(w, r, s.rateLimits.create, max(len(originalURLs), 1)) {
+		return
+	}
+
+	results, err := handleBatchCreation(r.Context(), originalURLs, s.urlService)
+	writeBatchResponse(w, results, err)
 }
 
-func rawURLFromRequestBody(r *http.Request) (string, error) {
-	var body struct {
-		URL string `json:"url"`
+func (s *RESTServer) handleBatchResolving(w http.ResponseWriter, r *http.Request, shortURLs []string) {
+	if !checkRateLimit(w, r, s.rateLimits.resolve, max(len(shortURLs), 1)) {
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		return "", fmt.Errorf("invalid json with url: %w", err)
+	results, err := handleBatchResolving(r.Context(), shortURLs, s.urlService)
+	writeBatchResponse(w, results, err)
+}
+
+// handleGetURL is processing a request to look up the original URL and handles its result.
+// It will write needed status code and body with a result url or error message.
+func (s *RESTServer) handleGetURL(w http.ResponseWriter, r *http.Request) {
+	resultURL, err := handleResolveShortURL(r.Context(), clickFromRequest(r), s.urlService)
+	writeResponse(w, resultURL, err)
+}
+
+// handleRedirect is looking for the original URL and redirects request to it
+// with the configured status code. If it is failed, it writes an error message.
+func (s *RESTServer) handleRedirect(w http.ResponseWriter, r *http.Request) {
+	originalURL, err := handleResolveShortURL(r.Context(), clickFromRequest(r), s.urlService)
+	if err != nil {
+		writeResponse(w, "", err)
+		return
 	}
 
-	return body.URL, nil
+	http.Redirect(w, r, originalURL, s.redirectStatusCode)
+}
+
+// handleDeleteURL is deleting the short URL. It responds with code 204 or writes an error message.
+func (s *RESTServer) handleDeleteURL(w http.ResponseWriter, r *http.Request) {
+	err := handleDeleteShortURL(r.Context(), r.PathValue(shortURLPathValue), s.urlService)
+	writeNoContentResponse(w, err)
 }
 
-func validateURL(rawURL string) (string, error) {
-	if rawURL == "" {
-		return "", errors.New("missing url in request")
+// handlePatchURL is changing the status of short URL. It responds with code 204 or writes an error message.
+func (s *RESTServer) handlePatchURL(w http.ResponseWriter, r *http.Request) {
+	patch, err := patchRequestFromBody(r)
+	if err != nil {
+		writeNoContentResponse(w, errors.Join(errInvalidRequest, err))
+		return
 	}
 
-	parsedURL, err := url.Parse(rawURL)
+	err = handleSetDisabled(r.Context(), r.PathValue(shortURLPathValue), *patch.Disabled, s.urlService)
+	writeNoContentResponse(w, err)
+}
+
+// handlePutURL is mapping the short URL with the original URL from request body, that must contain only url.
+// It writes the short URL or an error message.
+func (s *RESTServer) handlePutURL(w http.ResponseWriter, r *http.Request) {
+	shortURL := r.PathValue(shortURLPathValue)
+	body, err := updateRequestFromBody(r)
 	if err != nil {
-		return "", fmt.Errorf("invalid url: %w", err)
+		writeResponse(w, "", errors.Join(errInvalidRequest, err))
+		return
+	}
+
+	err = handleUpdateShortURL(r.Context(), shortURL, body.URL, s.urlService)
+	writeResponse(w, shortURL, err)
+}
+
+// handleGetHistory is writing changes of original URL mapped with the short URL or an error message.
+func (s *RESTServer) handleGetHistory(w http.ResponseWriter, r *http.Request) {
+	shortURL := r.PathValue(shortURLPathValue)
+	changes, err := handleGetHistory(r.Context(), shortURL, s.urlService)
+	writeHistoryResponse(w, shortURL, changes, err)
+}
+
+// handleGetStats is writing click statistics of the short URL or an error message.
+func (s *RESTServer) handleGetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := handleGetStats(r.Context(), r.PathValue(shortURLPathValue), s.urlService)
+	writeStatsResponse(w, stats, err)
+}
+
+// handleHealth is responding that the server is alive. It does not check the storage.
+func (s *RESTServer) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	respBody := struct {
+		Status string `json:"status"`
+	}{"ok"}
+
+	writeBody(w, respBody)
+}
+
+// creationRequest is a JSON body of request to create a short URL. All fields except URL are optional.
+type creationRequest struct {
+	URL        string    `json:"url"`
+	Alias      string    `json:"alias"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	TTLSeconds int64     `json:"ttl_seconds"`
+}
+
+func (r creationRequest) linkOptions() urlservice.LinkOptions {
+	return urlservice.LinkOptions{
+		Alias:     r.Alias,
+		ExpiresAt: r.ExpiresAt,
+		TTL:       time.Duration(r.TTLSeconds) * time.Second,
+	}
+}
+
+func creationRequestFromBody(r *http.Request) (creationRequest, error) {
+	var body creationRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return creationRequest{}, fmt.Errorf("invalid json with url: %w", err)
+	}
+
+	return body, nil
+}
+
+const (
+	batchActionCreate  = "create"
+	batchActionResolve = "resolve"
+)
+
+// batchRequest is a JSON body of batch request. Action is "create" to get short URLs
+// for original URLs or "resolve" to get original URLs for short URLs.
+type batchRequest struct {
+	Action string   `json:"action"`
+	URLs   []string `json:"urls"`
+}
+
+func batchRequestFromBody(r *http.Request) (batchRequest, error) {
+	var body batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return batchRequest{}, fmt.Errorf("invalid json with batch: %w", err)
 	}
 
-	return parsedURL.String(), nil
+	if body.Action != batchActionCreate && body.Action != batchActionResolve {
+		return batchRequest{}, fmt.Errorf("unknown batch action %q, expected %q or %q", body.Action, batchActionCreate, batchActionResolve)
+	}
+
+	return body, nil
+}
+
+// ownerMe is the only supported value of owner in query of listing, it means the owner of API key.
+const ownerMe = "me"
+
+// listQuery is a query of request to list short URLs. Limit is zero if it is not set.
+type listQuery struct {
+	cursor string
+	limit  int
+}
+
+func listQueryFromRequest(r *http.Request) (listQuery, error) {
+	values := r.URL.Query()
+	if owner := values.Get("owner"); owner != ownerMe {
+		return listQuery{}, fmt.Errorf("unsupported owner %q, expected %q", owner, ownerMe)
+	}
+
+	query := listQuery{cursor: values.Get("cursor")}
+	if rawLimit := values.Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil {
+			return listQuery{}, fmt.Errorf("limit is not int: %w", err)
+		}
+
+		query.limit = limit
+	}
+
+	return query, nil
+}
+
+// updateRequest is a JSON body of request to map the short URL with another original URL.
+// Other settings of the link are kept, so the body must not contain other fields.
+type updateRequest struct {
+	URL string `json:"url"`
+}
+
+func updateRequestFromBody(r *http.Request) (updateRequest, error) {
+	var body updateRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		return updateRequest{}, fmt.Errorf("invalid json with url: %w", err)
+	}
+
+	return body, nil
+}
+
+// patchRequest is a JSON body of request to change the status of short URL.
+type patchRequest struct {
+	Disabled *bool `json:"disabled"`
+}
+
+func patchRequestFromBody(r *http.Request) (patchRequest, error) {
+	var body patchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return patchRequest{}, fmt.Errorf("invalid json with status: %w", err)
+	}
+
+	if body.Disabled == nil {
+		return patchRequest{}, errors.New("missing disabled in request")
+	}
+
+	return body, nil
+}
+
+// clickFromRequest returns a click on the requested short URL made by the request client.
+func clickFromRequest(r *http.Request) analytics.Click {
+	return analytics.Click{
+		ShortURL:  r.PathValue(shortURLPathValue),
+		Time:      time.Now(),
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		ClientIP:  remoteIP(r.RemoteAddr),
+	}
 }
@@ -4,18 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
+	"shorturl/internal/auth"
 	"shorturl/internal/urlservice"
+	"shorturl/internal/urlservice/analytics"
 )
 
 type requestResult struct {
@@ -24,19 +29,98 @@ type requestResult struct {
 }
 
 func TestRequestWithNotAllowedMethod(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		allowedMethods []string
+	}{
+		{
+			name:           "urls collection",
+			method:         http.MethodPatch,
+			path:           "/api/v1/urls",
+			allowedMethods: []string{http.MethodPost},
+		},
+		{
+			name:           "url resource",
+			method:         http.MethodPost,
+			path:           "/api/v1/urls/1234567890",
+			allowedMethods: []string{http.MethodDelete, http.MethodGet, http.MethodPatch, http.MethodPut},
+		},
+		{
+			name:           "redirect",
+			method:         http.MethodPost,
+			path:           "/1234567890",
+			allowedMethods: []string{http.MethodGet},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+			assertBodyContent(t, recorder)
+			require.NotEmpty(t, recorder.Header().Get("Allow"), "Allow header should be set")
+			for _, method := range tt.allowedMethods {
+				assert.Contains(t, recorder.Header().Get("Allow"), method)
+			}
+		})
+	}
+}
+
+func TestRequestToUnknownPath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{
+			name: "root",
+			path: "/",
+		},
+		{
+			name: "empty short url",
+			path: "/api/v1/urls/",
+		},
+		{
+			name: "unknown resource",
+			path: "/api/v1/unknown/1234567890",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusNotFound, recorder.Code)
+			assertBodyContent(t, recorder)
+		})
+	}
+}
+
+func TestHealthRequest(t *testing.T) {
 	urlServiceMock := NewMockshortURLService(t)
 	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
 	sut := NewRESTServer(listenAddr, urlServiceMock)
 
-	request := httptest.NewRequest(http.MethodPatch, "/", nil)
+	request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
 	recorder := httptest.NewRecorder()
 	sut.server.Handler.ServeHTTP(recorder, request)
 
-	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
-	assertBodyContent(t, recorder)
-	require.NotEmpty(t, recorder.Header().Get("Allow"), "Allow header should be set")
-	assert.Contains(t, recorder.Header().Get("Allow"), http.MethodPost)
-	assert.Contains(t, recorder.Header().Get("Allow"), http.MethodGet)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
 }
 
 func TestGetRequest(t *testing.T) {
@@ -49,17 +133,356 @@ func TestGetRequest(t *testing.T) {
 	}{
 		{
 			name:               "short url exists",
-			path:               "/" + existingShortURL,
+			path:               "/api/v1/urls/" + existingShortURL,
 			expectedStatusCode: http.StatusOK,
 		},
+		{
+			name:               "short url does not exist",
+			path:               "/api/v1/urls/1111111111",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				OriginalURL(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, shortURL string) (string, error) {
+					if shortURL == existingShortURL {
+						return "https://example.com/", nil
+					}
+
+					return "", urlservice.ErrURLNotFound
+				}).
+				Once()
+			if tt.expectedStatusCode == http.StatusOK {
+				urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			assertBodyContent(t, recorder)
+		})
+	}
+}
+
+func TestRedirectRequest(t *testing.T) {
+	const (
+		existingShortURL = "1234567890"
+		originalURL      = "https://example.com/"
+	)
+
+	tests := []struct {
+		name               string
+		path               string
+		options            []RESTServerOption
+		expectedStatusCode int
+	}{
+		{
+			name:               "short url exists",
+			path:               "/" + existingShortURL,
+			expectedStatusCode: http.StatusFound,
+		},
+		{
+			name:               "short url exists with configured status code",
+			path:               "/" + existingShortURL,
+			options:            []RESTServerOption{WithRedirectStatusCode(http.StatusPermanentRedirect)},
+			expectedStatusCode: http.StatusPermanentRedirect,
+		},
 		{
 			name:               "short url does not exist",
 			path:               "/1111111111",
 			expectedStatusCode: http.StatusNotFound,
 		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				OriginalURL(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, shortURL string) (string, error) {
+					if shortURL == existingShortURL {
+						return originalURL, nil
+					}
+
+					return "", urlservice.ErrURLNotFound
+				}).
+				Once()
+			if IsRedirectStatusCode(tt.expectedStatusCode) {
+				urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, tt.options...)
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if !IsRedirectStatusCode(recorder.Code) {
+				assertBodyContent(t, recorder)
+				return
+			}
+
+			assert.Equal(t, originalURL, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestRedirectRequestRecordsClick(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		Return("https://example.com/", nil).
+		Once()
+	urlServiceMock.EXPECT().
+		RecordClick(mock.Anything).
+		Run(func(click analytics.Click) {
+			assert.Equal(t, "1234567890", click.ShortURL)
+			assert.Equal(t, "https://news.example.org/post", click.Referrer)
+			assert.Equal(t, "test-agent", click.UserAgent)
+			assert.Equal(t, "192.0.2.10", click.ClientIP)
+			assert.False(t, click.Time.IsZero())
+		}).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock)
+
+	request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+	request.RemoteAddr = "192.0.2.10:51234"
+	request.Header.Set("Referer", "https://news.example.org/post")
+	request.Header.Set("User-Agent", "test-agent")
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusFound, recorder.Code)
+}
+
+func TestGetStatsRequest(t *testing.T) {
+	const existingShortURL = "1234567890"
+	lastClickAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "short url exists",
+			path:               "/api/v1/urls/" + existingShortURL + "/stats",
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"url":"1234567890","clicks":3,"last_click_at":"2024-03-02T10:00:00Z",
+				"days":[{"value":"2024-03-01","clicks":1},{"value":"2024-03-02","clicks":2}],
+				"referrers":[{"value":"direct","clicks":3}],"user_agents":[],"networks":[]}`,
+		},
+		{
+			name:               "short url does not exist",
+			path:               "/api/v1/urls/1111111111/stats",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				Stats(mock.Anything, "", mock.Anything).
+				RunAndReturn(func(_ context.Context, _, shortURL string) (urlservice.Stats, error) {
+					if shortURL != existingShortURL {
+						return urlservice.Stats{}, urlservice.ErrURLNotFound
+					}
+
+					return urlservice.Stats{
+						ShortURL:    shortURL,
+						Clicks:      3,
+						LastClickAt: lastClickAt,
+						Days:        []urlservice.ClickCount{{Value: "2024-03-01", Clicks: 1}, {Value: "2024-03-02", Clicks: 2}},
+						Referrers:   []urlservice.ClickCount{{Value: "direct", Clicks: 3}},
+					}, nil
+				}).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedBody == "" {
+				assertBodyContent(t, recorder)
+				return
+			}
+
+			assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestDeleteRequest(t *testing.T) {
+	const existingShortURL = "1234567890"
+
+	tests := []struct {
+		name               string
+		path               string
+		expectedStatusCode int
+	}{
+		{
+			name:               "short url exists",
+			path:               "/api/v1/urls/" + existingShortURL,
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "short url does not exist",
+			path:               "/api/v1/urls/1111111111",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				Delete(mock.Anything, "", mock.Anything).
+				RunAndReturn(func(_ context.Context, _, shortURL string) error {
+					if shortURL == existingShortURL {
+						return nil
+					}
+
+					return urlservice.ErrURLNotFound
+				}).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode == http.StatusNoContent {
+				assert.Empty(t, recorder.Body.String())
+				return
+			}
+
+			assertBodyContent(t, recorder)
+		})
+	}
+}
+
+func TestPatchRequest(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		expectedCall       string
+		serviceError       error
+		expectedStatusCode int
+	}{
+		{
+			name:               "disable",
+			body:               `{"disabled":true}`,
+			expectedCall:       "Disable",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "enable",
+			body:               `{"disabled":false}`,
+			expectedCall:       "Enable",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "short url does not exist",
+			body:               `{"disabled":true}`,
+			expectedCall:       "Disable",
+			serviceError:       urlservice.ErrURLNotFound,
+			expectedStatusCode: http.StatusNotFound,
+		},
 		{
-			name:               "empty short url",
-			path:               "/",
+			name:               "missing status",
+			body:               `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid json",
+			body:               `{"disabled":`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			switch tt.expectedCall {
+			case "Disable":
+				urlServiceMock.EXPECT().Disable(mock.Anything, "", "1234567890").Return(tt.serviceError).Once()
+			case "Enable":
+				urlServiceMock.EXPECT().Enable(mock.Anything, "", "1234567890").Return(tt.serviceError).Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodPatch, "/api/v1/urls/1234567890", strings.NewReader(tt.body))
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode != http.StatusNoContent {
+				assertBodyContent(t, recorder)
+			}
+		})
+	}
+}
+
+func TestPutRequest(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		serviceError       error
+		expectedStatusCode int
+	}{
+		{
+			name:               "short url exists",
+			body:               `{"url":"https://example.com/new"}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "short url does not exist",
+			body:               `{"url":"https://example.com/new"}`,
+			serviceError:       urlservice.ErrURLNotFound,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "missing url",
+			body:               `{}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "invalid json",
+			body:               `{"url":`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "ignored creation settings",
+			body:               `{"url":"https://example.com/new","alias":"alias","ttl_seconds":60}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown field",
+			body:               `{"url":"https://example.com/new","disabled":true}`,
 			expectedStatusCode: http.StatusBadRequest,
 		},
 	}
@@ -69,21 +492,15 @@ func TestGetRequest(t *testing.T) {
 			urlServiceMock := NewMockshortURLService(t)
 			if tt.expectedStatusCode != http.StatusBadRequest {
 				urlServiceMock.EXPECT().
-					OriginalURL(mock.Anything, mock.Anything).
-					RunAndReturn(func(_ context.Context, shortURL string) (string, error) {
-						if shortURL == existingShortURL {
-							return "https://example.com/", nil
-						}
-
-						return "", urlservice.ErrURLNotFound
-					}).
+					UpdateOriginalURL(mock.Anything, "", "1234567890", "https://example.com/new").
+					Return(tt.serviceError).
 					Once()
 			}
 
 			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
 			sut := NewRESTServer(listenAddr, urlServiceMock)
 
-			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			request := httptest.NewRequest(http.MethodPut, "/api/v1/urls/1234567890", strings.NewReader(tt.body))
 			recorder := httptest.NewRecorder()
 			sut.server.Handler.ServeHTTP(recorder, request)
 
@@ -93,6 +510,150 @@ func TestGetRequest(t *testing.T) {
 	}
 }
 
+func TestGetHistoryRequest(t *testing.T) {
+	changedAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		History(mock.Anything, "", "1234567890").
+		Return([]urlservice.DestinationChange{{PreviousURL: "https://example.com/old", NewURL: "https://example.com/new", ChangedAt: changedAt}}, nil).
+		Once()
+	urlServiceMock.EXPECT().
+		History(mock.Anything, "", "1111111111").
+		Return(nil, urlservice.ErrURLNotFound).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/urls/1234567890/history", nil)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"url":"1234567890","changes":[{"previous_url":"https://example.com/old",
+		"new_url":"https://example.com/new","changed_at":"2024-03-02T10:00:00Z"}]}`, recorder.Body.String())
+
+	request = httptest.NewRequest(http.MethodGet, "/api/v1/urls/1111111111/history", nil)
+	recorder = httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+	assertBodyContent(t, recorder)
+}
+
+func TestBatchRequest(t *testing.T) {
+	tests := []struct {
+		name               string
+		body               string
+		setupMock          func(urlServiceMock *MockshortURLService)
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name: "create",
+			body: `{"action":"create","urls":["https://example.com/1","","https://example.com/2"]}`,
+			setupMock: func(urlServiceMock *MockshortURLService) {
+				urlServiceMock.EXPECT().
+					BatchShortURLs(mock.Anything, "", []string{"https://example.com/1", "https://example.com/2"}).
+					Return([]string{"1111111111", "2222222222"}, nil).
+					Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"url":"1111111111","original_url":"https://example.com/1"},
+				{"error":"request contains invalid data\nmissing original url","status":400},
+				{"url":"2222222222","original_url":"https://example.com/2"}]}`,
+		},
+		{
+			name: "resolve",
+			body: `{"action":"resolve","urls":["1111111111","2222222222"]}`,
+			setupMock: func(urlServiceMock *MockshortURLService) {
+				urlServiceMock.EXPECT().
+					BatchOriginalURLs(mock.Anything, []string{"1111111111", "2222222222"}).
+					Return([]urlservice.ResolveResult{
+						{ShortURL: "1111111111", OriginalURL: "https://example.com/1"},
+						{ShortURL: "2222222222", Err: urlservice.ErrURLNotFound},
+					}, nil).
+					Once()
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedBody: `{"results":[{"url":"1111111111","original_url":"https://example.com/1"},
+				{"url":"2222222222","error":"requested short url has no matches","status":404}]}`,
+		},
+		{
+			name: "batch error",
+			body: `{"action":"create","urls":["https://example.com/1"]}`,
+			setupMock: func(urlServiceMock *MockshortURLService) {
+				urlServiceMock.EXPECT().
+					BatchShortURLs(mock.Anything, mock.Anything, mock.Anything).
+					Return(nil, errors.New("some error")).
+					Once()
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "too large batch",
+			body:               `{"action":"create","urls":[` + strings.Repeat(`"https://example.com/",`, urlservice.MaxBatchSize) + `""]}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unknown action",
+			body:               `{"action":"delete","urls":["1111111111"]}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.setupMock != nil {
+				tt.setupMock(urlServiceMock)
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls:batch", strings.NewReader(tt.body))
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedBody == "" {
+				assertBodyContent(t, recorder)
+				return
+			}
+
+			assert.JSONEq(t, tt.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestRedirectRequestForDisabledURL(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, mock.Anything).
+		Return("", urlservice.ErrURLDisabled).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock)
+
+	request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	assertBodyContent(t, recorder)
+}
+
+func TestIsRedirectStatusCode(t *testing.T) {
+	assert.True(t, IsRedirectStatusCode(http.StatusMovedPermanently))
+	assert.True(t, IsRedirectStatusCode(http.StatusFound))
+	assert.True(t, IsRedirectStatusCode(http.StatusTemporaryRedirect))
+	assert.True(t, IsRedirectStatusCode(http.StatusPermanentRedirect))
+	assert.False(t, IsRedirectStatusCode(http.StatusOK))
+	assert.False(t, IsRedirectStatusCode(http.StatusNotModified))
+}
+
 func TestPostRequest(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -114,6 +675,16 @@ func TestPostRequest(t *testing.T) {
 			originalURL:        "https://examp  le.com/",
 			expectedStatusCode: http.StatusBadRequest,
 		},
+		{
+			name:               "relative url in request body",
+			originalURL:        "/relative",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "javascript url in request body",
+			originalURL:        "javascript:alert(1)",
+			expectedStatusCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -121,7 +692,7 @@ func TestPostRequest(t *testing.T) {
 			urlServiceMock := NewMockshortURLService(t)
 			if tt.expectedStatusCode == http.StatusOK {
 				urlServiceMock.EXPECT().
-					ShortURL(mock.Anything, mock.Anything).
+					ShortURL(mock.Anything, mock.Anything, urlservice.LinkOptions{}).
 					Return("1111111111", nil).
 					Once()
 			}
@@ -135,7 +706,7 @@ func TestPostRequest(t *testing.T) {
 			requestBody, err := json.Marshal(bodyRaw)
 			require.NoError(t, err, "Failed to marshal request body")
 
-			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(requestBody))
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", bytes.NewReader(requestBody))
 			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 			recorder := httptest.NewRecorder()
 			sut.server.Handler.ServeHTTP(recorder, request)
@@ -154,6 +725,126 @@ func TestPostRequest(t *testing.T) {
 	}
 }
 
+func TestPostRequestWithAlias(t *testing.T) {
+	tests := []struct {
+		name               string
+		aliasError         error
+		expectedStatusCode int
+	}{
+		{
+			name:               "alias is free",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "alias is invalid",
+			aliasError:         urlservice.ErrInvalidAlias,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "alias is taken",
+			aliasError:         urlservice.ErrAliasTaken,
+			expectedStatusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const alias = "launch-2026"
+
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				ShortURL(mock.Anything, mock.Anything, urlservice.LinkOptions{Alias: alias}).
+				RunAndReturn(func(_ context.Context, _ string, options urlservice.LinkOptions) (string, error) {
+					if tt.aliasError != nil {
+						return "", tt.aliasError
+					}
+
+					return options.Alias, nil
+				}).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			requestBody := `{"url": "https://example.com/", "alias": "` + alias + `"}`
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(requestBody))
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			result := assertBodyContent(t, recorder)
+			if recorder.Code == http.StatusOK {
+				assert.Equal(t, alias, result.URL)
+			}
+		})
+	}
+}
+
+func TestPostRequestWithExpiration(t *testing.T) {
+	expiresAt := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		requestBody     string
+		expectedOptions urlservice.LinkOptions
+	}{
+		{
+			name:            "expiration time",
+			requestBody:     `{"url": "https://example.com/", "expires_at": "2030-01-01T00:00:00Z"}`,
+			expectedOptions: urlservice.LinkOptions{ExpiresAt: expiresAt},
+		},
+		{
+			name:            "ttl",
+			requestBody:     `{"url": "https://example.com/", "ttl_seconds": 3600}`,
+			expectedOptions: urlservice.LinkOptions{TTL: time.Hour},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			urlServiceMock.EXPECT().
+				ShortURL(mock.Anything, mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, _ string, options urlservice.LinkOptions) (string, error) {
+					assert.True(t, tt.expectedOptions.ExpiresAt.Equal(options.ExpiresAt), "Unexpected expiration time")
+					assert.Equal(t, tt.expectedOptions.TTL, options.TTL)
+					return "1111111111", nil
+				}).
+				Once()
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/v1/urls", strings.NewReader(tt.requestBody))
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			assertBodyContent(t, recorder)
+		})
+	}
+}
+
+func TestGetRequestForExpiredURL(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, mock.Anything).
+		Return("", urlservice.ErrURLExpired).
+		Times(2)
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock)
+
+	for _, path := range []string{"/1234567890", "/api/v1/urls/1234567890"} {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		recorder := httptest.NewRecorder()
+		sut.server.Handler.ServeHTTP(recorder, request)
+
+		require.Equal(t, http.StatusGone, recorder.Code)
+		assertBodyContent(t, recorder)
+	}
+}
+
 func assertBodyContent(t *testing.T, recorder *httptest.ResponseRecorder) requestResult {
 	t.Helper()
 
@@ -174,3 +865,100 @@ func assertBodyContent(t *testing.T, recorder *httptest.ResponseRecorder) reques
 
 	return result
 }
+
+func TestListRequest(t *testing.T) {
+	owner := auth.HashKey(testAPIKey)
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name               string
+		query              string
+		expectedStatusCode int
+	}{
+		{
+			name:               "first page",
+			query:              "?owner=me",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "next page with limit",
+			query:              "?owner=me&cursor=abc&limit=2",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:               "without owner",
+			query:              "",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "unsupported owner",
+			query:              "?owner=someone",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "limit is not int",
+			query:              "?owner=me&limit=many",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.expectedStatusCode == http.StatusOK {
+				urlServiceMock.EXPECT().
+					ListShortURLs(mock.Anything, owner, mock.Anything, mock.Anything).
+					Return(urlservice.LinkPage{
+						Links: []urlservice.Link{
+							{ShortURL: "1234567890", OriginalURL: "https://example.com/1", Owner: owner},
+							{ShortURL: "my-alias", OriginalURL: "https://example.com/2", ExpiresAt: expiresAt, Disabled: true, Owner: owner},
+						},
+						NextCursor: "next",
+					}, nil).
+					Once()
+			}
+
+			listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+			sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/urls"+tt.query, nil)
+			request.Header.Set("Authorization", "Bearer "+testAPIKey)
+			recorder := httptest.NewRecorder()
+			sut.server.Handler.ServeHTTP(recorder, request)
+
+			require.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				assertBodyContent(t, recorder)
+				return
+			}
+
+			expectedBody := `{
+				"urls": [
+					{"url": "1234567890", "original_url": "https://example.com/1", "disabled": false},
+					{"url": "my-alias", "original_url": "https://example.com/2", "expires_at": "2030-01-02T03:04:05Z", "disabled": true}
+				],
+				"next_cursor": "next"
+			}`
+			assert.JSONEq(t, expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestListRequestPassesPage(t *testing.T) {
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		ListShortURLs(mock.Anything, auth.HashKey(testAPIKey), "abc", 2).
+		Return(urlservice.LinkPage{}, nil).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithAuthenticator(testAuthenticator()))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/urls?owner=me&cursor=abc&limit=2", nil)
+	request.Header.Set("Authorization", "Bearer "+testAPIKey)
+	recorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"urls": []}`, recorder.Body.String())
+}
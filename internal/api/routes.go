@@ -0,0 +1,131 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// shortURLPathValue is a name of path wildcard containing requested short URL.
+const shortURLPathValue = "short"
+
+// route is a registration of REST API path pattern with handlers of the HTTP methods it serves.
+type route struct {
+	pattern  string
+	handlers map[string]http.HandlerFunc
+}
+
+// routes returns the route table of REST API. Resource paths are versioned,
+// the root path is serving redirects from short URLs to original ones. Paths /healthz
+// and /livez are liveness probes, /readyz is a readiness probe.
+// Handlers of methods that change or read data of short URLs are protected. Rates of
+// creating and resolving requests are limited. The batch handler protects creation and limits
+// rates itself, because its action and count of URLs are known only from the body.
+func (s *RESTServer) routes() []route {
+	shortURLPattern := fmt.Sprintf("/{%s}", shortURLPathValue)
+
+	return []route{
+		{
+			pattern: "/api/v1/urls",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet:  s.protected(s.handleListURLs),
+				http.MethodPost: s.protected(s.createLimited(s.handleCreateURL)),
+			},
+		},
+		{
+			pattern: "/api/v1/urls:batch",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodPost: s.handleBatch,
+			},
+		},
+		{
+			pattern: "/api/v1/urls" + shortURLPattern,
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet:    s.resolveLimited(s.handleGetURL),
+				http.MethodDelete: s.protected(s.handleDeleteURL),
+				http.MethodPatch:  s.protected(s.handlePatchURL),
+				http.MethodPut:    s.protected(s.handlePutURL),
+			},
+		},
+		{
+			pattern: "/api/v1/urls" + shortURLPattern + "/history",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.protected(s.handleGetHistory),
+			},
+		},
+		{
+			pattern: "/api/v1/urls" + shortURLPattern + "/stats",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.protected(s.handleGetStats),
+			},
+		},
+		{
+			pattern: "/healthz",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.handleHealth,
+			},
+		},
+		{
+			pattern: "/livez",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.handleHealth,
+			},
+		},
+		{
+			pattern: "/readyz",
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.handleReadiness,
+			},
+		},
+		{
+			pattern: shortURLPattern,
+			handlers: map[string]http.HandlerFunc{
+				http.MethodGet: s.resolveLimited(s.handleRedirect),
+			},
+		},
+	}
+}
+
+// newRouter registers all routes of the route table in a new http.ServeMux. Requests to
+// unknown paths are responded with code 404, requests with not registered methods of
+// known paths are responded with code 405. If server has metrics handler, it is served
+// on path /metrics.
+func (s *RESTServer) newRouter() *http.ServeMux {
+	mux := http.NewServeMux()
+	for _, r := range s.routes() {
+		mux.Handle(r.pattern, loggingMiddleware(tracingMiddleware(s.tracing, r.pattern, metricsMiddleware(s.metrics, r.pattern, methodsHandler(r.handlers)))))
+	}
+
+	if s.metricsHandler != nil {
+		mux.Handle("/metrics", methodsHandler(map[string]http.HandlerFunc{
+			http.MethodGet: s.metricsHandler.ServeHTTP,
+		}))
+	}
+
+	mux.Handle("/", loggingMiddleware(tracingMiddleware(s.tracing, unmatchedRoute, metricsMiddleware(s.metrics, unmatchedRoute, handleNotFound))))
+	return mux
+}
+
+// methodsHandler returns a handler that calls the handler of request method. If the method is not
+// allowed, it sets Allow header with all methods of the handlers and returns code 405.
+func methodsHandler(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	allowedMethods := make([]string, 0, len(handlers))
+	for method := range handlers {
+		allowedMethods = append(allowedMethods, method)
+	}
+
+	slices.Sort(allowedMethods)
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, isAllowed := handlers[r.Method]
+		if !isAllowed {
+			writeNotAllowed(w, allowedMethods)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func handleNotFound(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, "", fmt.Errorf("%w: %s", errRouteNotFound, r.URL.Path))
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// httpsPort is a default port of HTTPS, it is omitted in redirect URLs.
+const httpsPort = "443"
+
+// WithTLS returns an option that makes RESTServer serve HTTPS with the TLS configuration.
+func WithTLS(config *tls.Config) RESTServerOption {
+	return func(server *RESTServer) {
+		server.tlsConfig = config
+	}
+}
+
+// WithHTTPSRedirect returns an option that makes RESTServer listen to plain HTTP requests
+// on the address and redirect them to the same URLs with HTTPS. It is used together with WithTLS.
+func WithHTTPSRedirect(listenAddress string) RESTServerOption {
+	return func(server *RESTServer) {
+		server.redirectListenAddress = listenAddress
+	}
+}
+
+// WithGRPCTLS returns an option that makes GRPCServer use TLS transport credentials with
+// the TLS configuration. Clients are required to present certificates if it sets ClientAuth.
+func WithGRPCTLS(config *tls.Config) GRPCServerOption {
+	return func(server *GRPCServer) {
+		server.tlsConfig = config
+	}
+}
+
+// newHTTPSRedirectHandler returns a handler redirecting requests to HTTPS listener with the port.
+// Host of the request is kept, so redirects work for every name of the server.
+func newHTTPSRedirectHandler(port string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		} else {
+			host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+		}
+
+		if port != httpsPort {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		target := url.URL{
+			Scheme:   "https",
+			Host:     host,
+			Path:     r.URL.Path,
+			RawPath:  r.URL.RawPath,
+			RawQuery: r.URL.RawQuery,
+		}
+
+		http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+	})
+}
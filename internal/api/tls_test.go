@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+
+	"shorturl/internal/pb"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	tests := []struct {
+		name             string
+		listenAddress    string
+		target           string
+		host             string
+		expectedLocation string
+	}{
+		{
+			name:             "custom port",
+			listenAddress:    ":8443",
+			target:           "/1234567890?utm=mail",
+			host:             "example.com",
+			expectedLocation: "https://example.com:8443/1234567890?utm=mail",
+		},
+		{
+			name:             "default port",
+			listenAddress:    ":443",
+			target:           "/api/v1/urls",
+			host:             "example.com:8080",
+			expectedLocation: "https://example.com/api/v1/urls",
+		},
+		{
+			name:             "ipv6 host",
+			listenAddress:    "[::]:8443",
+			target:           "/",
+			host:             "[::1]:8080",
+			expectedLocation: "https://[::1]:8443/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := NewRESTServer(tt.listenAddress, NewMockshortURLService(t),
+				WithTLS(&tls.Config{}), WithHTTPSRedirect(":8080"))
+			require.NotNil(t, sut.redirectServer)
+
+			request := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			request.Host = tt.host
+			recorder := httptest.NewRecorder()
+			sut.redirectServer.Handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusPermanentRedirect, recorder.Code)
+			assert.Equal(t, tt.expectedLocation, recorder.Header().Get("Location"))
+		})
+	}
+}
+
+func TestHTTPSRedirect_WithoutTLS(t *testing.T) {
+	sut := NewRESTServer(":8443", NewMockshortURLService(t), WithHTTPSRedirect(":8080"))
+
+	assert.Nil(t, sut.redirectServer, "Redirect to HTTPS requires TLS")
+	assert.Nil(t, sut.server.TLSConfig)
+}
+
+// newTestTLSCertificate returns a self-signed certificate of server and client "bufnet".
+func newTestTLSCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "bufnet"},
+		DNSNames:              []string{"bufnet"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &private.PublicKey, private)
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: private, Leaf: parsed}, pool
+}
+
+func TestGRPCServerWithClientCertificates(t *testing.T) {
+	const bufSize = 1 << 20
+
+	certificate, pool := newTestTLSCertificate(t)
+	serverConfig := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+
+	tests := []struct {
+		name         string
+		certificates []tls.Certificate
+		isAccepted   bool
+	}{
+		{name: "client with certificate", certificates: []tls.Certificate{certificate}, isAccepted: true},
+		{name: "client without certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urlServiceMock := NewMockshortURLService(t)
+			if tt.isAccepted {
+				urlServiceMock.EXPECT().OriginalURL(mock.Anything, "1234567890").Return("https://example.com/", nil).Once()
+				urlServiceMock.EXPECT().RecordClick(mock.Anything).Once()
+			}
+
+			listener := bufconn.Listen(bufSize)
+			runTestGRPCServer(t, urlServiceMock, listener, WithGRPCTLS(serverConfig))
+
+			clientCredentials := credentials.NewTLS(&tls.Config{
+				RootCAs:      pool,
+				Certificates: tt.certificates,
+			})
+			conn, err := grpc.DialContext(context.Background(), "bufnet",
+				grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+					return listener.Dial()
+				}),
+				grpc.WithTransportCredentials(clientCredentials))
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				conn.Close()
+			})
+
+			resp, err := pb.NewShortURLServiceClient(conn).GetOriginalURL(context.Background(), &pb.ShortURL{Url: "1234567890"})
+			if !tt.isAccepted {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "https://example.com/", resp.Url)
+		})
+	}
+}
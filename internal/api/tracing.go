@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const tracerName = "shorturl/internal/api"
+
+// tracing is a tracer of requests with a propagator of their trace context.
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// WithTracing returns an option that starts a span for every REST API request with tracer of the provider.
+// Trace context is extracted from request headers with the propagator and injected into response headers.
+func WithTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) RESTServerOption {
+	return func(server *RESTServer) {
+		server.tracing = newTracing(provider, propagator)
+	}
+}
+
+// WithGRPCTracing returns an option that starts a span for every gRPC request with tracer of the provider.
+// Trace context is extracted from request metadata with the propagator and injected into header metadata.
+func WithGRPCTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) GRPCServerOption {
+	return func(server *GRPCServer) {
+		server.tracing = newTracing(provider, propagator)
+	}
+}
+
+func newTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *tracing {
+	if provider == nil {
+		return nil
+	}
+
+	return &tracing{tracer: provider.Tracer(tracerName), propagator: propagator}
+}
+
+// tracingMiddleware returns a handler that calls the handler in a server span named by the route pattern.
+// If tracing is nil, the handler is returned as is.
+func tracingMiddleware(t *tracing, pattern string, handler http.HandlerFunc) http.HandlerFunc {
+	if t == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if !knownHTTPMethods[method] {
+			method = "OTHER"
+		}
+
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, method+" "+pattern,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPRequestMethodKey.String(method), semconv.HTTPRoute(pattern)),
+		)
+		defer span.End()
+
+		t.propagator.Inject(ctx, propagation.HeaderCarrier(w.Header()))
+		wrappedWriter := &responseWriterWrapper{
+			ResponseWriter: w,
+		}
+
+		handler(wrappedWriter, r.WithContext(ctx))
+
+		statusCode := wrappedWriter.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(statusCode))
+		if statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(statusCode))
+		}
+	}
+}
+
+func (s *GRPCServer) tracingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if s.tracing == nil {
+		return handler(ctx, req)
+	}
+
+	ctx, span := s.tracing.startGRPCSpan(ctx, info.FullMethod)
+	defer span.End()
+
+	if err := grpc.SetHeader(ctx, s.tracing.headerMetadata(ctx)); err != nil {
+		span.RecordError(err)
+	}
+
+	resp, err := handler(ctx, req)
+	endGRPCSpan(span, err)
+	return resp, err
+}
+
+func (s *GRPCServer) tracingStreamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if s.tracing == nil {
+		return handler(srv, stream)
+	}
+
+	ctx, span := s.tracing.startGRPCSpan(stream.Context(), info.FullMethod)
+	defer span.End()
+
+	if err := stream.SetHeader(s.tracing.headerMetadata(ctx)); err != nil {
+		span.RecordError(err)
+	}
+
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	endGRPCSpan(span, err)
+	return err
+}
+
+// startGRPCSpan starts a server span of the method with trace context from incoming metadata.
+func (t *tracing) startGRPCSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = t.propagator.Extract(ctx, metadataCarrier(md))
+
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return t.tracer.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.RPCSystemGRPC, semconv.RPCService(service), semconv.RPCMethod(method)),
+	)
+}
+
+// headerMetadata returns metadata with trace context of the span in the context.
+func (t *tracing) headerMetadata(ctx context.Context) metadata.MD {
+	md := metadata.MD{}
+	t.propagator.Inject(ctx, metadataCarrier(md))
+	return md
+}
+
+func endGRPCSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, code.String())
+	}
+}
+
+// metadataCarrier adapts gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"shorturl/internal/pb"
+	"shorturl/internal/urlservice"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+)
+
+func TestTracedRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		RunAndReturn(func(ctx context.Context, _ string) (string, error) {
+			assert.Equal(t, testTraceID, trace.SpanFromContext(ctx).SpanContext().TraceID().String())
+			return "", urlservice.ErrURLNotFound
+		}).
+		Once()
+
+	listenAddr := ":" + strconv.Itoa(rand.Intn(1e4))
+	sut := NewRESTServer(listenAddr, urlServiceMock, WithTracing(provider, propagation.TraceContext{}))
+
+	request := httptest.NewRequest(http.MethodGet, "/1234567890", nil)
+	request.Header.Set("traceparent", testTraceParent)
+	responseRecorder := httptest.NewRecorder()
+	sut.server.Handler.ServeHTTP(responseRecorder, request)
+
+	require.Equal(t, http.StatusNotFound, responseRecorder.Code)
+	assert.True(t, strings.HasPrefix(responseRecorder.Header().Get("traceparent"), "00-"+testTraceID+"-"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /{short}", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.True(t, spans[0].Parent().IsRemote())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("http.response.status_code", http.StatusNotFound))
+	assert.Equal(t, otelcodes.Unset, spans[0].Status().Code)
+}
+
+func TestTracedGRPCRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	urlServiceMock := NewMockshortURLService(t)
+	urlServiceMock.EXPECT().
+		OriginalURL(mock.Anything, "1234567890").
+		RunAndReturn(func(ctx context.Context, _ string) (string, error) {
+			assert.Equal(t, testTraceID, trace.SpanFromContext(ctx).SpanContext().TraceID().String())
+			return "", urlservice.ErrURLExpired
+		}).
+		Once()
+
+	client := grpcClient(t, urlServiceMock, WithGRPCTracing(provider, propagation.TraceContext{}))
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "traceparent", testTraceParent)
+	_, err := client.GetOriginalURL(ctx, &pb.ShortURL{Url: "1234567890"}, grpc.Header(&header))
+	assertCorrectGRPCCode(t, err, codes.FailedPrecondition)
+
+	require.Len(t, header.Get("traceparent"), 1)
+	assert.True(t, strings.HasPrefix(header.Get("traceparent")[0], "00-"+testTraceID+"-"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "shorturl.ShortURLService/GetOriginalURL", spans[0].Name())
+	assert.True(t, spans[0].Parent().IsRemote())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("rpc.grpc.status_code", int(codes.FailedPrecondition)))
+	assert.Equal(t, otelcodes.Error, spans[0].Status().Code)
+}
@@ -0,0 +1,75 @@
+// Package auth provides authentication of requests by API keys.
+//
+// Keys are never stored in plain text, key stores keep only their SHA-256 hashes.
+// The hash also identifies the owner of the key, so it is used as owner of created links.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrUnauthenticated is returned when API key is missing or is not found in key store.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// KeyStore is a storage of hashes of valid API keys.
+type KeyStore interface {
+	ContainsHash(ctx context.Context, keyHash string) (bool, error)
+}
+
+// Authenticator checks API keys using the key store.
+//
+// It must be initialized with NewAuthenticator.
+type Authenticator struct {
+	store KeyStore
+}
+
+// NewAuthenticator initializes Authenticator checking keys in the key store.
+func NewAuthenticator(store KeyStore) Authenticator {
+	return Authenticator{store: store}
+}
+
+// Authenticate returns the owner of the key, that is its hash. It returns an error wrapping
+// ErrUnauthenticated if the key is empty or its hash is not found in the key store.
+// Errors of key store are returned without ErrUnauthenticated.
+func (a Authenticator) Authenticate(ctx context.Context, key string) (string, error) {
+	if key == "" {
+		return "", fmt.Errorf("%w: api key is missing", ErrUnauthenticated)
+	}
+
+	keyHash := HashKey(key)
+	isValid, err := a.store.ContainsHash(ctx, keyHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to check api key: %w", err)
+	}
+
+	if !isValid {
+		return "", fmt.Errorf("%w: api key is invalid", ErrUnauthenticated)
+	}
+
+	return keyHash, nil
+}
+
+// HashKey returns hex encoded SHA-256 hash of the key, that is saved in key stores.
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// keySize is a count of random bytes of generated API keys.
+const keySize = 32
+
+// GenerateKey returns a new random API key encoded with URL-safe base64.
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticate(t *testing.T) {
+	const validKey = "valid-key"
+
+	tests := []struct {
+		name            string
+		key             string
+		isAuthenticated bool
+	}{
+		{
+			name:            "valid key",
+			key:             validKey,
+			isAuthenticated: true,
+		},
+		{
+			name: "invalid key",
+			key:  "invalid-key",
+		},
+		{
+			name: "missing key",
+			key:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := NewAuthenticator(NewInMemoryKeyStore(validKey))
+
+			owner, err := sut.Authenticate(context.Background(), tt.key)
+
+			if tt.isAuthenticated {
+				require.NoError(t, err)
+				assert.Equal(t, HashKey(tt.key), owner)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrUnauthenticated)
+		})
+	}
+}
+
+func TestAuthenticateWithStoreError(t *testing.T) {
+	storeErr := errors.New("store is unavailable")
+	storeMock := NewMockKeyStore(t)
+	storeMock.EXPECT().
+		ContainsHash(mock.Anything, HashKey("key")).
+		Return(false, storeErr).
+		Once()
+
+	sut := NewAuthenticator(storeMock)
+	_, err := sut.Authenticate(context.Background(), "key")
+
+	require.ErrorIs(t, err, storeErr)
+	assert.NotErrorIs(t, err, ErrUnauthenticated)
+}
+
+func TestInMemoryKeyStoreKeepsHashes(t *testing.T) {
+	sut := NewInMemoryKeyStore()
+	sut.AddKey("key")
+
+	containsHash, err := sut.ContainsHash(context.Background(), HashKey("key"))
+	require.NoError(t, err)
+	assert.True(t, containsHash)
+
+	containsKey, err := sut.ContainsHash(context.Background(), "key")
+	require.NoError(t, err)
+	assert.False(t, containsKey, "Store must not contain plain key")
+}
+
+func TestGenerateKey(t *testing.T) {
+	first, err := GenerateKey()
+	require.NoError(t, err)
+	second, err := GenerateKey()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second, "Generated keys are repeated")
+}
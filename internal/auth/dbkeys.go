@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// KeyInfo describes a saved API key without the key itself.
+type KeyInfo struct {
+	Hash      string
+	Name      string
+	CreatedAt time.Time
+}
+
+// PostgreSQLKeyStore is a database storage of hashes of API keys using PostgreSQL.
+//
+// The zero value is not useful, you must use NewPostgreSQLKeyStore to create an instance.
+type PostgreSQLKeyStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgreSQLKeyStore initializes PostgreSQLKeyStore with the given database connection pool.
+// It returns a pointer to created object.
+func NewPostgreSQLKeyStore(pool *pgxpool.Pool) *PostgreSQLKeyStore {
+	return &PostgreSQLKeyStore{pool: pool}
+}
+
+// AddKey saves hash of the key with its name. If the key is already saved, it does nothing.
+func (s *PostgreSQLKeyStore) AddKey(ctx context.Context, name, key string) error {
+	const sql = `
+		INSERT INTO api_keys (key_hash, name)
+		VALUES ($1, $2)
+		ON CONFLICT (key_hash) DO NOTHING;
+	`
+
+	if _, err := s.pool.Exec(ctx, sql, HashKey(key), name); err != nil {
+		return fmt.Errorf("failed to save %q api key in db: %w", name, err)
+	}
+
+	return nil
+}
+
+// ContainsHash reports whether the hash of key is saved.
+func (s *PostgreSQLKeyStore) ContainsHash(ctx context.Context, keyHash string) (bool, error) {
+	const sql = `
+		SELECT EXISTS (SELECT 1 FROM api_keys WHERE key_hash = $1);
+	`
+
+	var isFound bool
+	if err := s.pool.QueryRow(ctx, sql, keyHash).Scan(&isFound); err != nil {
+		return false, fmt.Errorf("failed to check api key in db: %w", err)
+	}
+
+	return isFound, nil
+}
+
+// Keys returns all saved keys, the oldest first.
+func (s *PostgreSQLKeyStore) Keys(ctx context.Context) ([]KeyInfo, error) {
+	const sql = `
+		SELECT key_hash, name, created_at FROM api_keys
+		ORDER BY created_at, key_hash;
+	`
+
+	rows, err := s.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys from db: %w", err)
+	}
+
+	var (
+		key  KeyInfo
+		keys []KeyInfo
+	)
+
+	_, err = pgx.ForEachRow(rows, []any{&key.Hash, &key.Name, &key.CreatedAt}, func() error {
+		keys = append(keys, key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api keys from db: %w", err)
+	}
+
+	return keys, nil
+}
+
+// DeleteKey removes the hash of key, so the key is not valid anymore.
+// It reports whether the hash was saved.
+func (s *PostgreSQLKeyStore) DeleteKey(ctx context.Context, keyHash string) (bool, error) {
+	const sql = `
+		DELETE FROM api_keys WHERE key_hash = $1;
+	`
+
+	tag, err := s.pool.Exec(ctx, sql, keyHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete api key from db: %w", err)
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryKeyStore is an in-memory storage of hashes of API keys. It's safe for concurrent use.
+//
+// The zero value is not useful, you must use NewInMemoryKeyStore to create an instance.
+type InMemoryKeyStore struct {
+	hashes map[string]struct{}
+	mutex  sync.RWMutex
+}
+
+// NewInMemoryKeyStore initializes InMemoryKeyStore with hashes of the keys.
+// It returns a pointer to created object.
+func NewInMemoryKeyStore(keys ...string) *InMemoryKeyStore {
+	store := &InMemoryKeyStore{
+		hashes: make(map[string]struct{}, len(keys)),
+	}
+
+	for _, key := range keys {
+		store.AddKey(key)
+	}
+
+	return store
+}
+
+// AddKey saves hash of the key.
+func (s *InMemoryKeyStore) AddKey(key string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.hashes[HashKey(key)] = struct{}{}
+}
+
+// ContainsHash reports whether the hash of key is saved.
+func (s *InMemoryKeyStore) ContainsHash(_ context.Context, keyHash string) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, isFound := s.hashes[keyHash]
+	return isFound, nil
+}
@@ -0,0 +1,89 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package auth
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockKeyStore is an autogenerated mock type for the KeyStore type
+type MockKeyStore struct {
+	mock.Mock
+}
+
+type MockKeyStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockKeyStore) EXPECT() *MockKeyStore_Expecter {
+	return &MockKeyStore_Expecter{mock: &_m.Mock}
+}
+
+// ContainsHash provides a mock function with given fields: ctx, keyHash
+func (_m *MockKeyStore) ContainsHash(ctx context.Context, keyHash string) (bool, error) {
+	ret := _m.Called(ctx, keyHash)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, keyHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, keyHash)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, keyHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockKeyStore_ContainsHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ContainsHash'
+type MockKeyStore_ContainsHash_Call struct {
+	*mock.Call
+}
+
+// ContainsHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - keyHash string
+func (_e *MockKeyStore_Expecter) ContainsHash(ctx interface{}, keyHash interface{}) *MockKeyStore_ContainsHash_Call {
+	return &MockKeyStore_ContainsHash_Call{Call: _e.mock.On("ContainsHash", ctx, keyHash)}
+}
+
+func (_c *MockKeyStore_ContainsHash_Call) Run(run func(ctx context.Context, keyHash string)) *MockKeyStore_ContainsHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockKeyStore_ContainsHash_Call) Return(_a0 bool, _a1 error) *MockKeyStore_ContainsHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockKeyStore_ContainsHash_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockKeyStore_ContainsHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockKeyStore creates a new instance of MockKeyStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockKeyStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockKeyStore {
+	mock := &MockKeyStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
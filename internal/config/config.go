@@ -0,0 +1,482 @@
+// Package config provides configuration of the program.
+//
+// Configuration is loaded from a YAML file, environment variables and flags. Values of flags
+// override values of environment variables, that override values of the file, that override
+// default values. Every field of Config has a key in the file, most of them have an environment
+// variable, and a few have flags registered by commands that use them.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"shorturl/internal/tlsconfig"
+	"shorturl/internal/tracing"
+)
+
+// Storage types selected by Storage.Type.
+const (
+	StorageInMemory = "in-memory"
+	StoragePostgres = "postgres"
+	StorageRedis    = "redis"
+	StorageBolt     = "bolt"
+)
+
+// maxShortURLLength is the maximum length of short URL, it is limited by storages.
+const maxShortURLLength = 64
+
+// Config is a configuration of the program.
+type Config struct {
+	ShortURLLength int        `yaml:"short_url_length" env:"SHORT_URL_LENGTH"`
+	Storage        Storage    `yaml:"storage"`
+	Server         Server     `yaml:"server"`
+	Cache          Cache      `yaml:"cache"`
+	Auth           Auth       `yaml:"auth"`
+	RateLimits     RateLimits `yaml:"rate_limits"`
+	Tracing        Tracing    `yaml:"tracing"`
+	Postgres       Postgres   `yaml:"postgres"`
+	Redis          Redis      `yaml:"redis"`
+	Bolt           Bolt       `yaml:"bolt"`
+	Journal        Journal    `yaml:"journal"`
+}
+
+// Storage selects the storage of links. Cached storage is wrapped with LRU cache of links,
+// new migrations of PostgreSQL storage are applied on launch of the server if Migrate is true.
+type Storage struct {
+	Type    string `yaml:"type" env:"STORAGE_TYPE" flag:"s" usage:"Specify the type of storage to use ('in-memory', 'postgres', 'redis' or 'bolt'). Default is 'in-memory'"`
+	Cached  bool   `yaml:"cached" env:"CACHE_ENABLED" flag:"cache" usage:"Put LRU cache of links in front of the selected storage"`
+	Migrate bool   `yaml:"migrate" env:"POSTGRES_MIGRATE" flag:"migrate" usage:"Apply new migrations of the scheme of PostgreSQL storage on launch"`
+}
+
+// Server configures REST API and gRPC servers, it is required only to run them. On shutdown
+// servers report that they are not ready and keep handling requests during DrainDelay,
+// so load balancers stop sending new requests before servers are stopped.
+type Server struct {
+	HTTPListenAddress  string        `yaml:"http_listen_address" env:"HTTP_LISTEN_ADDRESS"`
+	GRPCListenAddress  string        `yaml:"grpc_listen_address" env:"GRPC_LISTEN_ADDRESS"`
+	RedirectStatusCode int           `yaml:"redirect_status_code" env:"REDIRECT_STATUS_CODE"`
+	PurgeInterval      time.Duration `yaml:"purge_interval" env:"EXPIRED_URLS_PURGE_INTERVAL"`
+	DrainDelay         time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	TLS                TLS           `yaml:"tls"`
+}
+
+// TLS configures TLS of both servers, it is enabled if CertFile is set. Certificate and key
+// files are checked for changes at most once per ReloadInterval. gRPC server requires client
+// certificates signed by CA of GRPCClientCAFile if it is set. Plain HTTP requests are redirected
+// to HTTPS from HTTPRedirectAddress if it is set.
+type TLS struct {
+	CertFile            string        `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile             string        `yaml:"key_file" env:"TLS_KEY_FILE"`
+	ReloadInterval      time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL"`
+	GRPCClientCAFile    string        `yaml:"grpc_client_ca_file" env:"GRPC_TLS_CLIENT_CA_FILE"`
+	HTTPRedirectAddress string        `yaml:"http_redirect_address" env:"HTTP_REDIRECT_LISTEN_ADDRESS"`
+}
+
+// Enabled reports whether servers use TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Cache configures LRU cache of links.
+type Cache struct {
+	Size        int           `yaml:"size" env:"CACHE_SIZE"`
+	TTL         time.Duration `yaml:"ttl" env:"CACHE_TTL"`
+	NegativeTTL time.Duration `yaml:"negative_ttl" env:"CACHE_NEGATIVE_TTL"`
+}
+
+// Auth configures authentication by API keys.
+type Auth struct {
+	Enabled bool     `yaml:"enabled" env:"AUTH_ENABLED"`
+	APIKeys []string `yaml:"api_keys" env:"API_KEYS" secret:"true"`
+}
+
+// RateLimits configures limits of requests per client. Zero rate disables limiting.
+type RateLimits struct {
+	CreateRate   float64 `yaml:"create_rate" env:"CREATE_RATE_LIMIT"`
+	CreateBurst  int     `yaml:"create_burst" env:"CREATE_RATE_BURST"`
+	ResolveRate  float64 `yaml:"resolve_rate" env:"RESOLVE_RATE_LIMIT"`
+	ResolveBurst int     `yaml:"resolve_burst" env:"RESOLVE_RATE_BURST"`
+}
+
+// Tracing configures the exporter of OpenTelemetry spans.
+type Tracing struct {
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+}
+
+// Postgres configures connection to PostgreSQL server. URL is a connection string in URL
+// or keyword/value format, other connection settings, including TLS, are ignored if it is set.
+// Zero timeouts and pool settings keep defaults of pgx.
+type Postgres struct {
+	URL              string        `yaml:"url" env:"DATABASE_URL" secret:"true"`
+	Host             string        `yaml:"host" env:"POSTGRES_HOST"`
+	Port             int           `yaml:"port" env:"POSTGRES_PORT"`
+	User             string        `yaml:"user" env:"POSTGRES_USER"`
+	Password         string        `yaml:"password" env:"POSTGRES_PASSWORD" secret:"true"`
+	DB               string        `yaml:"db" env:"POSTGRES_DB"`
+	SSLMode          string        `yaml:"sslmode" env:"POSTGRES_SSLMODE"`
+	SSLRootCert      string        `yaml:"sslrootcert" env:"POSTGRES_SSLROOTCERT"`
+	SSLCert          string        `yaml:"sslcert" env:"POSTGRES_SSLCERT"`
+	SSLKey           string        `yaml:"sslkey" env:"POSTGRES_SSLKEY"`
+	ApplicationName  string        `yaml:"application_name" env:"POSTGRES_APPLICATION_NAME"`
+	ConnectTimeout   time.Duration `yaml:"connect_timeout" env:"POSTGRES_CONNECT_TIMEOUT"`
+	StatementTimeout time.Duration `yaml:"statement_timeout" env:"POSTGRES_STATEMENT_TIMEOUT"`
+	Pool             PostgresPool  `yaml:"pool"`
+}
+
+// PostgresPool configures the pool of connections to PostgreSQL server.
+type PostgresPool struct {
+	MaxConns          int           `yaml:"max_conns" env:"POSTGRES_POOL_MAX_CONNS"`
+	MinConns          int           `yaml:"min_conns" env:"POSTGRES_POOL_MIN_CONNS"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" env:"POSTGRES_POOL_MAX_CONN_LIFETIME"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" env:"POSTGRES_POOL_MAX_CONN_IDLE_TIME"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" env:"POSTGRES_POOL_HEALTH_CHECK_PERIOD"`
+}
+
+// Redis configures connection to Redis server.
+type Redis struct {
+	Addr     string `yaml:"addr" env:"REDIS_ADDR"`
+	Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+}
+
+// Bolt configures bolt storage.
+type Bolt struct {
+	Path string `yaml:"path" env:"BOLT_PATH" flag:"bolt-path" usage:"Path to the database file of bolt storage, it is created if needed"`
+}
+
+// Journal configures the journal of in-memory storage. Links are not saved on disk if Dir is empty.
+type Journal struct {
+	Dir              string        `yaml:"dir" env:"MEMSTORE_JOURNAL_DIR" flag:"journal-dir" usage:"Directory of the journal of in-memory storage. Links are not saved on disk if it is empty"`
+	SyncInterval     time.Duration `yaml:"sync_interval" env:"MEMSTORE_SYNC_INTERVAL"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env:"MEMSTORE_SNAPSHOT_INTERVAL"`
+}
+
+// Default returns the configuration with default values.
+func Default() Config {
+	return Config{
+		ShortURLLength: 10,
+		Storage:        Storage{Type: StorageInMemory},
+		Server: Server{
+			RedirectStatusCode: http.StatusFound,
+			PurgeInterval:      time.Minute,
+			DrainDelay:         5 * time.Second,
+			TLS:                TLS{ReloadInterval: tlsconfig.DefaultCheckInterval},
+		},
+		Cache: Cache{
+			Size:        10000,
+			TTL:         time.Minute,
+			NegativeTTL: 5 * time.Second,
+		},
+		RateLimits: RateLimits{
+			CreateRate:   5,
+			CreateBurst:  20,
+			ResolveRate:  50,
+			ResolveBurst: 200,
+		},
+		Tracing: Tracing{Exporter: string(tracing.ExporterNone)},
+		Postgres: Postgres{
+			Port:            5432,
+			ApplicationName: "shorturl_api",
+			ConnectTimeout:  15 * time.Second,
+		},
+		Bolt:    Bolt{Path: "shorturl.db"},
+		Journal: Journal{SnapshotInterval: 5 * time.Minute},
+	}
+}
+
+// Validate returns all errors of the configuration joined, except errors of Server that
+// are returned by Server.Validate. Settings of the selected storage are required.
+func (c Config) Validate() error {
+	var errs []error
+	if c.ShortURLLength <= 0 || c.ShortURLLength > maxShortURLLength {
+		errs = append(errs, fmt.Errorf("short_url_length must be from 1 to %d, got %d", maxShortURLLength, c.ShortURLLength))
+	}
+
+	switch c.Storage.Type {
+	case StorageInMemory, StorageBolt:
+	case StoragePostgres:
+		errs = append(errs, c.Postgres.Validate())
+	case StorageRedis:
+		if c.Redis.Addr == "" {
+			errs = append(errs, errors.New("redis.addr must be set for redis storage"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage.type must be %q, %q, %q or %q, got %q",
+			StorageInMemory, StoragePostgres, StorageRedis, StorageBolt, c.Storage.Type))
+	}
+
+	if c.Auth.Enabled && c.Storage.Type != StoragePostgres && len(c.Auth.APIKeys) == 0 {
+		errs = append(errs, errors.New("auth.api_keys must contain keys if auth is enabled with storage other than postgres"))
+	}
+
+	errs = append(errs,
+		c.Cache.validate(),
+		c.RateLimits.validate(),
+		c.Tracing.validate(),
+		c.Redis.validate(),
+		c.Journal.validate(),
+	)
+
+	return errors.Join(errs...)
+}
+
+// Validate returns all errors of the server configuration joined. Listen addresses are required.
+func (s Server) Validate() error {
+	errs := []error{
+		validateListenAddress("server.http_listen_address", s.HTTPListenAddress),
+		validateListenAddress("server.grpc_listen_address", s.GRPCListenAddress),
+	}
+
+	switch s.RedirectStatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	default:
+		errs = append(errs, fmt.Errorf("server.redirect_status_code must be 301, 302, 307 or 308, got %d", s.RedirectStatusCode))
+	}
+
+	if s.PurgeInterval <= 0 {
+		errs = append(errs, fmt.Errorf("server.purge_interval must be positive, got %s", s.PurgeInterval))
+	}
+
+	if s.DrainDelay < 0 {
+		errs = append(errs, fmt.Errorf("server.drain_delay must not be negative, got %s", s.DrainDelay))
+	}
+
+	errs = append(errs, s.TLS.validate(s.HTTPListenAddress))
+	return errors.Join(errs...)
+}
+
+func (t TLS) validate(httpListenAddress string) error {
+	var errs []error
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, errors.New("server.tls.cert_file and server.tls.key_file must be set together"))
+	}
+
+	if t.ReloadInterval < 0 {
+		errs = append(errs, fmt.Errorf("server.tls.reload_interval must not be negative, got %s", t.ReloadInterval))
+	}
+
+	if !t.Enabled() {
+		if t.GRPCClientCAFile != "" {
+			errs = append(errs, errors.New("server.tls.grpc_client_ca_file requires server.tls.cert_file"))
+		}
+
+		if t.HTTPRedirectAddress != "" {
+			errs = append(errs, errors.New("server.tls.http_redirect_address requires server.tls.cert_file"))
+		}
+
+		return errors.Join(errs...)
+	}
+
+	if t.HTTPRedirectAddress != "" {
+		errs = append(errs, validateListenAddress("server.tls.http_redirect_address", t.HTTPRedirectAddress))
+		if t.HTTPRedirectAddress == httpListenAddress {
+			errs = append(errs, errors.New("server.tls.http_redirect_address must differ from server.http_listen_address"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateListenAddress(key, address string) error {
+	if address == "" {
+		return fmt.Errorf("%s must be set", key)
+	}
+
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return fmt.Errorf("%s must be host:port: %w", key, err)
+	}
+
+	return nil
+}
+
+// Validate returns all errors of the connection settings joined. Host, user and db
+// are required if URL is not set.
+func (p Postgres) Validate() error {
+	var errs []error
+	if p.URL == "" {
+		errs = append(errs, p.validateConnection())
+	}
+
+	durations := []struct {
+		key   string
+		value time.Duration
+	}{
+		{key: "connect_timeout", value: p.ConnectTimeout},
+		{key: "statement_timeout", value: p.StatementTimeout},
+		{key: "pool.max_conn_lifetime", value: p.Pool.MaxConnLifetime},
+		{key: "pool.max_conn_idle_time", value: p.Pool.MaxConnIdleTime},
+		{key: "pool.health_check_period", value: p.Pool.HealthCheckPeriod},
+	}
+
+	for _, setting := range durations {
+		if setting.value < 0 {
+			errs = append(errs, fmt.Errorf("postgres.%s must not be negative, got %s", setting.key, setting.value))
+		}
+	}
+
+	if p.Pool.MaxConns < 0 || p.Pool.MaxConns > math.MaxInt32 {
+		errs = append(errs, fmt.Errorf("postgres.pool.max_conns must be from 0 to %d, got %d", math.MaxInt32, p.Pool.MaxConns))
+	}
+
+	if p.Pool.MinConns < 0 || p.Pool.MinConns > math.MaxInt32 {
+		errs = append(errs, fmt.Errorf("postgres.pool.min_conns must be from 0 to %d, got %d", math.MaxInt32, p.Pool.MinConns))
+	}
+
+	if p.Pool.MaxConns != 0 && p.Pool.MinConns > p.Pool.MaxConns {
+		errs = append(errs, fmt.Errorf("postgres.pool.min_conns must not be greater than max_conns, got %d > %d",
+			p.Pool.MinConns, p.Pool.MaxConns))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (p Postgres) validateConnection() error {
+	required := []struct{ key, value string }{
+		{key: "host", value: p.Host},
+		{key: "user", value: p.User},
+		{key: "db", value: p.DB},
+	}
+
+	var errs []error
+	for _, setting := range required {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("postgres.%s must be set if postgres.url is not set", setting.key))
+		}
+	}
+
+	if p.Port <= 0 || p.Port > 65535 {
+		errs = append(errs, fmt.Errorf("postgres.port must be from 1 to 65535, got %d", p.Port))
+	}
+
+	switch p.SSLMode {
+	case "", "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		errs = append(errs, fmt.Errorf("postgres.sslmode must be disable, allow, prefer, require, verify-ca or verify-full, got %q", p.SSLMode))
+	}
+
+	if (p.SSLCert == "") != (p.SSLKey == "") {
+		errs = append(errs, errors.New("postgres.sslcert and postgres.sslkey must be set together"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// ConnString returns URL if it is set, otherwise it returns a connection string in URL format
+// with the connection settings. User, password and other values are escaped.
+func (p Postgres) ConnString() string {
+	if p.URL != "" {
+		return p.URL
+	}
+
+	query := url.Values{}
+	connURL := url.URL{
+		Scheme: "postgres",
+		Path:   "/" + p.DB,
+	}
+
+	if p.Password != "" {
+		connURL.User = url.UserPassword(p.User, p.Password)
+	} else {
+		connURL.User = url.User(p.User)
+	}
+
+	// Path of unix socket directory cannot be the host part of URL.
+	if strings.HasPrefix(p.Host, "/") {
+		query.Set("host", p.Host)
+		query.Set("port", strconv.Itoa(p.Port))
+	} else {
+		connURL.Host = net.JoinHostPort(p.Host, strconv.Itoa(p.Port))
+	}
+
+	params := []struct{ key, value string }{
+		{key: "sslmode", value: p.SSLMode},
+		{key: "sslrootcert", value: p.SSLRootCert},
+		{key: "sslcert", value: p.SSLCert},
+		{key: "sslkey", value: p.SSLKey},
+	}
+
+	for _, param := range params {
+		if param.value != "" {
+			query.Set(param.key, param.value)
+		}
+	}
+
+	connURL.RawQuery = query.Encode()
+	return connURL.String()
+}
+
+func (c Cache) validate() error {
+	var errs []error
+	if c.Size <= 0 {
+		errs = append(errs, fmt.Errorf("cache.size must be positive, got %d", c.Size))
+	}
+
+	if c.TTL <= 0 {
+		errs = append(errs, fmt.Errorf("cache.ttl must be positive, got %s", c.TTL))
+	}
+
+	if c.NegativeTTL <= 0 {
+		errs = append(errs, fmt.Errorf("cache.negative_ttl must be positive, got %s", c.NegativeTTL))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r RateLimits) validate() error {
+	var errs []error
+	if r.CreateRate < 0 {
+		errs = append(errs, fmt.Errorf("rate_limits.create_rate must not be negative, got %v", r.CreateRate))
+	}
+
+	if r.CreateBurst <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limits.create_burst must be positive, got %d", r.CreateBurst))
+	}
+
+	if r.ResolveRate < 0 {
+		errs = append(errs, fmt.Errorf("rate_limits.resolve_rate must not be negative, got %v", r.ResolveRate))
+	}
+
+	if r.ResolveBurst <= 0 {
+		errs = append(errs, fmt.Errorf("rate_limits.resolve_burst must be positive, got %d", r.ResolveBurst))
+	}
+
+	return errors.Join(errs...)
+}
+
+func (t Tracing) validate() error {
+	switch tracing.Exporter(t.Exporter) {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+		return nil
+	default:
+		return fmt.Errorf("tracing.exporter must be %q, %q or %q, got %q",
+			tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, t.Exporter)
+	}
+}
+
+func (r Redis) validate() error {
+	if r.DB < 0 {
+		return fmt.Errorf("redis.db must not be negative, got %d", r.DB)
+	}
+
+	return nil
+}
+
+func (j Journal) validate() error {
+	var errs []error
+	if j.SyncInterval < 0 {
+		errs = append(errs, fmt.Errorf("journal.sync_interval must not be negative, got %s", j.SyncInterval))
+	}
+
+	if j.SnapshotInterval < 0 {
+		errs = append(errs, fmt.Errorf("journal.snapshot_interval must not be negative, got %s", j.SnapshotInterval))
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv is a name of the environment variable with a path to the configuration file.
+// Flag "-config" overrides it.
+const FileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Loader loads the configuration with values of flags, registered by it in a flag set.
+//
+// The zero value is not useful, you must use NewLoader to create an instance.
+type Loader struct {
+	path      string
+	flags     map[string]string
+	lookupEnv func(key string) (string, bool)
+}
+
+// NewLoader registers flag "-config" with a path to the configuration file and flags of
+// the fields of Config with given names in the flag set. Values of the flags are used by Load
+// after the flag set is parsed. It panics if some name is not a flag of Config fields.
+func NewLoader(flags *flag.FlagSet, names ...string) *Loader {
+	loader := &Loader{
+		flags:     make(map[string]string),
+		lookupEnv: os.LookupEnv,
+	}
+
+	flags.StringVar(&loader.path, "config", "", "Path to the YAML configuration file, it overrides "+FileEnv+" env")
+
+	fields := make(map[string]reflect.StructField)
+	walkFields(reflect.ValueOf(&Config{}).Elem(), "", func(_ reflect.Value, field reflect.StructField, _ string) {
+		if name := field.Tag.Get("flag"); name != "" {
+			fields[name] = field
+		}
+	})
+
+	for _, name := range names {
+		field, ok := fields[name]
+		if !ok {
+			panic(fmt.Sprintf("config: unknown flag %q", name))
+		}
+
+		set := func(value string) error {
+			loader.flags[name] = value
+			return nil
+		}
+
+		if field.Type.Kind() == reflect.Bool {
+			flags.BoolFunc(name, field.Tag.Get("usage"), set)
+		} else {
+			flags.Func(name, field.Tag.Get("usage"), set)
+		}
+	}
+
+	return loader
+}
+
+// Load returns the configuration with default values overridden by values of the file,
+// environment variables and flags. It returns all errors of parsing or validation joined.
+func (l *Loader) Load() (Config, error) {
+	config := Default()
+
+	path := l.path
+	if path == "" {
+		path, _ = l.lookupEnv(FileEnv)
+	}
+
+	if path != "" {
+		if err := readFile(path, &config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	var errs []error
+	walkFields(reflect.ValueOf(&config).Elem(), "", func(value reflect.Value, field reflect.StructField, _ string) {
+		if key := field.Tag.Get("env"); key != "" {
+			if raw, isSet := l.lookupEnv(key); isSet {
+				errs = append(errs, setValue(value, raw, key+" env"))
+			}
+		}
+
+		if name := field.Tag.Get("flag"); name != "" {
+			if raw, isSet := l.flags[name]; isSet {
+				errs = append(errs, setValue(value, raw, "flag -"+name))
+			}
+		}
+	})
+
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return config, nil
+}
+
+// readFile decodes the YAML file to the configuration. Unknown keys are errors.
+func readFile(path string, config *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open configuration file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read configuration file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// walkFields calls fn for every field of the struct, that is not a struct itself, with its
+// path of YAML keys. Nested structs are walked recursively.
+func walkFields(value reflect.Value, path string, fn func(value reflect.Value, field reflect.StructField, path string)) {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		fieldPath := field.Tag.Get("yaml")
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+
+		if field.Type.Kind() == reflect.Struct {
+			walkFields(value.Field(i), fieldPath, fn)
+			continue
+		}
+
+		fn(value.Field(i), field, fieldPath)
+	}
+}
+
+// setValue parses the raw value from the source to the field value. Slices of strings are comma-separated.
+func setValue(value reflect.Value, raw, source string) error {
+	if value.Type() == durationType {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%s contains not duration: %w", source, err)
+		}
+
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s contains not bool: %w", source, err)
+		}
+
+		value.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%s contains not int: %w", source, err)
+		}
+
+		value.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s contains not number: %w", source, err)
+		}
+
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		var values []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+
+		value.Set(reflect.ValueOf(values))
+	default:
+		panic(fmt.Sprintf("config: unsupported type %s", value.Type()))
+	}
+
+	return nil
+}
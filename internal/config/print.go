@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces values of secret fields in printed configuration.
+const redacted = "REDACTED"
+
+// Print writes the configuration in YAML format of the configuration file.
+// Values of secret fields, like passwords and API keys, are replaced if they are set.
+func Print(w io.Writer, config Config) error {
+	node, err := structNode(reflect.ValueOf(config))
+	if err != nil {
+		return err
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(node); err != nil {
+		return fmt.Errorf("failed to print configuration: %w", err)
+	}
+
+	return encoder.Close()
+}
+
+// structNode returns a mapping node with fields of the struct in order of their declaration.
+func structNode(value reflect.Value) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		key := &yaml.Node{Kind: yaml.ScalarNode, Value: field.Tag.Get("yaml")}
+
+		var (
+			fieldNode *yaml.Node
+			err       error
+		)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			fieldNode, err = structNode(value.Field(i))
+		case field.Tag.Get("secret") == "true" && !value.Field(i).IsZero():
+			fieldNode = &yaml.Node{Kind: yaml.ScalarNode, Value: redacted}
+		case field.Type == durationType:
+			fieldNode = &yaml.Node{Kind: yaml.ScalarNode, Value: time.Duration(value.Field(i).Int()).String()}
+		default:
+			fieldNode = &yaml.Node{}
+			err = fieldNode.Encode(value.Field(i).Interface())
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to print %s: %w", key.Value, err)
+		}
+
+		node.Content = append(node.Content, key, fieldNode)
+	}
+
+	return node, nil
+}
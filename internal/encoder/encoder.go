@@ -1,6 +1,8 @@
 // Package encoder provides a type for encoding integers into strings to generate unique identifiers for id values.
 package encoder
 
+import "bytes"
+
 // IDEncoder is an interface that describes type encoding integer into string.
 // It can be used to mock that internal type.
 type IDEncoder interface {
@@ -77,6 +79,23 @@ func (e idEncoder) repeatedZeroChar(repeatsCount int) []byte {
 	return result
 }
 
+// IsBaseEncoded reports whether the string is not empty and consists only of symbols that
+// encoder is using, so the string has a form of encoded id.
+func IsBaseEncoded(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	baseChars := baseCharSet()
+	for i := 0; i < len(s); i++ {
+		if !bytes.Contains(baseChars, []byte{s[i]}) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // baseCharSet returns filled slice with desired symbols to encode in bytes.
 // It is using ranges 0-9, A-Z, a-z and '_' symbol.
 func baseCharSet() []byte {
@@ -82,3 +82,43 @@ func Test_charSet(t *testing.T) {
 	result := baseCharSet()
 	assert.Equal(t, expectedSet, result)
 }
+
+func TestIsBaseEncoded(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{
+			name:     "encoded id",
+			input:    NewIDEncoder().EncodeID(12345, defaultLen),
+			expected: true,
+		},
+		{
+			name:     "all symbol ranges",
+			input:    "_09azAZ",
+			expected: true,
+		},
+		{
+			name:     "symbol out of set",
+			input:    "launch-2026",
+			expected: false,
+		},
+		{
+			name:     "not ascii symbol",
+			input:    "ссылка",
+			expected: false,
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsBaseEncoded(tt.input))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package lru
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Get(t *testing.T) {
+	sut := New[string, int](2)
+	sut.Add("a", 1, time.Minute)
+
+	value, isFound := sut.Get("a")
+	assert.True(t, isFound)
+	assert.Equal(t, 1, value)
+
+	_, isFound = sut.Get("b")
+	assert.False(t, isFound)
+}
+
+func TestCache_Eviction(t *testing.T) {
+	sut := New[string, int](2)
+	sut.Add("a", 1, time.Minute)
+	sut.Add("b", 2, time.Minute)
+	sut.Get("a")
+	sut.Add("c", 3, time.Minute)
+
+	_, isFound := sut.Get("b")
+	assert.False(t, isFound, "least recently used entry must be evicted")
+
+	_, isFound = sut.Get("a")
+	assert.True(t, isFound)
+
+	_, isFound = sut.Get("c")
+	assert.True(t, isFound)
+	assert.Equal(t, 2, sut.Len())
+}
+
+func TestCache_Expiration(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sut := New[string, int](2)
+	sut.now = func() time.Time { return now }
+
+	sut.Add("a", 1, time.Second)
+	now = now.Add(time.Second)
+
+	_, isFound := sut.Get("a")
+	assert.False(t, isFound)
+	assert.Equal(t, 0, sut.Len())
+}
+
+func TestCache_AddExisting(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sut := New[string, int](2)
+	sut.now = func() time.Time { return now }
+
+	sut.Add("a", 1, time.Second)
+	sut.Add("a", 2, time.Minute)
+	now = now.Add(time.Second)
+
+	value, isFound := sut.Get("a")
+	assert.True(t, isFound)
+	assert.Equal(t, 2, value)
+	assert.Equal(t, 1, sut.Len())
+}
+
+func TestCache_Remove(t *testing.T) {
+	sut := New[string, int](2)
+	sut.Add("a", 1, time.Minute)
+	sut.Remove("a")
+	sut.Remove("b")
+
+	_, isFound := sut.Get("a")
+	assert.False(t, isFound)
+}
+
+func TestCache_Concurrency(t *testing.T) {
+	sut := New[string, int](10)
+
+	var wg sync.WaitGroup
+	for i := range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := strconv.Itoa(i % 20)
+			sut.Add(key, i, time.Minute)
+			sut.Get(key)
+			sut.Remove(strconv.Itoa(i % 7))
+		}()
+	}
+
+	wg.Wait()
+	assert.LessOrEqual(t, sut.Len(), 10)
+}
@@ -0,0 +1,105 @@
+// Package metrics provides Prometheus metrics of servers, storages and short URLs.
+//
+// Metrics are registered in their own registry, so they can be exposed with Handler
+// and tested independently of the default registry.
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shorturl"
+
+// Metrics is a collection of metrics of the program.
+//
+// It must be initialized with New.
+type Metrics struct {
+	registry         *prometheus.Registry
+	requests         *prometheus.CounterVec
+	requestDurations *prometheus.HistogramVec
+	storageDurations *prometheus.HistogramVec
+	linksCreated     prometheus.Counter
+	linksResolved    prometheus.Counter
+}
+
+// New initializes Metrics with a new registry. Go runtime and process metrics are registered too.
+func New() *Metrics {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	m := &Metrics{
+		registry: registry,
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "Count of handled requests.",
+		}, []string{"transport", "method", "code"}),
+		requestDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of handling requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"transport", "method", "code"}),
+		storageDurations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Duration of storage operations.",
+			Buckets:   []float64{.0001, .0005, .001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"storage", "operation", "result"}),
+		linksCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "links_created_total",
+			Help:      "Count of short URLs returned to create requests, including reused ones.",
+		}),
+		linksResolved: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "links_resolved_total",
+			Help:      "Count of short URLs resolved to original URLs.",
+		}),
+	}
+
+	registry.MustRegister(m.requests, m.requestDurations, m.storageDurations, m.linksCreated, m.linksResolved)
+	return m
+}
+
+// Register registers additional collectors, for example PoolCollector.
+func (m *Metrics) Register(collector prometheus.Collector) error {
+	return m.registry.Register(collector)
+}
+
+// Handler returns HTTP handler exposing all registered metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest counts the request handled by the transport and observes its duration.
+// Method must be a route or RPC name, not a raw path, to keep count of label values low.
+func (m *Metrics) ObserveRequest(transport, method, code string, duration time.Duration) {
+	m.requests.WithLabelValues(transport, method, code).Inc()
+	m.requestDurations.WithLabelValues(transport, method, code).Observe(duration.Seconds())
+}
+
+// ObserveStorageOperation observes duration of the storage operation and its result.
+func (m *Metrics) ObserveStorageOperation(storage, operation string, duration time.Duration, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	m.storageDurations.WithLabelValues(storage, operation, result).Observe(duration.Seconds())
+}
+
+// AddLinksCreated adds count of short URLs returned to create requests.
+func (m *Metrics) AddLinksCreated(count int) {
+	m.linksCreated.Add(float64(count))
+}
+
+// AddLinksResolved adds count of short URLs resolved to original URLs.
+func (m *Metrics) AddLinksResolved(count int) {
+	m.linksResolved.Add(float64(count))
+}
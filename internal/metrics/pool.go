@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector collects statistics of PostgreSQL connection pool on every scrape.
+//
+// It must be initialized with NewPoolCollector.
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns       *prometheus.Desc
+	idleConns           *prometheus.Desc
+	constructingConns   *prometheus.Desc
+	totalConns          *prometheus.Desc
+	maxConns            *prometheus.Desc
+	acquires            *prometheus.Desc
+	acquireDuration     *prometheus.Desc
+	emptyAcquires       *prometheus.Desc
+	canceledAcquires    *prometheus.Desc
+	newConns            *prometheus.Desc
+	maxLifetimeDestroys *prometheus.Desc
+	maxIdleTimeDestroys *prometheus.Desc
+}
+
+// NewPoolCollector initializes PoolCollector of the pool.
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "pgxpool", name), help, nil, nil)
+	}
+
+	return &PoolCollector{
+		pool:                pool,
+		acquiredConns:       desc("acquired_conns", "Count of currently acquired connections."),
+		idleConns:           desc("idle_conns", "Count of currently idle connections."),
+		constructingConns:   desc("constructing_conns", "Count of connections that are being constructed."),
+		totalConns:          desc("total_conns", "Total count of connections in the pool."),
+		maxConns:            desc("max_conns", "Maximum size of the pool."),
+		acquires:            desc("acquires_total", "Count of successful acquires of connections."),
+		acquireDuration:     desc("acquire_duration_seconds_total", "Total duration of successful acquires."),
+		emptyAcquires:       desc("empty_acquires_total", "Count of acquires that waited for a connection because the pool was empty."),
+		canceledAcquires:    desc("canceled_acquires_total", "Count of acquires canceled by context."),
+		newConns:            desc("new_conns_total", "Count of opened connections."),
+		maxLifetimeDestroys: desc("max_lifetime_destroys_total", "Count of connections closed because of max lifetime."),
+		maxIdleTimeDestroys: desc("max_idle_time_destroys_total", "Count of connections closed because of max idle time."),
+	}
+}
+
+// Describe sends descriptors of all pool metrics.
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs() {
+		ch <- desc
+	}
+}
+
+// Collect sends current values of pool statistics.
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructingConns, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquires, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.newConns, prometheus.CounterValue, float64(stat.NewConnsCount()))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeDestroys, prometheus.CounterValue, float64(stat.MaxLifetimeDestroyCount()))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeDestroys, prometheus.CounterValue, float64(stat.MaxIdleDestroyCount()))
+}
+
+func (c *PoolCollector) descs() []*prometheus.Desc {
+	return []*prometheus.Desc{
+		c.acquiredConns, c.idleConns, c.constructingConns, c.totalConns, c.maxConns, c.acquires,
+		c.acquireDuration, c.emptyAcquires, c.canceledAcquires, c.newConns, c.maxLifetimeDestroys, c.maxIdleTimeDestroys,
+	}
+}
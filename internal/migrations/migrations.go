@@ -0,0 +1,382 @@
+// Package migrations provides versioned migrations of PostgreSQL scheme.
+//
+// Migrations are embedded in the program, each of them is a pair of files
+// "sql/<version>_<name>.up.sql" and "sql/<version>_<name>.down.sql". Applied versions
+// are saved in the table schema_migrations. Migrations are run under an advisory lock,
+// so several instances of the program, starting at once, do not race.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockKey is a key of the advisory lock taken while migrations are run.
+const lockKey = 7_401_512_981
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// fileNamePattern matches names of migration files, groups are version, name and direction.
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrOutdatedScheme is returned when the database scheme has not applied migrations.
+var ErrOutdatedScheme = errors.New("database scheme is out of date")
+
+// Migration is a versioned change of the scheme with SQL to apply and to roll back it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration with time of its applying. AppliedAt is zero if it is not applied.
+type Status struct {
+	Migration
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back embedded migrations.
+//
+// The zero value is not useful, you must use New to create an instance.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// New initializes a new Migrator with the given database connection pool and the embedded migrations.
+// It returns an error if the embedded files are not a sequence of pairs of migrations, starting from version 1.
+func New(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := readMigrations(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Latest returns the version of the last migration.
+func (m *Migrator) Latest() int {
+	return len(m.migrations)
+}
+
+// Up applies all not applied migrations in order of versions, each in its own transaction.
+// It returns the applied migrations, that also are returned if some next migration fails.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, current int) error {
+		for _, migration := range m.migrations[current:] {
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, migration.Up); err != nil {
+					return err
+				}
+
+				_, err := tx.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2);",
+					migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d %s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last applied migration and returns it.
+// It returns false if there are no applied migrations.
+func (m *Migrator) Down(ctx context.Context) (Migration, bool, error) {
+	var (
+		rolledBack Migration
+		ok         bool
+	)
+
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, current int) error {
+		if current == 0 {
+			return nil
+		}
+
+		migration := m.migrations[current-1]
+		err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, migration.Down); err != nil {
+				return err
+			}
+
+			_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version = $1;", migration.Version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration %d %s: %w", migration.Version, migration.Name, err)
+		}
+
+		rolledBack, ok = migration, true
+		return nil
+	})
+
+	return rolledBack, ok, err
+}
+
+// Status returns all migrations with time of their applying.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, Status{Migration: migration, AppliedAt: applied[migration.Version]})
+	}
+
+	return statuses, nil
+}
+
+// Check returns an error wrapping ErrOutdatedScheme if some migrations are not applied.
+// It also returns an error if the database has migrations that are unknown to the program.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	for version := range applied {
+		if version > m.Latest() {
+			return fmt.Errorf("database scheme has unknown migration %d, latest known is %d", version, m.Latest())
+		}
+	}
+
+	if len(applied) < m.Latest() {
+		return fmt.Errorf("%w: %d of %d migrations are applied", ErrOutdatedScheme, len(applied), m.Latest())
+	}
+
+	return nil
+}
+
+// applied returns times of applying by versions of applied migrations.
+// There are no applied migrations if the table schema_migrations does not exist.
+func (m *Migrator) applied(ctx context.Context) (map[int]time.Time, error) {
+	const sql = `SELECT version, applied_at FROM schema_migrations;`
+
+	var exists bool
+	err := m.pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL;").Scan(&exists)
+	if err != nil || !exists {
+		return nil, wrapError(err)
+	}
+
+	rows, err := m.pool.Query(ctx, sql)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var (
+		version   int
+		appliedAt time.Time
+	)
+
+	applied := make(map[int]time.Time)
+	_, err = pgx.ForEachRow(rows, []any{&version, &appliedAt}, func() error {
+		applied[version] = appliedAt
+		return nil
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return applied, nil
+}
+
+// withLock calls fn with a connection holding the advisory lock and the current version of the scheme.
+// It creates the table schema_migrations if it does not exist.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, current int) error) (err error) {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1);", lockKey); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	defer func() {
+		// The lock is released together with the session if the connection is broken.
+		_, unlockErr := conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1);", lockKey)
+		err = errors.Join(err, wrapError(unlockErr))
+	}()
+
+	current, err := m.prepare(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, current)
+}
+
+// prepare creates the table schema_migrations and returns the current version of the scheme.
+// Versions of a scheme, created before migrations, are saved as applied.
+func (m *Migrator) prepare(ctx context.Context, conn *pgxpool.Conn) (int, error) {
+	const createSQL = `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+	`
+
+	if _, err := conn.Exec(ctx, createSQL); err != nil {
+		return 0, wrapError(err)
+	}
+
+	var current int
+	err := conn.QueryRow(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations;").Scan(&current)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+
+	if current > m.Latest() {
+		return 0, fmt.Errorf("database scheme has unknown migration %d, latest known is %d", current, m.Latest())
+	}
+
+	if current != 0 {
+		return current, nil
+	}
+
+	return m.baseline(ctx, conn)
+}
+
+// legacyObjects are tables and columns created by each version of the scheme,
+// used to detect a version of the scheme created before migrations. Empty column means a table.
+var legacyObjects = []struct{ table, column string }{
+	{table: "original_urls"},
+	{table: "short_urls", column: "reusable"},
+	{table: "short_urls", column: "expires_at"},
+	{table: "url_stats"},
+	{table: "short_urls", column: "disabled"},
+	{table: "short_url_changes"},
+	{table: "api_keys"},
+	{table: "short_urls", column: "owner"},
+}
+
+// baseline detects a version of the scheme created before migrations by its tables and columns,
+// saves versions up to it as applied and returns it.
+func (m *Migrator) baseline(ctx context.Context, conn *pgxpool.Conn) (int, error) {
+	const sql = `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name::text = $1::text
+				AND ($2::text = '' OR column_name::text = $2::text)
+		);
+	`
+
+	version := 0
+	for _, object := range legacyObjects[:min(len(legacyObjects), m.Latest())] {
+		var exists bool
+		if err := conn.QueryRow(ctx, sql, object.table, object.column).Scan(&exists); err != nil {
+			return 0, wrapError(err)
+		}
+
+		if !exists {
+			break
+		}
+
+		version++
+	}
+
+	for _, migration := range m.migrations[:version] {
+		_, err := conn.Exec(ctx, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2);",
+			migration.Version, migration.Name)
+		if err != nil {
+			return 0, wrapError(err)
+		}
+	}
+
+	return version, nil
+}
+
+// readMigrations reads migrations from files in the directory "sql" sorted by versions.
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("unexpected name of migration file %q", entry.Name())
+		}
+
+		version, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return nil, fmt.Errorf("unexpected version of migration file %q: %w", entry.Name(), err)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join("sql", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has different names %q and %q", version, migration.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d %s must have both up and down files", migration.Version, migration.Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return fmt.Errorf("failed to query schema migrations in db: %w", err)
+}
@@ -0,0 +1,206 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/pgtest"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// newTestMigrator returns a migrator of the empty scheme "migrations_test" in the test database,
+// so migrations are run apart from the scheme used by tests of other packages. The scheme is dropped
+// when the test ends, the test is skipped if the database is unavailable.
+func newTestMigrator(t *testing.T) (*Migrator, *pgxpool.Pool) {
+	t.Helper()
+
+	const schema = "migrations_test"
+	ctx := context.Background()
+	config, err := pgxpool.ParseConfig(pgtest.DatabaseURL(t))
+	require.NoError(t, err)
+
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	dropSchema := func() error {
+		_, err := pool.Exec(context.Background(), "DROP SCHEMA IF EXISTS "+pgx.Identifier{schema}.Sanitize()+" CASCADE;")
+		return err
+	}
+	require.NoError(t, dropSchema())
+	_, err = pool.Exec(ctx, "CREATE SCHEMA "+pgx.Identifier{schema}.Sanitize()+";")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, dropSchema())
+	})
+
+	migrator, err := New(pool)
+	require.NoError(t, err)
+	return migrator, pool
+}
+
+// downAll rolls back all applied migrations.
+func downAll(ctx context.Context, migrator *Migrator) error {
+	for {
+		_, ok, err := migrator.Down(ctx)
+		if err != nil || !ok {
+			return err
+		}
+	}
+}
+
+func TestMigrator_UpDownUp(t *testing.T) {
+	migrator, _ := newTestMigrator(t)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, migrator.Latest())
+	require.NoError(t, migrator.Check(ctx))
+
+	require.NoError(t, downAll(ctx, migrator))
+	assert.ErrorIs(t, migrator.Check(ctx), ErrOutdatedScheme)
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, migrator.Latest())
+	assert.NoError(t, migrator.Check(ctx))
+}
+
+func TestMigrator_DownWithLinks(t *testing.T) {
+	migrator, pool := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	const insertSQL = `
+		INSERT INTO original_urls (url) VALUES ('https://example.com'), ('https://example.org');
+		INSERT INTO short_urls (original_url, url) VALUES
+			('https://example.com', '1234567890'),
+			('https://example.org', 'long-short-url');
+	`
+	_, err = pool.Exec(ctx, insertSQL)
+	require.NoError(t, err)
+
+	err = downAll(ctx, migrator)
+	assert.ErrorContains(t, err, "cannot roll back short URL identity")
+
+	var count int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT count(*) FROM short_urls;").Scan(&count))
+	assert.Equal(t, 2, count, "Links are lost by refused roll back")
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, migrator.Check(ctx))
+}
+
+func TestNew_EmbeddedMigrations(t *testing.T) {
+	migrator, err := New(nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, len(legacyObjects), migrator.Latest(), "Scheme before migrations is not detected")
+	for _, migration := range migrator.migrations {
+		assert.NotEmpty(t, migration.Name)
+	}
+}
+
+func TestReadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("up 2")},
+		"sql/0001_first.down.sql":  {Data: []byte("down 1")},
+		"sql/0002_second.down.sql": {Data: []byte("down 2")},
+		"sql/0001_first.up.sql":    {Data: []byte("up 1")},
+	}
+
+	migrations, err := readMigrations(fsys)
+	require.NoError(t, err)
+
+	expected := []Migration{
+		{Version: 1, Name: "first", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+	}
+	assert.Equal(t, expected, migrations)
+}
+
+func TestReadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+	}{
+		{
+			name:  "unexpected name",
+			files: []string{"sql/0001_first.sql"},
+		},
+		{
+			name:  "missing version",
+			files: []string{"sql/0002_second.up.sql", "sql/0002_second.down.sql"},
+		},
+		{
+			name:  "missing down",
+			files: []string{"sql/0001_first.up.sql"},
+		},
+		{
+			name:  "different names",
+			files: []string{"sql/0001_first.up.sql", "sql/0001_second.down.sql"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fsys := make(fstest.MapFS)
+			for _, file := range tt.files {
+				fsys[file] = &fstest.MapFile{Data: []byte("SELECT 1;")}
+			}
+
+			_, err := readMigrations(fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMigrator_DownWithOwners(t *testing.T) {
+	migrator, pool := newTestMigrator(t)
+	ctx := context.Background()
+
+	_, err := migrator.Up(ctx)
+	require.NoError(t, err)
+
+	const insertSQL = `
+		INSERT INTO original_urls (url) VALUES ('https://example.com');
+		INSERT INTO short_urls (original_url, url, owner) VALUES
+			('https://example.com', '1234567890', 'owner'),
+			('https://example.com', '0987654321', '');
+	`
+	_, err = pool.Exec(ctx, insertSQL)
+	require.NoError(t, err)
+
+	rolledBack, ok, err := migrator.Down(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "link_owners", rolledBack.Name)
+
+	rows, err := pool.Query(ctx, "SELECT url FROM short_urls WHERE reusable;")
+	require.NoError(t, err)
+	reusable, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0987654321"}, reusable, "Anonymous link is not kept reusable")
+
+	var count int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT count(*) FROM short_urls;").Scan(&count))
+	assert.Equal(t, 2, count, "Links of owners are lost by roll back")
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.NoError(t, migrator.Check(ctx))
+}
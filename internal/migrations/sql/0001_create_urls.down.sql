@@ -0,0 +1,2 @@
+DROP TABLE short_urls;
+DROP TABLE original_urls;
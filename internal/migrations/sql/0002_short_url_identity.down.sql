@@ -0,0 +1,16 @@
+-- The previous scheme keeps one short URL of up to 10 characters for each original URL,
+-- so aliases and longer short URLs cannot be rolled back without losing links.
+DO $$
+BEGIN
+    IF EXISTS (SELECT FROM short_urls WHERE NOT reusable OR length(url) > 10) THEN
+        RAISE EXCEPTION 'cannot roll back short URL identity: short_urls has aliases or short URLs longer than 10 characters, delete them first';
+    END IF;
+END
+$$;
+
+DROP INDEX short_urls_reusable_original_url_key;
+ALTER TABLE short_urls DROP COLUMN reusable;
+ALTER TABLE short_urls ALTER COLUMN url TYPE VARCHAR(10);
+ALTER TABLE short_urls DROP CONSTRAINT short_urls_pkey;
+ALTER TABLE short_urls ADD CONSTRAINT short_urls_url_key UNIQUE (url);
+ALTER TABLE short_urls ADD PRIMARY KEY (original_url);
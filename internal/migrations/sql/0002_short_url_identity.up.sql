@@ -0,0 +1,9 @@
+ALTER TABLE short_urls DROP CONSTRAINT short_urls_pkey;
+ALTER TABLE short_urls DROP CONSTRAINT short_urls_url_key;
+ALTER TABLE short_urls ADD PRIMARY KEY (url);
+ALTER TABLE short_urls ALTER COLUMN url TYPE VARCHAR(64);
+
+-- Reusable short URL is returned to every request shortening its original URL,
+-- aliases are never reused.
+ALTER TABLE short_urls ADD COLUMN reusable BOOLEAN NOT NULL DEFAULT TRUE;
+CREATE UNIQUE INDEX short_urls_reusable_original_url_key ON short_urls (original_url) WHERE reusable;
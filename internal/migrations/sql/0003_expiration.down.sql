@@ -0,0 +1,2 @@
+DROP INDEX short_urls_expires_at_idx;
+ALTER TABLE short_urls DROP COLUMN expires_at;
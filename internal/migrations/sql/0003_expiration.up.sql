@@ -0,0 +1,3 @@
+-- Links without expiration time never expire.
+ALTER TABLE short_urls ADD COLUMN expires_at TIMESTAMPTZ;
+CREATE INDEX short_urls_expires_at_idx ON short_urls (expires_at) WHERE expires_at IS NOT NULL;
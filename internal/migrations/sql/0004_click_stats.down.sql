@@ -0,0 +1,2 @@
+DROP TABLE url_click_counts;
+DROP TABLE url_stats;
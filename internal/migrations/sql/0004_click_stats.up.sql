@@ -0,0 +1,15 @@
+-- Click statistics are removed together with their links.
+CREATE TABLE url_stats (
+    short_url VARCHAR(64) PRIMARY KEY REFERENCES short_urls (url) ON DELETE CASCADE,
+    clicks BIGINT NOT NULL,
+    last_click_at TIMESTAMPTZ NOT NULL
+);
+
+-- Counts of clicks for each value of each click dimension: day, referrer, user_agent, network.
+CREATE TABLE url_click_counts (
+    short_url VARCHAR(64) NOT NULL REFERENCES short_urls (url) ON DELETE CASCADE,
+    dimension VARCHAR(16) NOT NULL,
+    value VARCHAR(256) NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (short_url, dimension, value)
+);
@@ -0,0 +1,2 @@
+-- Disabled links are kept, but not resolved.
+ALTER TABLE short_urls ADD COLUMN disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1 @@
+DROP TABLE short_url_changes;
@@ -0,0 +1,9 @@
+-- Changes of original URLs mapped with short URLs, removed together with their links.
+CREATE TABLE short_url_changes (
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    short_url VARCHAR(64) NOT NULL REFERENCES short_urls (url) ON DELETE CASCADE,
+    previous_url TEXT NOT NULL,
+    new_url TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX short_url_changes_short_url_idx ON short_url_changes (short_url, id);
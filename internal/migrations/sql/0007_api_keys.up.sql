@@ -0,0 +1,6 @@
+-- API keys are saved as hex encoded SHA-256 hashes, never in plain text.
+CREATE TABLE api_keys (
+    key_hash CHAR(64) PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,14 @@
+DROP INDEX short_urls_owner_url_idx;
+DROP INDEX short_urls_reusable_owner_original_url_key;
+
+-- The previous scheme keeps one reusable short URL for each original URL, reusable short URLs
+-- of other owners are kept as aliases, preferring the anonymous one as reusable.
+UPDATE short_urls SET reusable = FALSE
+WHERE reusable AND url NOT IN (
+    SELECT DISTINCT ON (original_url) url FROM short_urls
+    WHERE reusable
+    ORDER BY original_url, owner <> '', url
+);
+
+ALTER TABLE short_urls DROP COLUMN owner;
+CREATE UNIQUE INDEX short_urls_reusable_original_url_key ON short_urls (original_url) WHERE reusable;
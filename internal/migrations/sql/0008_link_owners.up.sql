@@ -0,0 +1,6 @@
+-- Links are owned by callers that created them, empty owner means an anonymous link.
+-- Every owner gets its own reusable short URL for the same original URL.
+ALTER TABLE short_urls ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+DROP INDEX short_urls_reusable_original_url_key;
+CREATE UNIQUE INDEX short_urls_reusable_owner_original_url_key ON short_urls (owner, original_url) WHERE reusable;
+CREATE INDEX short_urls_owner_url_idx ON short_urls (owner, url);
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,8 +20,19 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ShortURLService_CreateShortURL_FullMethodName = "/shorturl.ShortURLService/CreateShortURL"
-	ShortURLService_GetOriginalURL_FullMethodName = "/shorturl.ShortURLService/GetOriginalURL"
+	ShortURLService_CreateShortURL_FullMethodName       = "/shorturl.ShortURLService/CreateShortURL"
+	ShortURLService_GetOriginalURL_FullMethodName       = "/shorturl.ShortURLService/GetOriginalURL"
+	ShortURLService_GetStats_FullMethodName             = "/shorturl.ShortURLService/GetStats"
+	ShortURLService_BatchCreateShortURLs_FullMethodName = "/shorturl.ShortURLService/BatchCreateShortURLs"
+	ShortURLService_BatchGetOriginalURLs_FullMethodName = "/shorturl.ShortURLService/BatchGetOriginalURLs"
+	ShortURLService_ImportURLs_FullMethodName           = "/shorturl.ShortURLService/ImportURLs"
+	ShortURLService_ExportURLs_FullMethodName           = "/shorturl.ShortURLService/ExportURLs"
+	ShortURLService_DeleteShortURL_FullMethodName       = "/shorturl.ShortURLService/DeleteShortURL"
+	ShortURLService_DisableShortURL_FullMethodName      = "/shorturl.ShortURLService/DisableShortURL"
+	ShortURLService_EnableShortURL_FullMethodName       = "/shorturl.ShortURLService/EnableShortURL"
+	ShortURLService_UpdateShortURL_FullMethodName       = "/shorturl.ShortURLService/UpdateShortURL"
+	ShortURLService_GetHistory_FullMethodName           = "/shorturl.ShortURLService/GetHistory"
+	ShortURLService_ListShortURLs_FullMethodName        = "/shorturl.ShortURLService/ListShortURLs"
 )
 
 // ShortURLServiceClient is the client API for ShortURLService service.
@@ -29,6 +41,17 @@ const (
 type ShortURLServiceClient interface {
 	CreateShortURL(ctx context.Context, in *OriginalURL, opts ...grpc.CallOption) (*ShortURL, error)
 	GetOriginalURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*OriginalURL, error)
+	GetStats(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*Stats, error)
+	BatchCreateShortURLs(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	BatchGetOriginalURLs(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	ImportURLs(ctx context.Context, opts ...grpc.CallOption) (ShortURLService_ImportURLsClient, error)
+	ExportURLs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (ShortURLService_ExportURLsClient, error)
+	DeleteShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	DisableShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	EnableShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*ShortURL, error)
+	GetHistory(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*History, error)
+	ListShortURLs(ctx context.Context, in *ListShortURLsRequest, opts ...grpc.CallOption) (*LinkPage, error)
 }
 
 type shortURLServiceClient struct {
@@ -57,12 +80,170 @@ func (c *shortURLServiceClient) GetOriginalURL(ctx context.Context, in *ShortURL
 	return out, nil
 }
 
+func (c *shortURLServiceClient) GetStats(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*Stats, error) {
+	out := new(Stats)
+	err := c.cc.Invoke(ctx, ShortURLService_GetStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) BatchCreateShortURLs(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, ShortURLService_BatchCreateShortURLs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) BatchGetOriginalURLs(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, ShortURLService_BatchGetOriginalURLs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) ImportURLs(ctx context.Context, opts ...grpc.CallOption) (ShortURLService_ImportURLsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ShortURLService_ServiceDesc.Streams[0], ShortURLService_ImportURLs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortURLServiceImportURLsClient{stream}
+	return x, nil
+}
+
+type ShortURLService_ImportURLsClient interface {
+	Send(*Link) error
+	CloseAndRecv() (*ImportSummary, error)
+	grpc.ClientStream
+}
+
+type shortURLServiceImportURLsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortURLServiceImportURLsClient) Send(m *Link) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *shortURLServiceImportURLsClient) CloseAndRecv() (*ImportSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortURLServiceClient) ExportURLs(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (ShortURLService_ExportURLsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ShortURLService_ServiceDesc.Streams[1], ShortURLService_ExportURLs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &shortURLServiceExportURLsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ShortURLService_ExportURLsClient interface {
+	Recv() (*Link, error)
+	grpc.ClientStream
+}
+
+type shortURLServiceExportURLsClient struct {
+	grpc.ClientStream
+}
+
+func (x *shortURLServiceExportURLsClient) Recv() (*Link, error) {
+	m := new(Link)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *shortURLServiceClient) DeleteShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ShortURLService_DeleteShortURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) DisableShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ShortURLService_DisableShortURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) EnableShortURL(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ShortURLService_EnableShortURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) UpdateShortURL(ctx context.Context, in *UpdateShortURLRequest, opts ...grpc.CallOption) (*ShortURL, error) {
+	out := new(ShortURL)
+	err := c.cc.Invoke(ctx, ShortURLService_UpdateShortURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) GetHistory(ctx context.Context, in *ShortURL, opts ...grpc.CallOption) (*History, error) {
+	out := new(History)
+	err := c.cc.Invoke(ctx, ShortURLService_GetHistory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shortURLServiceClient) ListShortURLs(ctx context.Context, in *ListShortURLsRequest, opts ...grpc.CallOption) (*LinkPage, error) {
+	out := new(LinkPage)
+	err := c.cc.Invoke(ctx, ShortURLService_ListShortURLs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShortURLServiceServer is the server API for ShortURLService service.
 // All implementations must embed UnimplementedShortURLServiceServer
 // for forward compatibility
 type ShortURLServiceServer interface {
 	CreateShortURL(context.Context, *OriginalURL) (*ShortURL, error)
 	GetOriginalURL(context.Context, *ShortURL) (*OriginalURL, error)
+	GetStats(context.Context, *ShortURL) (*Stats, error)
+	BatchCreateShortURLs(context.Context, *BatchRequest) (*BatchResponse, error)
+	BatchGetOriginalURLs(context.Context, *BatchRequest) (*BatchResponse, error)
+	ImportURLs(ShortURLService_ImportURLsServer) error
+	ExportURLs(*emptypb.Empty, ShortURLService_ExportURLsServer) error
+	DeleteShortURL(context.Context, *ShortURL) (*emptypb.Empty, error)
+	DisableShortURL(context.Context, *ShortURL) (*emptypb.Empty, error)
+	EnableShortURL(context.Context, *ShortURL) (*emptypb.Empty, error)
+	UpdateShortURL(context.Context, *UpdateShortURLRequest) (*ShortURL, error)
+	GetHistory(context.Context, *ShortURL) (*History, error)
+	ListShortURLs(context.Context, *ListShortURLsRequest) (*LinkPage, error)
 	mustEmbedUnimplementedShortURLServiceServer()
 }
 
@@ -76,6 +257,39 @@ func (UnimplementedShortURLServiceServer) CreateShortURL(context.Context, *Origi
 func (UnimplementedShortURLServiceServer) GetOriginalURL(context.Context, *ShortURL) (*OriginalURL, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOriginalURL not implemented")
 }
+func (UnimplementedShortURLServiceServer) GetStats(context.Context, *ShortURL) (*Stats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedShortURLServiceServer) BatchCreateShortURLs(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateShortURLs not implemented")
+}
+func (UnimplementedShortURLServiceServer) BatchGetOriginalURLs(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGetOriginalURLs not implemented")
+}
+func (UnimplementedShortURLServiceServer) ImportURLs(ShortURLService_ImportURLsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportURLs not implemented")
+}
+func (UnimplementedShortURLServiceServer) ExportURLs(*emptypb.Empty, ShortURLService_ExportURLsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportURLs not implemented")
+}
+func (UnimplementedShortURLServiceServer) DeleteShortURL(context.Context, *ShortURL) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteShortURL not implemented")
+}
+func (UnimplementedShortURLServiceServer) DisableShortURL(context.Context, *ShortURL) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableShortURL not implemented")
+}
+func (UnimplementedShortURLServiceServer) EnableShortURL(context.Context, *ShortURL) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableShortURL not implemented")
+}
+func (UnimplementedShortURLServiceServer) UpdateShortURL(context.Context, *UpdateShortURLRequest) (*ShortURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShortURL not implemented")
+}
+func (UnimplementedShortURLServiceServer) GetHistory(context.Context, *ShortURL) (*History, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedShortURLServiceServer) ListShortURLs(context.Context, *ListShortURLsRequest) (*LinkPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListShortURLs not implemented")
+}
 func (UnimplementedShortURLServiceServer) mustEmbedUnimplementedShortURLServiceServer() {}
 
 // UnsafeShortURLServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +339,215 @@ func _ShortURLService_GetOriginalURL_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShortURLService_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).GetStats(ctx, req.(*ShortURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_BatchCreateShortURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).BatchCreateShortURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_BatchCreateShortURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).BatchCreateShortURLs(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_BatchGetOriginalURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).BatchGetOriginalURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_BatchGetOriginalURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).BatchGetOriginalURLs(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_ImportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ShortURLServiceServer).ImportURLs(&shortURLServiceImportURLsServer{stream})
+}
+
+type ShortURLService_ImportURLsServer interface {
+	SendAndClose(*ImportSummary) error
+	Recv() (*Link, error)
+	grpc.ServerStream
+}
+
+type shortURLServiceImportURLsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortURLServiceImportURLsServer) SendAndClose(m *ImportSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *shortURLServiceImportURLsServer) Recv() (*Link, error) {
+	m := new(Link)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _ShortURLService_ExportURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(emptypb.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ShortURLServiceServer).ExportURLs(m, &shortURLServiceExportURLsServer{stream})
+}
+
+type ShortURLService_ExportURLsServer interface {
+	Send(*Link) error
+	grpc.ServerStream
+}
+
+type shortURLServiceExportURLsServer struct {
+	grpc.ServerStream
+}
+
+func (x *shortURLServiceExportURLsServer) Send(m *Link) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ShortURLService_DeleteShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).DeleteShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_DeleteShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).DeleteShortURL(ctx, req.(*ShortURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_DisableShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).DisableShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_DisableShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).DisableShortURL(ctx, req.(*ShortURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_EnableShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).EnableShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_EnableShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).EnableShortURL(ctx, req.(*ShortURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_UpdateShortURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShortURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).UpdateShortURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_UpdateShortURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).UpdateShortURL(ctx, req.(*UpdateShortURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortURL)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).GetHistory(ctx, req.(*ShortURL))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShortURLService_ListShortURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListShortURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShortURLServiceServer).ListShortURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShortURLService_ListShortURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShortURLServiceServer).ListShortURLs(ctx, req.(*ListShortURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShortURLService_ServiceDesc is the grpc.ServiceDesc for ShortURLService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -140,7 +563,54 @@ var ShortURLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOriginalURL",
 			Handler:    _ShortURLService_GetOriginalURL_Handler,
 		},
+		{
+			MethodName: "GetStats",
+			Handler:    _ShortURLService_GetStats_Handler,
+		},
+		{
+			MethodName: "BatchCreateShortURLs",
+			Handler:    _ShortURLService_BatchCreateShortURLs_Handler,
+		},
+		{
+			MethodName: "BatchGetOriginalURLs",
+			Handler:    _ShortURLService_BatchGetOriginalURLs_Handler,
+		},
+		{
+			MethodName: "DeleteShortURL",
+			Handler:    _ShortURLService_DeleteShortURL_Handler,
+		},
+		{
+			MethodName: "DisableShortURL",
+			Handler:    _ShortURLService_DisableShortURL_Handler,
+		},
+		{
+			MethodName: "EnableShortURL",
+			Handler:    _ShortURLService_EnableShortURL_Handler,
+		},
+		{
+			MethodName: "UpdateShortURL",
+			Handler:    _ShortURLService_UpdateShortURL_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _ShortURLService_GetHistory_Handler,
+		},
+		{
+			MethodName: "ListShortURLs",
+			Handler:    _ShortURLService_ListShortURLs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportURLs",
+			Handler:       _ShortURLService_ImportURLs_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportURLs",
+			Handler:       _ShortURLService_ExportURLs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: ".proto",
 }
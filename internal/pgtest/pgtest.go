@@ -0,0 +1,136 @@
+// Package pgtest provides a PostgreSQL database to tests of packages using it.
+//
+// Tests use the database set by DatabaseURLEnv or an ephemeral embedded server. Binaries
+// of the embedded server are downloaded once to the cache, CacheEnv moves the cache, for
+// example to a directory restored by CI, and BinariesEnv sets already extracted binaries,
+// so tests work offline. If the database is unavailable, tests of the package fail,
+// unless SkipEnv is set to true to skip them explicitly.
+package pgtest
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+)
+
+const (
+	// DatabaseURLEnv is a name of the environment variable with a database url to run the tests against.
+	DatabaseURLEnv = "POSTGRES_TEST_URL"
+	// SkipEnv is a name of the environment variable that skips tests if the database is unavailable.
+	SkipEnv = "SKIP_POSTGRES_TESTS"
+	// CacheEnv is a name of the environment variable with a directory of downloaded archives of binaries.
+	CacheEnv = "EMBEDDED_POSTGRES_CACHE"
+	// BinariesEnv is a name of the environment variable with a directory of extracted binaries.
+	BinariesEnv = "EMBEDDED_POSTGRES_BINARIES"
+)
+
+var (
+	databaseURL string
+	// skipReason is the reason why tests calling DatabaseURL are skipped.
+	skipReason string
+)
+
+// Main starts the database, runs tests of the package and stops it. It must be called by TestMain.
+// It exits with failure without running tests if the database is unavailable and SkipEnv is not set.
+func Main(m *testing.M) {
+	url, stop, err := start()
+	if err != nil {
+		skip, _ := strconv.ParseBool(os.Getenv(SkipEnv))
+		if !skip {
+			fmt.Fprintf(os.Stderr, "PostgreSQL is not available: %s\nSet %s to an existing database, "+
+				"%s or %s to binaries available offline, or %s=true to skip the tests\n",
+				err, DatabaseURLEnv, CacheEnv, BinariesEnv, SkipEnv)
+			os.Exit(1)
+		}
+
+		skipReason = fmt.Sprintf("PostgreSQL is not available and %s is set: %s", SkipEnv, err)
+	}
+
+	databaseURL = url
+	code := m.Run()
+
+	if err := stop(); err != nil {
+		fmt.Fprintln(os.Stderr, "stop embedded postgres:", err)
+	}
+
+	os.Exit(code)
+}
+
+// DatabaseURL returns the url of the test database. It skips the test if the database is unavailable.
+func DatabaseURL(t testing.TB) string {
+	t.Helper()
+
+	if skipReason != "" {
+		t.Skip(skipReason)
+	}
+
+	return databaseURL
+}
+
+// start returns the url of the database set by DatabaseURLEnv or starts the embedded server.
+func start() (string, func() error, error) {
+	noop := func() error { return nil }
+	if url, ok := os.LookupEnv(DatabaseURLEnv); ok {
+		return url, noop, nil
+	}
+
+	url, stop, err := startEmbeddedPostgres()
+	if err != nil {
+		return "", noop, err
+	}
+
+	return url, stop, nil
+}
+
+// startEmbeddedPostgres starts an ephemeral PostgreSQL server on a free port.
+func startEmbeddedPostgres() (string, func() error, error) {
+	port, err := freePort()
+	if err != nil {
+		return "", nil, err
+	}
+
+	runtimePath, err := os.MkdirTemp("", "shorturl-postgres-")
+	if err != nil {
+		return "", nil, err
+	}
+
+	config := embeddedpostgres.DefaultConfig().
+		Port(port).
+		RuntimePath(runtimePath).
+		Logger(io.Discard)
+	if cachePath := os.Getenv(CacheEnv); cachePath != "" {
+		config = config.CachePath(cachePath)
+	}
+
+	if binariesPath := os.Getenv(BinariesEnv); binariesPath != "" {
+		config = config.BinariesPath(binariesPath)
+	}
+
+	postgres := embeddedpostgres.NewDatabase(config)
+	if err := postgres.Start(); err != nil {
+		_ = os.RemoveAll(runtimePath)
+		return "", nil, fmt.Errorf("start embedded postgres: %w", err)
+	}
+
+	stop := func() error {
+		defer os.RemoveAll(runtimePath)
+		return postgres.Stop()
+	}
+
+	return config.GetConnectionURL() + "?sslmode=disable", stop, nil
+}
+
+func freePort() (uint32, error) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return uint32(listener.Addr().(*net.TCPAddr).Port), nil
+}
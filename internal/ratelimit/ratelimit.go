@@ -0,0 +1,30 @@
+// Package ratelimit provides limiting of request rates by keys of clients.
+//
+// Limiter is an interface, so a limiter sharing its state between instances of
+// the program can be used instead of the in-process one.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter decides whether a request of the client with the key is allowed. Cost of the request
+// is a count of items it handles, like URLs of a batch, so every item is charged like a single request.
+type Limiter interface {
+	AllowN(ctx context.Context, key string, cost int) (Decision, error)
+}
+
+// Decision is a result of limiting of a single request.
+//
+// Limit is the maximum cost of requests in a burst, Remaining is the cost of requests
+// that are allowed right after this one. ResetAfter is the time until the client is allowed
+// to make requests of Limit cost again. RetryAfter is the time until the request of the same
+// cost is allowed, it is zero if the request is allowed.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// TokenBucketLimiter is an in-process Limiter using a token bucket for every key.
+// Every bucket holds up to burst tokens and is refilled with rate tokens per second,
+// every allowed request takes tokens of its cost. Request costing more than burst is allowed
+// only if the bucket is full, the bucket goes into debt then, so the whole cost is still charged.
+//
+// Buckets that are full are the same as new ones, so they are removed from time to time.
+// It's safe for concurrent use. It must be initialized with NewTokenBucketLimiter.
+type TokenBucketLimiter struct {
+	rate      float64
+	burst     int
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+	mutex     sync.Mutex
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// NewTokenBucketLimiter initializes TokenBucketLimiter with rate of requests per second and
+// the maximum count of requests in a burst. Both values must be positive.
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of the key if it has one. It never returns an error.
+func (l *TokenBucketLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN takes tokens of the cost from the bucket of the key if it has them. It never returns an error.
+func (l *TokenBucketLimiter) AllowN(_ context.Context, key string, cost int) (Decision, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, isFound := l.buckets[key]
+	if !isFound {
+		b = &bucket{tokens: float64(l.burst), updatedAt: now}
+		l.buckets[key] = b
+	}
+
+	b.refill(now, l.rate, l.burst)
+
+	decision := Decision{Limit: l.burst}
+	required := float64(min(cost, l.burst))
+	if b.tokens >= required {
+		b.tokens -= float64(cost)
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = l.timeToFill(required - b.tokens)
+	}
+
+	decision.Remaining = max(int(b.tokens), 0)
+	decision.ResetAfter = l.timeToFill(float64(l.burst) - b.tokens)
+	return decision, nil
+}
+
+// sweep removes buckets that are full at the moment. It runs not more often than
+// a bucket is filled from empty one, so the cost of sweeping is spread over requests.
+// Mutex must be locked by caller.
+func (l *TokenBucketLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.timeToFill(float64(l.burst)) {
+		return
+	}
+
+	for key, b := range l.buckets {
+		b.refill(now, l.rate, l.burst)
+		if b.tokens >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
+
+// timeToFill returns the time of refilling the count of tokens, rounded up to milliseconds.
+func (l *TokenBucketLimiter) timeToFill(tokens float64) time.Duration {
+	seconds := tokens / l.rate
+	return time.Duration(math.Ceil(seconds*1000)) * time.Millisecond
+}
+
+func (b *bucket) refill(now time.Time, rate float64, burst int) {
+	elapsed := now.Sub(b.updatedAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+	b.updatedAt = now
+}
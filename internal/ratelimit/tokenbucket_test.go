@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucketLimiter_Allow(t *testing.T) {
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	sut := NewTokenBucketLimiter(2, 3)
+	sut.now = func() time.Time { return now }
+
+	for i := range 3 {
+		decision, err := sut.Allow(context.Background(), "client")
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed, "Request %d of burst should be allowed", i)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, 2-i, decision.Remaining)
+	}
+
+	decision, err := sut.Allow(context.Background(), "client")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "Request after burst should be rejected")
+	assert.Equal(t, 0, decision.Remaining)
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, decision.ResetAfter)
+
+	decision, err = sut.Allow(context.Background(), "other")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "Other keys should have their own buckets")
+
+	now = now.Add(500 * time.Millisecond)
+	decision, err = sut.Allow(context.Background(), "client")
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "Request should be allowed after refill")
+	assert.Zero(t, decision.RetryAfter)
+}
+
+func TestTokenBucketLimiter_AllowN(t *testing.T) {
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	sut := NewTokenBucketLimiter(2, 4)
+	sut.now = func() time.Time { return now }
+
+	decision, err := sut.AllowN(context.Background(), "client", 3)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 1, decision.Remaining)
+
+	decision, err = sut.AllowN(context.Background(), "client", 2)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "Request costing more than remaining tokens should be rejected")
+	assert.Equal(t, 500*time.Millisecond, decision.RetryAfter)
+
+	now = now.Add(1500 * time.Millisecond)
+	decision, err = sut.AllowN(context.Background(), "client", 10)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed, "Request costing more than burst should be allowed with full bucket")
+	assert.Equal(t, 0, decision.Remaining)
+	assert.Equal(t, 5*time.Second, decision.ResetAfter, "Whole cost should be charged")
+
+	decision, err = sut.Allow(context.Background(), "client")
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed, "Request should be rejected until debt is paid")
+	assert.Equal(t, 3500*time.Millisecond, decision.RetryAfter)
+}
+
+func TestTokenBucketLimiter_SweepsFullBuckets(t *testing.T) {
+	now := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	sut := NewTokenBucketLimiter(1, 2)
+	sut.now = func() time.Time { return now }
+
+	_, err := sut.Allow(context.Background(), "first")
+	require.NoError(t, err)
+	require.Len(t, sut.buckets, 1)
+
+	now = now.Add(2 * time.Second)
+	_, err = sut.Allow(context.Background(), "second")
+	require.NoError(t, err)
+
+	assert.NotContains(t, sut.buckets, "first", "Full bucket should be removed")
+	assert.Contains(t, sut.buckets, "second")
+}
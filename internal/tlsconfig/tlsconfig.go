@@ -0,0 +1,167 @@
+// Package tlsconfig provides TLS configuration of servers with certificates reloaded on change.
+//
+// Certificate and key files are checked for changes on TLS handshakes at most once per
+// check interval, so rotated certificates are used without restart of the program.
+// If new files cannot be loaded, for example while only one of them is replaced,
+// the previous certificate is used until they are loaded.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval is a default interval of checking certificate files for changes.
+const DefaultCheckInterval = 10 * time.Second
+
+// fileVersion identifies content of a file without reading it.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// CertificateReloader provides a certificate loaded from files, that is reloaded when they change.
+//
+// The zero value is not useful, you must use NewCertificateReloader to create an instance.
+type CertificateReloader struct {
+	certFile      string
+	keyFile       string
+	checkInterval time.Duration
+	now           func() time.Time
+
+	mu          sync.Mutex
+	certificate *tls.Certificate
+	versions    [2]fileVersion
+	checkedAt   time.Time
+}
+
+// ReloaderOption is used to set optional settings of CertificateReloader on its initialization.
+type ReloaderOption func(reloader *CertificateReloader)
+
+// WithCheckInterval returns an option that sets the interval of checking files for changes.
+// Files are checked on every handshake if it is zero, the default value is DefaultCheckInterval.
+func WithCheckInterval(interval time.Duration) ReloaderOption {
+	return func(reloader *CertificateReloader) {
+		reloader.checkInterval = interval
+	}
+}
+
+// NewCertificateReloader initializes a new CertificateReloader with paths to PEM files of
+// the certificate and its private key. It returns an error if they cannot be loaded.
+func NewCertificateReloader(certFile, keyFile string, options ...ReloaderOption) (*CertificateReloader, error) {
+	reloader := &CertificateReloader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		checkInterval: DefaultCheckInterval,
+		now:           time.Now,
+	}
+
+	for _, option := range options {
+		option(reloader)
+	}
+
+	versions, err := reloader.fileVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := reloader.load(versions); err != nil {
+		return nil, err
+	}
+
+	reloader.checkedAt = reloader.now()
+	return reloader, nil
+}
+
+// GetCertificate returns the current certificate, it is used as tls.Config.GetCertificate.
+// Files are reloaded if they are changed since the last check.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now := r.now(); now.Sub(r.checkedAt) >= r.checkInterval {
+		r.checkedAt = now
+		if err := r.reloadIfChanged(); err != nil {
+			slog.Error("Failed to reload TLS certificate, previous one is used", slog.String("error", err.Error()))
+		}
+	}
+
+	return r.certificate, nil
+}
+
+func (r *CertificateReloader) reloadIfChanged() error {
+	versions, err := r.fileVersions()
+	if err != nil {
+		return err
+	}
+
+	if versions == r.versions {
+		return nil
+	}
+
+	if err := r.load(versions); err != nil {
+		return err
+	}
+
+	slog.Info("TLS certificate reloaded", slog.String("cert_file", r.certFile))
+	return nil
+}
+
+// load loads the certificate and saves versions of its files, that are checked for changes later.
+func (r *CertificateReloader) load(versions [2]fileVersion) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.certificate = &certificate
+	r.versions = versions
+	return nil
+}
+
+func (r *CertificateReloader) fileVersions() ([2]fileVersion, error) {
+	var versions [2]fileVersion
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return versions, fmt.Errorf("failed to check TLS certificate file: %w", err)
+		}
+
+		versions[i] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return versions, nil
+}
+
+// Server returns TLS configuration of a server with the certificate of the reloader.
+// If clientCAFile is not empty, clients must present certificates signed by CA from that PEM file.
+func Server(reloader *CertificateReloader, clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	content, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, errors.New("client CA file contains no PEM certificates")
+	}
+
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
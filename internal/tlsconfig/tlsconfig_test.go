@@ -0,0 +1,229 @@
+package tlsconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCertificate is a certificate with its key in PEM format.
+type testCertificate struct {
+	cert, key []byte
+	parsed    *x509.Certificate
+	private   *ecdsa.PrivateKey
+}
+
+// newTestCertificate returns a certificate for localhost signed by the parent, it is self-signed if parent is nil.
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate) testCertificate {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	issuer, signer := template, private
+	if parent != nil {
+		issuer, signer = parent.parsed, parent.private
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &private.PublicKey, signer)
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(private)
+	require.NoError(t, err)
+
+	return testCertificate{
+		cert:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		key:     pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		parsed:  parsed,
+		private: private,
+	}
+}
+
+// writeCertificate writes files of the certificate with the modification time.
+func writeCertificate(t *testing.T, certificate testCertificate, certFile, keyFile string, modTime time.Time) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certFile, certificate.cert, 0o600))
+	require.NoError(t, os.WriteFile(keyFile, certificate.key, 0o600))
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+	require.NoError(t, os.Chtimes(keyFile, modTime, modTime))
+}
+
+func commonName(t *testing.T, certificate *tls.Certificate) string {
+	t.Helper()
+
+	parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+	require.NoError(t, err)
+	return parsed.Subject.CommonName
+}
+
+func TestCertificateReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	modTime := time.Now().Add(-time.Hour)
+	writeCertificate(t, newTestCertificate(t, "first", nil), certFile, keyFile, modTime)
+
+	sut, err := NewCertificateReloader(certFile, keyFile, WithCheckInterval(0))
+	require.NoError(t, err)
+
+	certificate, err := sut.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, certificate))
+
+	t.Run("keeps certificate if files are invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyFile, newTestCertificate(t, "other", nil).key, 0o600))
+
+		certificate, err := sut.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "first", commonName(t, certificate))
+	})
+
+	t.Run("reloads changed files", func(t *testing.T) {
+		writeCertificate(t, newTestCertificate(t, "second", nil), certFile, keyFile, modTime.Add(time.Minute))
+
+		certificate, err := sut.GetCertificate(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "second", commonName(t, certificate))
+	})
+}
+
+func TestCertificateReloader_CheckInterval(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	modTime := time.Now().Add(-time.Hour)
+	writeCertificate(t, newTestCertificate(t, "first", nil), certFile, keyFile, modTime)
+
+	sut, err := NewCertificateReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	now := time.Now()
+	sut.now = func() time.Time { return now }
+	sut.checkedAt = now
+	writeCertificate(t, newTestCertificate(t, "second", nil), certFile, keyFile, modTime.Add(time.Minute))
+
+	certificate, err := sut.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first", commonName(t, certificate), "Files must not be checked before the interval passes")
+
+	now = now.Add(DefaultCheckInterval)
+	certificate, err = sut.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second", commonName(t, certificate))
+}
+
+func TestNewCertificateReloader_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewCertificateReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"))
+
+	assert.ErrorContains(t, err, "failed to check TLS certificate file")
+}
+
+func TestServer_ClientCertificates(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCertificate(t, "ca", nil)
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, ca.cert, 0o600))
+
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(t, newTestCertificate(t, "server", &ca), certFile, keyFile, time.Now())
+
+	reloader, err := NewCertificateReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	sut, err := Server(reloader, caFile)
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.parsed)
+
+	client := newTestCertificate(t, "client", &ca)
+	clientCertificate, err := tls.X509KeyPair(client.cert, client.key)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		certificates []tls.Certificate
+		isAccepted   bool
+	}{
+		{name: "signed client certificate", certificates: []tls.Certificate{clientCertificate}, isAccepted: true},
+		{name: "missing client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConn, clientConn := net.Pipe()
+			defer serverConn.Close()
+			defer clientConn.Close()
+
+			serverErr := make(chan error, 1)
+			go func() {
+				serverErr <- tls.Server(serverConn, sut).Handshake()
+			}()
+
+			clientTLS := tls.Client(clientConn, &tls.Config{
+				RootCAs:      roots,
+				ServerName:   "localhost",
+				Certificates: tt.certificates,
+			})
+
+			// Client of TLS 1.3 completes its handshake before the server verifies its certificate.
+			_ = clientTLS.Handshake()
+			clientConn.Close()
+
+			err := <-serverErr
+			if tt.isAccepted {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestServer_InvalidClientCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(t, newTestCertificate(t, "server", nil), certFile, keyFile, time.Now())
+	caFile := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, []byte("not a certificate"), 0o600))
+
+	reloader, err := NewCertificateReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	_, err = Server(reloader, caFile)
+
+	assert.ErrorContains(t, err, "client CA file contains no PEM certificates")
+}
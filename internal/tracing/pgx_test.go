@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracer(t *testing.T) {
+	tests := []struct {
+		name           string
+		sql            string
+		err            error
+		expectedName   string
+		expectedStatus codes.Code
+	}{
+		{
+			name:           "query",
+			sql:            "  select url from urls where short_url = $1",
+			expectedName:   "SELECT",
+			expectedStatus: codes.Unset,
+		},
+		{
+			name:           "error",
+			sql:            "insert into urls values ($1)",
+			err:            errors.New("some error"),
+			expectedName:   "INSERT",
+			expectedStatus: codes.Error,
+		},
+		{
+			name:           "empty",
+			expectedName:   "QUERY",
+			expectedStatus: codes.Unset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+			sut := NewQueryTracer(provider)
+
+			parentCtx, parent := provider.Tracer("test").Start(context.Background(), "request")
+			ctx := sut.TraceQueryStart(parentCtx, nil, pgx.TraceQueryStartData{SQL: tt.sql})
+			sut.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: tt.err})
+			parent.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 2)
+
+			query := spans[0]
+			assert.Equal(t, tt.expectedName, query.Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+			assert.Contains(t, query.Attributes(), attribute.String("db.statement", tt.sql))
+			assert.Equal(t, tt.expectedStatus, query.Status().Code)
+		})
+	}
+}
+
+func TestQueryTracerBatch(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	sut := NewQueryTracer(provider)
+
+	batch := &pgx.Batch{}
+	batch.Queue("update urls set clicks = clicks + $1 where short_url = $2", 1, "1234567890")
+	batch.Queue("update urls set clicks = clicks + $1 where short_url = $2", 2, "0987654321")
+
+	ctx := sut.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{Batch: batch})
+	sut.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "update urls"})
+	sut.TraceBatchQuery(ctx, nil, pgx.TraceBatchQueryData{SQL: "update urls"})
+	sut.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "BATCH", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.Int("db.batch.size", 2))
+	assert.Len(t, spans[0].Events(), 2)
+}
@@ -0,0 +1,88 @@
+// Package tracing provides OpenTelemetry tracer providers with configurable exporters
+// and a tracer of PostgreSQL queries.
+//
+// Trace context is propagated in W3C format, see Propagator.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// ServiceName is a name of the service in exported spans. It can be overridden
+// with environment variable OTEL_SERVICE_NAME.
+const ServiceName = "shorturl"
+
+// Exporter is a name of destination of spans.
+type Exporter string
+
+const (
+	// ExporterNone disables tracing.
+	ExporterNone Exporter = "none"
+
+	// ExporterStdout writes spans to standard output as JSON.
+	ExporterStdout Exporter = "stdout"
+
+	// ExporterOTLP sends spans to OTLP collector over HTTP. The collector is configured
+	// with standard environment variables, like OTEL_EXPORTER_OTLP_ENDPOINT.
+	ExporterOTLP Exporter = "otlp"
+)
+
+// ErrUnknownExporter is returned when the exporter is not supported.
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// stdoutWriter is a destination of ExporterStdout, it is replaced in tests.
+var stdoutWriter io.Writer = os.Stdout
+
+// NewTracerProvider returns a tracer provider that exports spans in batches with the exporter.
+// For ExporterNone it returns nil provider and nil error. Provider must be shut down to export
+// remaining spans.
+func NewTracerProvider(ctx context.Context, exporter Exporter) (*sdktrace.TracerProvider, error) {
+	var (
+		spanExporter sdktrace.SpanExporter
+		err          error
+	)
+
+	switch exporter {
+	case ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(stdoutWriter))
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExporter, exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to init %s trace exporter: %w", exporter, err)
+	}
+
+	serviceResource, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init trace resource: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(serviceResource),
+	), nil
+}
+
+// Propagator returns a propagator of W3C trace context and baggage.
+func Propagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+}
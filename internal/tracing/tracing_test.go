@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	tests := []struct {
+		name          string
+		exporter      Exporter
+		wantProvider  bool
+		expectedError error
+	}{
+		{
+			name:     "none",
+			exporter: ExporterNone,
+		},
+		{
+			name:         "stdout",
+			exporter:     ExporterStdout,
+			wantProvider: true,
+		},
+		{
+			name:         "otlp",
+			exporter:     ExporterOTLP,
+			wantProvider: true,
+		},
+		{
+			name:          "unknown",
+			exporter:      "jaeger",
+			expectedError: ErrUnknownExporter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewTracerProvider(context.Background(), tt.exporter)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			if !tt.wantProvider {
+				assert.Nil(t, provider)
+				return
+			}
+
+			require.NotNil(t, provider)
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestStdoutExporter(t *testing.T) {
+	var output bytes.Buffer
+	previousWriter := stdoutWriter
+	stdoutWriter = &output
+	t.Cleanup(func() {
+		stdoutWriter = previousWriter
+	})
+
+	provider, err := NewTracerProvider(context.Background(), ExporterStdout)
+	require.NoError(t, err)
+
+	_, span := provider.Tracer("test").Start(context.Background(), "some span")
+	span.End()
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	assert.Contains(t, output.String(), `"Name":"some span"`)
+	assert.Contains(t, output.String(), ServiceName)
+}
@@ -0,0 +1,95 @@
+// Package analytics provides recording of clicks on short URLs.
+//
+// Clicks are recorded asynchronously and aggregated before saving, so recording
+// does not slow down resolving of short URLs.
+package analytics
+
+import (
+	"net/netip"
+	"net/url"
+	"time"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	// unknownValue is counted when a click property is not provided or cannot be parsed.
+	unknownValue = "unknown"
+	// directReferrer is counted when a click has no referrer.
+	directReferrer = "direct"
+	// maxUserAgentLength limits length of counted user agents, the rest is cut.
+	maxUserAgentLength = 128
+	// ipv4NetworkBits and ipv6NetworkBits are prefix lengths of counted client networks.
+	ipv4NetworkBits = 24
+	ipv6NetworkBits = 48
+)
+
+// Click is a single resolving of short URL with properties of its client.
+// ClientIP is an exact address, but only its network is counted.
+type Click struct {
+	ShortURL  string
+	Time      time.Time
+	Referrer  string
+	UserAgent string
+	ClientIP  string
+}
+
+// stats returns statistics of the single click to add it to aggregated ones.
+func (c Click) stats() urlstore.ClickStats {
+	stats := urlstore.NewClickStats(c.ShortURL)
+	stats.Clicks = 1
+	stats.LastClickAt = c.Time
+	stats.AddCount(urlstore.DimensionDay, c.Time.UTC().Format(time.DateOnly), 1)
+	stats.AddCount(urlstore.DimensionReferrer, referrerHost(c.Referrer), 1)
+	stats.AddCount(urlstore.DimensionUserAgent, shortUserAgent(c.UserAgent), 1)
+	stats.AddCount(urlstore.DimensionNetwork, CoarseNetwork(c.ClientIP), 1)
+
+	return stats
+}
+
+// CoarseNetwork returns the network of the IP address to count clients without
+// their exact addresses. IPv4 addresses are masked to /24 networks, IPv6 to /48.
+func CoarseNetwork(rawIP string) string {
+	ip, err := netip.ParseAddr(rawIP)
+	if err != nil {
+		return unknownValue
+	}
+
+	ip = ip.Unmap()
+	bits := ipv6NetworkBits
+	if ip.Is4() {
+		bits = ipv4NetworkBits
+	}
+
+	network, err := ip.Prefix(bits)
+	if err != nil {
+		return unknownValue
+	}
+
+	return network.String()
+}
+
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return directReferrer
+	}
+
+	parsedURL, err := url.Parse(referrer)
+	if err != nil || parsedURL.Hostname() == "" {
+		return unknownValue
+	}
+
+	return parsedURL.Hostname()
+}
+
+func shortUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return unknownValue
+	}
+
+	if len(userAgent) > maxUserAgentLength {
+		return userAgent[:maxUserAgentLength]
+	}
+
+	return userAgent
+}
@@ -0,0 +1,59 @@
+package analytics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestCoarseNetwork(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{ip: "192.0.2.10", expected: "192.0.2.0/24"},
+		{ip: "::ffff:192.0.2.10", expected: "192.0.2.0/24"},
+		{ip: "2001:db8:1234:5678::1", expected: "2001:db8:1234::/48"},
+		{ip: "", expected: unknownValue},
+		{ip: "not ip", expected: unknownValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CoarseNetwork(tt.ip))
+		})
+	}
+}
+
+func TestClick_stats(t *testing.T) {
+	clickTime := time.Date(2024, 3, 2, 23, 30, 0, 0, time.FixedZone("UTC-1", -60*60))
+	click := Click{
+		ShortURL:  "short",
+		Time:      clickTime,
+		Referrer:  "https://news.example.org/post?id=1",
+		UserAgent: strings.Repeat("a", maxUserAgentLength+1),
+		ClientIP:  "192.0.2.10",
+	}
+
+	stats := click.stats()
+
+	assert.Equal(t, "short", stats.ShortURL)
+	assert.Equal(t, int64(1), stats.Clicks)
+	assert.Equal(t, clickTime, stats.LastClickAt)
+	assert.Equal(t, map[string]int64{"2024-03-03": 1}, stats.Counts[urlstore.DimensionDay])
+	assert.Equal(t, map[string]int64{"news.example.org": 1}, stats.Counts[urlstore.DimensionReferrer])
+	assert.Equal(t, map[string]int64{strings.Repeat("a", maxUserAgentLength): 1}, stats.Counts[urlstore.DimensionUserAgent])
+	assert.Equal(t, map[string]int64{"192.0.2.0/24": 1}, stats.Counts[urlstore.DimensionNetwork])
+}
+
+func TestClick_stats_WithoutClientProperties(t *testing.T) {
+	stats := Click{ShortURL: "short", Time: time.Now()}.stats()
+
+	assert.Equal(t, map[string]int64{directReferrer: 1}, stats.Counts[urlstore.DimensionReferrer])
+	assert.Equal(t, map[string]int64{unknownValue: 1}, stats.Counts[urlstore.DimensionUserAgent])
+	assert.Equal(t, map[string]int64{unknownValue: 1}, stats.Counts[urlstore.DimensionNetwork])
+}
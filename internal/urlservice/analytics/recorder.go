@@ -0,0 +1,124 @@
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// finalFlushTimeout limits saving of clicks remaining on recorder stop.
+const finalFlushTimeout = 5 * time.Second
+
+// StatsStorage is a storage that saves aggregated clicks.
+type StatsStorage interface {
+	AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error
+}
+
+// Recorder collects clicks and saves their aggregates to storage in batches.
+//
+// Clicks are buffered in channel, if the buffer is full, new clicks are dropped to
+// not block the caller. Aggregates are saved every flush interval or when clicks of
+// too many short URLs are collected.
+//
+// It must be initialized with NewRecorder and started with Run.
+type Recorder struct {
+	clicks        chan Click
+	storage       StatsStorage
+	flushInterval time.Duration
+	maxBatchSize  int
+}
+
+// NewRecorder initializes Recorder saving clicks to the storage. Buffer size is a count of clicks
+// waiting to be aggregated, flush interval is the maximum time between saves of aggregates.
+// It returns a pointer to created object.
+func NewRecorder(storage StatsStorage, bufferSize int, flushInterval time.Duration) *Recorder {
+	return &Recorder{
+		clicks:        make(chan Click, bufferSize),
+		storage:       storage,
+		flushInterval: flushInterval,
+		maxBatchSize:  bufferSize,
+	}
+}
+
+// Record passes the click to be aggregated without waiting. It returns false if the
+// click was dropped because the buffer is full.
+func (r *Recorder) Record(click Click) bool {
+	select {
+	case r.clicks <- click:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run aggregates recorded clicks and saves them until the context is done. Then
+// it saves clicks remaining in the buffer and returns. Saving errors are logged.
+func (r *Recorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.flushInterval)
+	defer ticker.Stop()
+
+	batch := make(map[string]urlstore.ClickStats)
+	for {
+		select {
+		case <-ctx.Done():
+			r.drain(batch)
+			r.finalFlush(batch)
+			return
+		case <-ticker.C:
+			batch = r.flush(ctx, batch)
+		case click := <-r.clicks:
+			addClick(batch, click)
+			if len(batch) >= r.maxBatchSize {
+				batch = r.flush(ctx, batch)
+			}
+		}
+	}
+}
+
+func (r *Recorder) drain(batch map[string]urlstore.ClickStats) {
+	for {
+		select {
+		case click := <-r.clicks:
+			addClick(batch, click)
+		default:
+			return
+		}
+	}
+}
+
+func (r *Recorder) finalFlush(batch map[string]urlstore.ClickStats) {
+	ctx, cancel := context.WithTimeout(context.Background(), finalFlushTimeout)
+	defer cancel()
+
+	r.flush(ctx, batch)
+}
+
+// flush saves the batch to storage and returns a new empty batch.
+func (r *Recorder) flush(ctx context.Context, batch map[string]urlstore.ClickStats) map[string]urlstore.ClickStats {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	stats := make([]urlstore.ClickStats, 0, len(batch))
+	for _, shortURLStats := range batch {
+		stats = append(stats, shortURLStats)
+	}
+
+	if err := r.storage.AddClickStats(ctx, stats); err != nil {
+		slog.Error("Failed to save click stats", slog.String("error", err.Error()), slog.Int("short_urls_count", len(stats)))
+	}
+
+	return make(map[string]urlstore.ClickStats)
+}
+
+func addClick(batch map[string]urlstore.ClickStats, click Click) {
+	shortURLStats, isFound := batch[click.ShortURL]
+	if !isFound {
+		shortURLStats = urlstore.NewClickStats(click.ShortURL)
+	}
+
+	shortURLStats.Merge(click.stats())
+	batch[click.ShortURL] = shortURLStats
+}
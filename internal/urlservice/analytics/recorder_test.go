@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+type statsStorageStub struct {
+	mutex sync.Mutex
+	saved map[string]urlstore.ClickStats
+}
+
+func (s *statsStorageStub) AddClickStats(_ context.Context, stats []urlstore.ClickStats) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, toAdd := range stats {
+		saved, isFound := s.saved[toAdd.ShortURL]
+		if !isFound {
+			saved = urlstore.NewClickStats(toAdd.ShortURL)
+		}
+
+		saved.Merge(toAdd)
+		s.saved[toAdd.ShortURL] = saved
+	}
+
+	return nil
+}
+
+func (s *statsStorageStub) clicks(shortURL string) int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.saved[shortURL].Clicks
+}
+
+func newStatsStorageStub() *statsStorageStub {
+	return &statsStorageStub{saved: make(map[string]urlstore.ClickStats)}
+}
+
+func TestRecorder_Run_FlushesOnStop(t *testing.T) {
+	storage := newStatsStorageStub()
+	sut := NewRecorder(storage, 10, time.Hour)
+
+	for range 3 {
+		require.True(t, sut.Record(Click{ShortURL: "first", Time: time.Now()}))
+	}
+	require.True(t, sut.Record(Click{ShortURL: "second", Time: time.Now()}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sut.Run(ctx)
+
+	assert.Equal(t, int64(3), storage.clicks("first"))
+	assert.Equal(t, int64(1), storage.clicks("second"))
+}
+
+func TestRecorder_Run_FlushesEveryInterval(t *testing.T) {
+	storage := newStatsStorageStub()
+	sut := NewRecorder(storage, 10, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sut.Run(ctx)
+		close(done)
+	}()
+
+	sut.Record(Click{ShortURL: "short", Time: time.Now()})
+	assert.Eventually(t, func() bool {
+		return storage.clicks("short") == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestRecorder_Record_DropsClickWhenBufferIsFull(t *testing.T) {
+	sut := NewRecorder(newStatsStorageStub(), 1, time.Hour)
+
+	assert.True(t, sut.Record(Click{ShortURL: "short"}))
+	assert.False(t, sut.Record(Click{ShortURL: "short"}))
+}
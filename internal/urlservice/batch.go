@@ -0,0 +1,95 @@
+package urlservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// MaxBatchSize is the maximum count of URLs in a single batch request.
+const MaxBatchSize = 1000
+
+// ErrBatchTooLarge is returned when batch contains more than MaxBatchSize URLs.
+var ErrBatchTooLarge = fmt.Errorf("batch contains more than %d urls", MaxBatchSize)
+
+// ResolveResult is a result of resolving short URL in batch. Err is not nil if the
+// short URL cannot be resolved, it is ErrURLNotFound, ErrURLExpired or ErrURLDisabled.
+type ResolveResult struct {
+	ShortURL    string
+	OriginalURL string
+	Err         error
+}
+
+// BatchShortURLs returns the owner's short URLs for all original URLs in the same order. Like ShortURL
+// without options except owner, it returns the same short URL to the same owner for the same original URL. It calls method
+// ShortURLs in his storage once for the whole batch, so any storage error fails the whole batch.
+//
+// It returns ErrBatchTooLarge if the batch contains more than MaxBatchSize URLs.
+func (s ShortURLService) BatchShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	if len(originalURLs) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	if len(originalURLs) == 0 {
+		return []string{}, nil
+	}
+
+	shortURLs, err := s.storage.ShortURLs(ctx, owner, originalURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert or get batch of %d short urls: %w", len(originalURLs), err)
+	}
+
+	s.addLinksCreated(len(shortURLs))
+	return shortURLs, nil
+}
+
+// BatchOriginalURLs resolves all short URLs and returns results in the same order. It calls
+// method Links in his storage once for the whole batch, errors of single short URLs are set in
+// their results. Clicks are not recorded for short URLs resolved in batch.
+//
+// It returns ErrBatchTooLarge if the batch contains more than MaxBatchSize URLs.
+func (s ShortURLService) BatchOriginalURLs(ctx context.Context, shortURLs []string) ([]ResolveResult, error) {
+	if len(shortURLs) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	if len(shortURLs) == 0 {
+		return []ResolveResult{}, nil
+	}
+
+	links, err := s.storage.Links(ctx, shortURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch of %d short urls: %w", len(shortURLs), err)
+	}
+
+	linksByShortURLs := make(map[string]urlstore.Link, len(links))
+	for _, link := range links {
+		linksByShortURLs[link.ShortURL] = link
+	}
+
+	now := time.Now()
+	var resolvedCount int
+	results := make([]ResolveResult, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		result := ResolveResult{ShortURL: shortURL}
+		link, isFound := linksByShortURLs[shortURL]
+		switch {
+		case !isFound:
+			result.Err = fmt.Errorf("%w: %q", ErrURLNotFound, shortURL)
+		case link.Disabled:
+			result.Err = fmt.Errorf("%w: %q", ErrURLDisabled, shortURL)
+		case link.IsExpired(now):
+			result.Err = fmt.Errorf("%w: %q", ErrURLExpired, shortURL)
+		default:
+			result.OriginalURL = link.OriginalURL
+			resolvedCount++
+		}
+
+		results = append(results, result)
+	}
+
+	s.addLinksResolved(resolvedCount)
+	return results, nil
+}
@@ -0,0 +1,74 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestShortURLService_BatchShortURLs(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		ShortURLs(mock.Anything, "owner", []string{"first", "second"}).
+		Return([]string{"1", "2"}, nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	shortURLs, err := sut.BatchShortURLs(context.Background(), "owner", []string{"first", "second"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, shortURLs)
+
+	shortURLs, err = sut.BatchShortURLs(context.Background(), "owner", nil)
+	require.NoError(t, err)
+	assert.Empty(t, shortURLs)
+
+	_, err = sut.BatchShortURLs(context.Background(), "owner", make([]string, MaxBatchSize+1))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
+
+func TestShortURLService_BatchShortURLs_StorageError(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		ShortURLs(mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("some error")).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	_, err := sut.BatchShortURLs(context.Background(), "owner", []string{"first"})
+	assert.Error(t, err)
+}
+
+func TestShortURLService_BatchOriginalURLs(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Links(mock.Anything, mock.Anything).
+		Return([]urlstore.Link{
+			{ShortURL: "active", OriginalURL: "original"},
+			{ShortURL: "disabled", OriginalURL: "original", Disabled: true},
+			{ShortURL: "expired", OriginalURL: "original", ExpiresAt: time.Now().Add(-time.Hour)},
+		}, nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	results, err := sut.BatchOriginalURLs(context.Background(), []string{"expired", "missing", "active", "disabled"})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.Equal(t, "expired", results[0].ShortURL)
+	assert.ErrorIs(t, results[0].Err, ErrURLExpired)
+	assert.ErrorIs(t, results[1].Err, ErrURLNotFound)
+	assert.NoError(t, results[2].Err)
+	assert.Equal(t, "original", results[2].OriginalURL)
+	assert.ErrorIs(t, results[3].Err, ErrURLDisabled)
+	assert.Empty(t, results[3].OriginalURL)
+
+	_, err = sut.BatchOriginalURLs(context.Background(), make([]string, MaxBatchSize+1))
+	assert.ErrorIs(t, err, ErrBatchTooLarge)
+}
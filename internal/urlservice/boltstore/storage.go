@@ -0,0 +1,773 @@
+// Package boltstore provides a URL storage in an embedded bbolt database file.
+//
+// It allows for the storing and retrieval of original URLs using
+// encoded keys. It's safe for concurrent use, but the file can be opened
+// by a single process only.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	// maxEncodingAttempts limits count of ids tried to encode a short URL that is not saved yet.
+	maxEncodingAttempts = 100
+	// linksPageSize is a count of links read in a single transaction while iterating over all links.
+	linksPageSize = 1000
+	// openTimeout limits waiting for a lock of the file opened by another process.
+	openTimeout = time.Second
+	// keySeparator separates parts of composite keys, it never appears in URLs.
+	keySeparator = 0
+)
+
+var (
+	linksBucket               = []byte("links")
+	encodedByOriginalBucket   = []byte("encoded_by_original")
+	ownerLinksBucket          = []byte("owner_links")
+	expirationByEncodedBucket = []byte("expiration_by_encoded")
+	changesBucket             = []byte("changes")
+	statsBucket               = []byte("stats")
+
+	buckets = [][]byte{
+		linksBucket,
+		encodedByOriginalBucket,
+		ownerLinksBucket,
+		expirationByEncodedBucket,
+		changesBucket,
+		statsBucket,
+	}
+)
+
+// Open opens the database file at the path, creating it if needed, and creates buckets of the storage.
+// It returns an error if the file is locked by another process for longer than a second.
+func Open(path string) (*bbolt.DB, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database %q: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to create buckets in bolt database %q: %w", path, err), db.Close())
+	}
+
+	return db, nil
+}
+
+// BoltStorage is a URL storage using an embedded bbolt database.
+//
+// Like memstore, it maps both original URLs by short URLs and short URLs by owned original URLs
+// in two buckets. Links are saved in the bucket of short URLs with their owners, expiration
+// times and statuses. Keys of owned links and of expiration times are kept in buckets ordered
+// by owners and by times. Changes of original URLs and click statistics are mapped by short URLs
+// of changed and clicked links only. IDs to encode short URLs are taken from the sequence of
+// the bucket of links.
+//
+// bbolt allows a single writing transaction at a time, so new short URLs are saved after
+// checking again that another goroutine has not saved the original URL already.
+//
+// The zero value is not useful, you must use NewBoltStorage to create an instance.
+type BoltStorage struct {
+	db             *bbolt.DB
+	idEncoder      encoder.IDEncoder
+	shortURLLength uint
+}
+
+// NewBoltStorage initializes a new BoltStorage instance with the database opened by Open,
+// the given ID encoder, and the specified length for short URLs. It returns a pointer to created object.
+func NewBoltStorage(db *bbolt.DB, idEncoder encoder.IDEncoder, shortURLLength uint) *BoltStorage {
+	return &BoltStorage{
+		db:             db,
+		idEncoder:      idEncoder,
+		shortURLLength: shortURLLength,
+	}
+}
+
+// linkRecord is a link saved as JSON by its short URL. Zero ExpiresAt means that the link never expires.
+type linkRecord struct {
+	OriginalURL string `json:"original_url"`
+	Owner       string `json:"owner,omitempty"`
+	ExpiresAt   int64  `json:"expires_at,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Link is looking for the link by passed short URL.
+// If the short URL does not exist in the storage, it returns an error wrapping urlstore.ErrNotFound.
+func (s *BoltStorage) Link(_ context.Context, shortURL string) (urlstore.Link, error) {
+	var (
+		link    urlstore.Link
+		isFound bool
+	)
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		link, isFound, err = lookForLink(tx, shortURL)
+		return err
+	})
+	if err != nil {
+		return urlstore.Link{}, err
+	}
+
+	if !isFound {
+		return urlstore.Link{}, notFoundError(shortURL)
+	}
+
+	return link, nil
+}
+
+// ShortURL should always return the owner's short URL for provided original URL value.
+//
+// At first, it tries to find saved value, but if it does not exist, it encodes the
+// original URL by incremented ID and returns a new value.
+//
+// saveURL is tying to get saved value again in the writing transaction before saving a new one
+// due to a case where another goroutine has already performed this operation before.
+//
+// It might return an error if all encoded short URLs already exist in storage
+// or if encoded value has an incorrect length.
+func (s *BoltStorage) ShortURL(_ context.Context, owner, originalURL string) (string, error) {
+	var shortURL []byte
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		shortURL = bytes.Clone(tx.Bucket(encodedByOriginalBucket).Get(ownedURLKey(owner, originalURL)))
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get short url of %q url from bolt: %w", originalURL, err)
+	}
+
+	if shortURL != nil {
+		return string(shortURL), nil
+	}
+
+	shortURLs, err := s.saveURLs(owner, []string{originalURL})
+	if err != nil {
+		return "", err
+	}
+
+	return shortURLs[0], nil
+}
+
+// ShortURLs returns the owner's short URLs for all provided original URLs in the same order
+// in a single transaction. Like ShortURL, it returns saved values or encodes new ones.
+// If encoding of any short URL fails, it returns an error and no short URLs are saved.
+func (s *BoltStorage) ShortURLs(_ context.Context, owner string, originalURLs []string) ([]string, error) {
+	return s.saveURLs(owner, originalURLs)
+}
+
+// Links is looking for links by passed short URLs in a single transaction.
+// Short URLs that do not exist in the storage are skipped.
+func (s *BoltStorage) Links(_ context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	links := make([]urlstore.Link, 0, len(shortURLs))
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		for _, shortURL := range shortURLs {
+			link, isFound, err := lookForLink(tx, shortURL)
+			if err != nil {
+				return err
+			}
+
+			if isFound {
+				links = append(links, link)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// AddLink saves the link that is never returned by ShortURL and returns its short URL.
+// If the link has no short URL, it encodes a new one by incremented ID.
+// The link is saved disabled at once if it is disabled.
+//
+// If the short URL is mapped with an expired link, the link is replaced and its click statistics are removed.
+// Otherwise, if it is already mapped with the same original URL of the same owner, it does nothing, the saved
+// link is not changed, and if it is mapped with another original URL or owned by another owner, it returns
+// an error wrapping urlstore.ErrShortURLTaken.
+func (s *BoltStorage) AddLink(_ context.Context, link urlstore.Link) (string, error) {
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		if link.ShortURL == "" {
+			var err error
+			link.ShortURL, err = s.encodeNewShortURL(tx)
+			if err != nil {
+				return err
+			}
+
+			return setLink(tx, link)
+		}
+
+		savedLink, isSaved, err := lookForLink(tx, link.ShortURL)
+		if err != nil {
+			return err
+		}
+
+		if isSaved && !savedLink.IsExpired(time.Now()) {
+			if savedLink.OriginalURL == link.OriginalURL && savedLink.Owner == link.Owner {
+				return nil
+			}
+
+			return fmt.Errorf("%w: %q in bolt", urlstore.ErrShortURLTaken, link.ShortURL)
+		}
+
+		if err := deleteLink(tx, link.ShortURL); err != nil {
+			return err
+		}
+
+		return setLink(tx, link)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return link.ShortURL, nil
+}
+
+// ForEachLink calls fn for every link saved in the storage in order of short URLs. Links are
+// read by pages in separate transactions, so fn may use the storage itself, but links changed
+// during iteration may be missed or returned in their new state.
+// It stops on the first error returned by fn and returns it.
+func (s *BoltStorage) ForEachLink(_ context.Context, fn func(link urlstore.Link) error) error {
+	var lastShortURL string
+	for {
+		page, err := s.linksPage(linksBucket, nil, lastShortURL, linksPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range page {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < linksPageSize {
+			return nil
+		}
+
+		lastShortURL = page[len(page)-1].ShortURL
+	}
+}
+
+// OwnerLinks returns up to limit links of the owner with short URLs greater than
+// the passed one in order of short URLs. Pass empty short URL to get the first page.
+func (s *BoltStorage) OwnerLinks(_ context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	if owner == "" {
+		return []urlstore.Link{}, nil
+	}
+
+	return s.linksPage(ownerLinksBucket, compositeKey([]byte(owner), ""), afterShortURL, limit)
+}
+
+// DeleteExpired removes all links that have expired at the moment and returns their count.
+func (s *BoltStorage) DeleteExpired(_ context.Context, moment time.Time) (int64, error) {
+	var count int64
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		var expired []string
+		cursor := tx.Bucket(expirationByEncodedBucket).Cursor()
+		maxTime := expirationKey(moment.UnixMicro(), "")
+		for key, _ := cursor.First(); key != nil && bytes.Compare(key[:len(maxTime)], maxTime) <= 0; key, _ = cursor.Next() {
+			expired = append(expired, string(key[len(maxTime):]))
+		}
+
+		for _, shortURL := range expired {
+			if err := deleteLink(tx, shortURL); err != nil {
+				return err
+			}
+		}
+
+		count = int64(len(expired))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired urls from bolt: %w", err)
+	}
+
+	return count, nil
+}
+
+// Delete removes the link with its click statistics. If the link is returned by ShortURL
+// for its original URL, the next call of ShortURL encodes a new short URL.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *BoltStorage) Delete(_ context.Context, owner, shortURL string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		if _, err := lookForOwnedLink(tx, owner, shortURL); err != nil {
+			return err
+		}
+
+		return deleteLink(tx, shortURL)
+	})
+}
+
+// SetDisabled disables or enables the link. Disabled link is still returned by ShortURL
+// for its original URL, so it is not replaced with a new one while it is disabled.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *BoltStorage) SetDisabled(_ context.Context, owner, shortURL string, disabled bool) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		link, err := lookForOwnedLink(tx, owner, shortURL)
+		if err != nil {
+			return err
+		}
+
+		link.Disabled = disabled
+		return putLink(tx, link)
+	})
+}
+
+// destinationChange is a change of original URL saved as JSON.
+type destinationChange struct {
+	PreviousURL string `json:"previous_url"`
+	NewURL      string `json:"new_url"`
+	ChangedAt   int64  `json:"changed_at"`
+}
+
+// UpdateOriginalURL maps the short URL with another original URL and records the change.
+// Updated link is not returned by ShortURL for any original URL anymore. Its expiration,
+// status and click statistics are kept. If the original URL is the same, it does nothing.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *BoltStorage) UpdateOriginalURL(_ context.Context, owner, shortURL, originalURL string) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		link, err := lookForOwnedLink(tx, owner, shortURL)
+		if err != nil {
+			return err
+		}
+
+		if link.OriginalURL == originalURL {
+			return nil
+		}
+
+		if err := deleteOwnedURL(tx, link); err != nil {
+			return err
+		}
+
+		changes, err := lookForChanges(tx, shortURL)
+		if err != nil {
+			return err
+		}
+
+		changes = append(changes, destinationChange{
+			PreviousURL: link.OriginalURL,
+			NewURL:      originalURL,
+			ChangedAt:   time.Now().UnixMicro(),
+		})
+		if err := putJSON(tx.Bucket(changesBucket), []byte(shortURL), changes); err != nil {
+			return err
+		}
+
+		link.OriginalURL = originalURL
+		return putLink(tx, link)
+	})
+}
+
+// History returns changes of original URL mapped with the short URL, the oldest first.
+func (s *BoltStorage) History(_ context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	var changes []destinationChange
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		changes, err = lookForChanges(tx, shortURL)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of %q url from bolt: %w", shortURL, err)
+	}
+
+	result := make([]urlstore.DestinationChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, urlstore.DestinationChange{
+			ShortURL:    shortURL,
+			PreviousURL: change.PreviousURL,
+			NewURL:      change.NewURL,
+			ChangedAt:   time.UnixMicro(change.ChangedAt),
+		})
+	}
+
+	return result, nil
+}
+
+// clickStats are click statistics saved as JSON.
+type clickStats struct {
+	Clicks      int64                       `json:"clicks"`
+	LastClickAt int64                       `json:"last_click_at"`
+	Counts      map[string]map[string]int64 `json:"counts,omitempty"`
+}
+
+// AddClickStats adds clicks to statistics of their short URLs in a single transaction.
+// Clicks of short URLs that do not exist in the storage are ignored.
+func (s *BoltStorage) AddClickStats(_ context.Context, stats []urlstore.ClickStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		for _, toAdd := range stats {
+			if tx.Bucket(linksBucket).Get([]byte(toAdd.ShortURL)) == nil {
+				continue
+			}
+
+			saved, err := lookForClickStats(tx, toAdd.ShortURL)
+			if err != nil {
+				return err
+			}
+
+			saved.Merge(toAdd)
+			if err := putClickStats(tx, saved); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save click stats in bolt: %w", err)
+	}
+
+	return nil
+}
+
+// ClickStats returns click statistics of the short URL.
+// If the short URL has never been clicked, it returns empty statistics.
+func (s *BoltStorage) ClickStats(_ context.Context, shortURL string) (urlstore.ClickStats, error) {
+	var stats urlstore.ClickStats
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		stats, err = lookForClickStats(tx, shortURL)
+		return err
+	})
+	if err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to get stats of %q url from bolt: %w", shortURL, err)
+	}
+
+	return stats, nil
+}
+
+// Ping checks that the database is open.
+func (s *BoltStorage) Ping(_ context.Context) error {
+	return s.db.View(func(*bbolt.Tx) error {
+		return nil
+	})
+}
+
+// saveURLs returns the owner's saved short URLs for the original URLs or encodes and saves new ones
+// in a single writing transaction.
+func (s *BoltStorage) saveURLs(owner string, originalURLs []string) ([]string, error) {
+	shortURLs := make([]string, 0, len(originalURLs))
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		for _, originalURL := range originalURLs {
+			shortURL, err := s.saveURL(tx, owner, originalURL)
+			if err != nil {
+				return err
+			}
+
+			shortURLs = append(shortURLs, shortURL)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shortURLs, nil
+}
+
+// saveURL returns the owner's saved short URL for the original URL or encodes and saves a new one.
+func (s *BoltStorage) saveURL(tx *bbolt.Tx, owner, originalURL string) (string, error) {
+	key := ownedURLKey(owner, originalURL)
+	encodedByOriginal := tx.Bucket(encodedByOriginalBucket)
+	if shortURL := encodedByOriginal.Get(key); shortURL != nil {
+		return string(shortURL), nil
+	}
+
+	newShortURL, err := s.encodeNewShortURL(tx)
+	if err != nil {
+		return "", err
+	}
+
+	if err := setLink(tx, urlstore.Link{ShortURL: newShortURL, OriginalURL: originalURL, Owner: owner}); err != nil {
+		return "", err
+	}
+
+	return newShortURL, encodedByOriginal.Put(key, []byte(newShortURL))
+}
+
+// encodeNewShortURL increments ID and encodes it. Short URLs that are already saved,
+// for example imported ones, are skipped.
+//
+// It returns an error if encoded value has an incorrect length or if all attempts
+// returned short URLs that are already saved.
+func (s *BoltStorage) encodeNewShortURL(tx *bbolt.Tx) (string, error) {
+	links := tx.Bucket(linksBucket)
+	for range maxEncodingAttempts {
+		id, err := links.NextSequence()
+		if err != nil {
+			return "", fmt.Errorf("failed to get next id from bolt: %w", err)
+		}
+
+		newShortURL := s.idEncoder.EncodeID(uint(id), s.shortURLLength)
+		if len(newShortURL) != int(s.shortURLLength) {
+			return "", fmt.Errorf("unexpected length of encoded url, expected=%d, actual=%d", s.shortURLLength, len(newShortURL))
+		}
+
+		if links.Get([]byte(newShortURL)) == nil {
+			return newShortURL, nil
+		}
+	}
+
+	return "", errors.New("encoded url is not unique")
+}
+
+// linksPage returns up to limit links from the bucket with keys starting with the prefix followed
+// by short URLs greater than the last one.
+func (s *BoltStorage) linksPage(bucket, prefix []byte, lastShortURL string, limit int) ([]urlstore.Link, error) {
+	links := make([]urlstore.Link, 0, min(limit, linksPageSize))
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		cursor := tx.Bucket(bucket).Cursor()
+		start := append(bytes.Clone(prefix), lastShortURL...)
+		for key, _ := cursor.Seek(start); key != nil && bytes.HasPrefix(key, prefix) && len(links) < limit; key, _ = cursor.Next() {
+			shortURL := string(key[len(prefix):])
+			if shortURL == lastShortURL {
+				continue
+			}
+
+			link, isFound, err := lookForLink(tx, shortURL)
+			if err != nil {
+				return err
+			}
+
+			if isFound {
+				links = append(links, link)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links after %q from bolt: %w", lastShortURL, err)
+	}
+
+	return links, nil
+}
+
+func lookForLink(tx *bbolt.Tx, shortURL string) (urlstore.Link, bool, error) {
+	raw := tx.Bucket(linksBucket).Get([]byte(shortURL))
+	if raw == nil {
+		return urlstore.Link{}, false, nil
+	}
+
+	var record linkRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return urlstore.Link{}, false, fmt.Errorf("failed to read %q url from bolt: %w", shortURL, err)
+	}
+
+	link := urlstore.Link{
+		ShortURL:    shortURL,
+		OriginalURL: record.OriginalURL,
+		Owner:       record.Owner,
+		Disabled:    record.Disabled,
+	}
+	if record.ExpiresAt != 0 {
+		link.ExpiresAt = time.UnixMicro(record.ExpiresAt)
+	}
+
+	return link, true, nil
+}
+
+// lookForOwnedLink returns the link that may be changed by the owner. It returns an error wrapping
+// urlstore.ErrNotFound if the link is not found or is owned by another owner.
+func lookForOwnedLink(tx *bbolt.Tx, owner, shortURL string) (urlstore.Link, error) {
+	link, isFound, err := lookForLink(tx, shortURL)
+	if err != nil {
+		return urlstore.Link{}, err
+	}
+
+	if !isFound || !link.IsOwnedBy(owner) {
+		return urlstore.Link{}, notFoundError(shortURL)
+	}
+
+	return link, nil
+}
+
+// setLink saves a new link with its indexes of owner and expiration time.
+func setLink(tx *bbolt.Tx, link urlstore.Link) error {
+	if link.Owner != "" {
+		if err := tx.Bucket(ownerLinksBucket).Put(compositeKey([]byte(link.Owner), link.ShortURL), nil); err != nil {
+			return err
+		}
+	}
+
+	if !link.ExpiresAt.IsZero() {
+		if err := tx.Bucket(expirationByEncodedBucket).Put(expirationKey(link.ExpiresAt.UnixMicro(), link.ShortURL), nil); err != nil {
+			return err
+		}
+	}
+
+	return putLink(tx, link)
+}
+
+// putLink saves the link without updating its indexes.
+func putLink(tx *bbolt.Tx, link urlstore.Link) error {
+	record := linkRecord{
+		OriginalURL: link.OriginalURL,
+		Owner:       link.Owner,
+		Disabled:    link.Disabled,
+	}
+	if !link.ExpiresAt.IsZero() {
+		record.ExpiresAt = link.ExpiresAt.UnixMicro()
+	}
+
+	return putJSON(tx.Bucket(linksBucket), []byte(link.ShortURL), record)
+}
+
+// deleteLink removes the link and everything mapped by its short URL.
+func deleteLink(tx *bbolt.Tx, shortURL string) error {
+	link, isFound, err := lookForLink(tx, shortURL)
+	if err != nil || !isFound {
+		return err
+	}
+
+	if err := deleteOwnedURL(tx, link); err != nil {
+		return err
+	}
+
+	if link.Owner != "" {
+		if err := tx.Bucket(ownerLinksBucket).Delete(compositeKey([]byte(link.Owner), shortURL)); err != nil {
+			return err
+		}
+	}
+
+	if !link.ExpiresAt.IsZero() {
+		if err := tx.Bucket(expirationByEncodedBucket).Delete(expirationKey(link.ExpiresAt.UnixMicro(), shortURL)); err != nil {
+			return err
+		}
+	}
+
+	for _, bucket := range [][]byte{linksBucket, changesBucket, statsBucket} {
+		if err := tx.Bucket(bucket).Delete([]byte(shortURL)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteOwnedURL removes mapping of the link by its owned original URL if ShortURL returns the link.
+func deleteOwnedURL(tx *bbolt.Tx, link urlstore.Link) error {
+	key := ownedURLKey(link.Owner, link.OriginalURL)
+	encodedByOriginal := tx.Bucket(encodedByOriginalBucket)
+	if string(encodedByOriginal.Get(key)) != link.ShortURL {
+		return nil
+	}
+
+	return encodedByOriginal.Delete(key)
+}
+
+func lookForChanges(tx *bbolt.Tx, shortURL string) ([]destinationChange, error) {
+	raw := tx.Bucket(changesBucket).Get([]byte(shortURL))
+	if raw == nil {
+		return nil, nil
+	}
+
+	var changes []destinationChange
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return nil, fmt.Errorf("failed to read history of %q url from bolt: %w", shortURL, err)
+	}
+
+	return changes, nil
+}
+
+func lookForClickStats(tx *bbolt.Tx, shortURL string) (urlstore.ClickStats, error) {
+	result := urlstore.NewClickStats(shortURL)
+	raw := tx.Bucket(statsBucket).Get([]byte(shortURL))
+	if raw == nil {
+		return result, nil
+	}
+
+	var saved clickStats
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to read stats of %q url from bolt: %w", shortURL, err)
+	}
+
+	result.Clicks = saved.Clicks
+	if saved.LastClickAt != 0 {
+		result.LastClickAt = time.UnixMicro(saved.LastClickAt)
+	}
+
+	for dimension, counts := range saved.Counts {
+		for value, clicks := range counts {
+			result.AddCount(urlstore.ClickDimension(dimension), value, clicks)
+		}
+	}
+
+	return result, nil
+}
+
+func putClickStats(tx *bbolt.Tx, stats urlstore.ClickStats) error {
+	toSave := clickStats{
+		Clicks: stats.Clicks,
+		Counts: make(map[string]map[string]int64, len(stats.Counts)),
+	}
+	if !stats.LastClickAt.IsZero() {
+		toSave.LastClickAt = stats.LastClickAt.UnixMicro()
+	}
+
+	for dimension, counts := range stats.Counts {
+		toSave.Counts[string(dimension)] = counts
+	}
+
+	return putJSON(tx.Bucket(statsBucket), []byte(stats.ShortURL), toSave)
+}
+
+func putJSON(bucket *bbolt.Bucket, key []byte, value any) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put(key, raw)
+}
+
+// ownedURLKey is a key of the original URL shortened by the owner. Every owner gets its own short URL
+// for the same original URL.
+func ownedURLKey(owner, originalURL string) []byte {
+	return compositeKey([]byte(owner), originalURL)
+}
+
+// expirationKey is a key of the expiring short URL ordered by expiration time.
+// The sign bit of time is flipped, so keys of times before 1970 are ordered too.
+func expirationKey(expiresAt int64, shortURL string) []byte {
+	key := binary.BigEndian.AppendUint64(nil, uint64(expiresAt)^(1<<63))
+	return append(key, shortURL...)
+}
+
+func compositeKey(prefix []byte, suffix string) []byte {
+	key := append(bytes.Clone(prefix), keySeparator)
+	return append(key, suffix...)
+}
+
+func notFoundError(shortURL string) error {
+	return fmt.Errorf("%w: %q in bolt", urlstore.ErrNotFound, shortURL)
+}
@@ -0,0 +1,108 @@
+package boltstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/storagetest"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func openTestDB(t *testing.T, path string) *bbolt.DB {
+	t.Helper()
+
+	db, err := Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	return db
+}
+
+func newTestStorage(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) (*BoltStorage, *bbolt.DB) {
+	t.Helper()
+
+	db := openTestDB(t, filepath.Join(t.TempDir(), "shorturl.db"))
+	return NewBoltStorage(db, idEncoder, shortURLLength), db
+}
+
+func TestBoltStorage_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) storagetest.Storage {
+		storage, _ := newTestStorage(t, idEncoder, shortURLLength)
+		return storage
+	})
+}
+
+func TestBoltStorage_Delete_RemovesKeys(t *testing.T) {
+	sut, db := newTestStorage(t, encoder.NewIDEncoder(), storagetest.ShortURLLength)
+	ctx := context.Background()
+
+	shortURL, err := sut.AddLink(ctx, urlstore.Link{OriginalURL: "https://example.com", Owner: "owner", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	require.NoError(t, sut.AddClickStats(ctx, []urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1}}))
+	require.NoError(t, sut.UpdateOriginalURL(ctx, "owner", shortURL, "https://example.org"))
+
+	require.NoError(t, sut.Delete(ctx, "owner", shortURL))
+
+	err = db.View(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			assert.Zero(t, tx.Bucket(bucket).Stats().KeyN, string(bucket))
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestBoltStorage_Ping(t *testing.T) {
+	sut, db := newTestStorage(t, encoder.NewIDEncoder(), storagetest.ShortURLLength)
+
+	require.NoError(t, sut.Ping(context.Background()))
+
+	require.NoError(t, db.Close())
+	assert.Error(t, sut.Ping(context.Background()))
+}
+
+func TestBoltStorage_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shorturl.db")
+	ctx := context.Background()
+
+	db, err := Open(path)
+	require.NoError(t, err)
+	sut := NewBoltStorage(db, encoder.NewIDEncoder(), storagetest.ShortURLLength)
+
+	shortURL, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, sut.SetDisabled(ctx, "owner", shortURL, true))
+	require.NoError(t, db.Close())
+
+	sut = NewBoltStorage(openTestDB(t, path), encoder.NewIDEncoder(), storagetest.ShortURLLength)
+
+	link, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, urlstore.Link{ShortURL: shortURL, OriginalURL: "https://example.com", Owner: "owner", Disabled: true}, link)
+
+	reused, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortURL, reused)
+
+	newShortURL, err := sut.ShortURL(ctx, "owner", "https://example.org")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, newShortURL, "ids must not be reused after reopening")
+}
+
+func TestOpen_Locked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shorturl.db")
+	openTestDB(t, path)
+
+	_, err := Open(path)
+	assert.Error(t, err)
+}
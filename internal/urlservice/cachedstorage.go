@@ -0,0 +1,293 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/lru"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// CacheSettings are settings of the cache of links in front of a storage.
+type CacheSettings struct {
+	// Size is a maximum count of cached links, it must be positive.
+	Size int
+
+	// TTL is a time to live of cached links.
+	TTL time.Duration
+
+	// NegativeTTL is a time to live of cached misses, short URLs that are not saved in the storage.
+	NegativeTTL time.Duration
+}
+
+// WithCache returns an option that initializes a storage with the storage option and puts
+// a read-through LRU cache of links in front of it. The cache is local to the program, so changes
+// made by other instances sharing the storage are visible only when cached links expire.
+func WithCache(storageOption StorageOptionFunc, settings CacheSettings) StorageOptionFunc {
+	return func(idEncoder encoder.IDEncoder, shortURLLength uint) urlStorage {
+		return newCachedStorage(storageOption(idEncoder, shortURLLength), settings)
+	}
+}
+
+// cachedStorage is a decorator of urlStorage that caches links read by short URLs, including misses.
+// Concurrent misses of the same short URL are coalesced into one read of the storage. Cached links are
+// invalidated by changes of their short URLs made through the decorator.
+//
+// Links are cached not longer than until they expire. Expired links are cached as well, service checks
+// expiration itself, but only as long as misses, because they are purged from the storage and their
+// short URLs may be taken by new links.
+type cachedStorage struct {
+	urlStorage
+
+	links       *lru.Cache[string, cachedLink]
+	group       singleflight.Group
+	ttl         time.Duration
+	negativeTTL time.Duration
+
+	// reads are short URLs being read from the storage. Their links are not cached if the short URLs
+	// are invalidated during the reads, because they may be stale. Short URLs are removed when their
+	// last read finishes, so invalidation of other short URLs never touches them. It is guarded by mu
+	// together with changes of the cache.
+	mu    sync.Mutex
+	reads map[string]*pendingRead
+}
+
+// pendingRead is a count of reads of short URL in progress and a generation of the short URL
+// incremented by every invalidation during them.
+type pendingRead struct {
+	count      int
+	generation uint64
+}
+
+// sharedReadTimeout is a maximum duration of the read of the storage shared by concurrent calls.
+const sharedReadTimeout = 10 * time.Second
+
+// cachedLink is a link of short URL or a miss if the short URL is not found.
+type cachedLink struct {
+	link    urlstore.Link
+	isFound bool
+}
+
+func newCachedStorage(storage urlStorage, settings CacheSettings) *cachedStorage {
+	return &cachedStorage{
+		urlStorage:  storage,
+		links:       lru.New[string, cachedLink](settings.Size),
+		ttl:         settings.TTL,
+		negativeTTL: settings.NegativeTTL,
+		reads:       make(map[string]*pendingRead),
+	}
+}
+
+// Link returns the cached link or reads it from the storage and caches it. Only one read of
+// the short URL is made at a time, concurrent calls wait for it and share its result.
+// A call returns the error of its context if it is done before the shared read.
+func (s *cachedStorage) Link(ctx context.Context, shortURL string) (urlstore.Link, error) {
+	if cached, isFound := s.links.Get(shortURL); isFound {
+		return cached.linkOrError(shortURL)
+	}
+
+	resultCh := s.group.DoChan(shortURL, func() (interface{}, error) {
+		// The read is shared by concurrent calls, so it is not canceled with the context of the first one.
+		readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedReadTimeout)
+		defer cancel()
+
+		generations := s.beginReads(shortURL)
+		link, err := s.urlStorage.Link(readCtx, shortURL)
+		switch {
+		case errors.Is(err, urlstore.ErrNotFound):
+			s.endRead(shortURL, generations[0], &cachedLink{})
+		case err == nil:
+			s.endRead(shortURL, generations[0], &cachedLink{link: link, isFound: true})
+		default:
+			s.endRead(shortURL, generations[0], nil)
+		}
+
+		return link, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return urlstore.Link{}, ctx.Err()
+	case result := <-resultCh:
+		return result.Val.(urlstore.Link), result.Err
+	}
+}
+
+// Links returns cached links and reads the rest from the storage with a single call, caching them.
+// Short URLs that are not found are skipped and cached as misses.
+func (s *cachedStorage) Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	links := make([]urlstore.Link, 0, len(shortURLs))
+	missedShortURLs := make([]string, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		cached, isFound := s.links.Get(shortURL)
+		switch {
+		case !isFound:
+			missedShortURLs = append(missedShortURLs, shortURL)
+		case cached.isFound:
+			links = append(links, cached.link)
+		}
+	}
+
+	if len(missedShortURLs) == 0 {
+		return links, nil
+	}
+
+	generations := s.beginReads(missedShortURLs...)
+	storedLinks, err := s.urlStorage.Links(ctx, missedShortURLs)
+	if err != nil {
+		for i, shortURL := range missedShortURLs {
+			s.endRead(shortURL, generations[i], nil)
+		}
+
+		return nil, err
+	}
+
+	storedByShortURLs := make(map[string]urlstore.Link, len(storedLinks))
+	for _, link := range storedLinks {
+		storedByShortURLs[link.ShortURL] = link
+	}
+
+	for i, shortURL := range missedShortURLs {
+		cached := cachedLink{}
+		if link, isStored := storedByShortURLs[shortURL]; isStored {
+			cached = cachedLink{link: link, isFound: true}
+		}
+
+		s.endRead(shortURL, generations[i], &cached)
+	}
+
+	return append(links, storedLinks...), nil
+}
+
+// ShortURL invalidates the returned short URL, because it may be a new one cached as a miss.
+func (s *cachedStorage) ShortURL(ctx context.Context, owner, originalURL string) (string, error) {
+	shortURL, err := s.urlStorage.ShortURL(ctx, owner, originalURL)
+	if err == nil {
+		s.invalidate(shortURL)
+	}
+
+	return shortURL, err
+}
+
+// ShortURLs invalidates the returned short URLs, because they may be new ones cached as misses.
+func (s *cachedStorage) ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	shortURLs, err := s.urlStorage.ShortURLs(ctx, owner, originalURLs)
+	if err == nil {
+		s.invalidate(shortURLs...)
+	}
+
+	return shortURLs, err
+}
+
+// AddLink invalidates the short URL of the link, because it may replace an expired link or a cached miss.
+func (s *cachedStorage) AddLink(ctx context.Context, link urlstore.Link) (string, error) {
+	shortURL, err := s.urlStorage.AddLink(ctx, link)
+	s.invalidate(link.ShortURL, shortURL)
+
+	return shortURL, err
+}
+
+func (s *cachedStorage) Delete(ctx context.Context, owner, shortURL string) error {
+	defer s.invalidate(shortURL)
+	return s.urlStorage.Delete(ctx, owner, shortURL)
+}
+
+func (s *cachedStorage) SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error {
+	defer s.invalidate(shortURL)
+	return s.urlStorage.SetDisabled(ctx, owner, shortURL, disabled)
+}
+
+func (s *cachedStorage) UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error {
+	defer s.invalidate(shortURL)
+	return s.urlStorage.UpdateOriginalURL(ctx, owner, shortURL, originalURL)
+}
+
+// beginReads registers reads of short URLs from the storage and returns their current generations.
+// Every read must be finished by endRead.
+func (s *cachedStorage) beginReads(shortURLs ...string) []uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	generations := make([]uint64, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		read, isFound := s.reads[shortURL]
+		if !isFound {
+			read = &pendingRead{}
+			s.reads[shortURL] = read
+		}
+
+		read.count++
+		generations[i] = read.generation
+	}
+
+	return generations
+}
+
+// endRead finishes the read of the short URL and caches its result if the short URL was not invalidated
+// since the read began. Nil result means that the read failed and nothing is cached.
+func (s *cachedStorage) endRead(shortURL string, generation uint64, cached *cachedLink) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	read := s.reads[shortURL]
+	if cached != nil && read.generation == generation {
+		s.links.Add(shortURL, *cached, s.linkTTL(*cached))
+	}
+
+	read.count--
+	if read.count == 0 {
+		delete(s.reads, shortURL)
+	}
+}
+
+// linkTTL returns the time to live of the cached link, it is capped at the expiration of the link.
+func (s *cachedStorage) linkTTL(cached cachedLink) time.Duration {
+	if !cached.isFound {
+		return s.negativeTTL
+	}
+
+	if cached.link.ExpiresAt.IsZero() {
+		return s.ttl
+	}
+
+	untilExpiration := time.Until(cached.link.ExpiresAt)
+	if untilExpiration <= 0 {
+		return min(s.ttl, s.negativeTTL)
+	}
+
+	return min(s.ttl, untilExpiration)
+}
+
+// invalidate removes short URLs from the cache and makes their reads in progress not cached.
+// Reads of other short URLs are cached as usual.
+func (s *cachedStorage) invalidate(shortURLs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, shortURL := range shortURLs {
+		if shortURL == "" {
+			continue
+		}
+
+		if read, isFound := s.reads[shortURL]; isFound {
+			read.generation++
+		}
+
+		s.links.Remove(shortURL)
+		s.group.Forget(shortURL)
+	}
+}
+
+func (c cachedLink) linkOrError(shortURL string) (urlstore.Link, error) {
+	if !c.isFound {
+		return urlstore.Link{}, fmt.Errorf("%w: %q in cache", urlstore.ErrNotFound, shortURL)
+	}
+
+	return c.link, nil
+}
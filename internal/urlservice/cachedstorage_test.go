@@ -0,0 +1,364 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+var testCacheSettings = CacheSettings{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute}
+
+func TestCachedStorage_Link(t *testing.T) {
+	link := urlstore.Link{ShortURL: "1234567890", OriginalURL: "https://example.com"}
+
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(link, nil).Once()
+
+	sut := newCachedStorage(storageMock, testCacheSettings)
+	for range 3 {
+		result, err := sut.Link(context.Background(), "1234567890")
+		require.NoError(t, err)
+		assert.Equal(t, link, result)
+	}
+}
+
+func TestCachedStorage_LinkMiss(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedCalls int
+	}{
+		{
+			name:          "not found is cached",
+			err:           urlstore.ErrNotFound,
+			expectedCalls: 1,
+		},
+		{
+			name:          "other error is not cached",
+			err:           errors.New("some error"),
+			expectedCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := NewMockurlStorage(t)
+			storageMock.EXPECT().
+				Link(mock.Anything, "1234567890").
+				Return(urlstore.Link{}, tt.err).
+				Times(tt.expectedCalls)
+
+			sut := newCachedStorage(storageMock, testCacheSettings)
+			for range 2 {
+				_, err := sut.Link(context.Background(), "1234567890")
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCachedStorage_LinkCoalescing(t *testing.T) {
+	const callers = 10
+
+	release := make(chan struct{})
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		RunAndReturn(func(_ context.Context, shortURL string) (urlstore.Link, error) {
+			<-release
+			return urlstore.Link{ShortURL: shortURL}, nil
+		}).
+		Once()
+
+	sut := newCachedStorage(storageMock, testCacheSettings)
+
+	var wg sync.WaitGroup
+	for range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			link, err := sut.Link(context.Background(), "1234567890")
+			assert.NoError(t, err)
+			assert.Equal(t, "1234567890", link.ShortURL)
+		}()
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
+
+func TestCachedStorage_LinkCanceledCaller(t *testing.T) {
+	release := make(chan struct{})
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		RunAndReturn(func(ctx context.Context, shortURL string) (urlstore.Link, error) {
+			<-release
+			assert.NoError(t, ctx.Err(), "Shared read must not be canceled with the first caller")
+			return urlstore.Link{ShortURL: shortURL}, nil
+		}).
+		Once()
+
+	sut := newCachedStorage(storageMock, testCacheSettings)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := sut.Link(ctx, "1234567890")
+		firstErr <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	secondResult := make(chan urlstore.Link)
+	go func() {
+		link, err := sut.Link(context.Background(), "1234567890")
+		assert.NoError(t, err)
+		secondResult <- link
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	close(release)
+	assert.Equal(t, "1234567890", (<-secondResult).ShortURL)
+}
+
+func TestCachedStorage_LinkTTL(t *testing.T) {
+	now := time.Now()
+	settings := CacheSettings{Size: 10, TTL: time.Minute, NegativeTTL: 5 * time.Second}
+	sut := newCachedStorage(NewMockurlStorage(t), settings)
+
+	tests := []struct {
+		name     string
+		cached   cachedLink
+		expected time.Duration
+	}{
+		{name: "miss", cached: cachedLink{}, expected: 5 * time.Second},
+		{name: "link without expiration", cached: cachedLink{isFound: true}, expected: time.Minute},
+		{
+			name:     "link expiring after ttl",
+			cached:   cachedLink{link: urlstore.Link{ExpiresAt: now.Add(time.Hour)}, isFound: true},
+			expected: time.Minute,
+		},
+		{
+			name:     "link expiring before ttl",
+			cached:   cachedLink{link: urlstore.Link{ExpiresAt: now.Add(30 * time.Second)}, isFound: true},
+			expected: 30 * time.Second,
+		},
+		{
+			name:     "expired link",
+			cached:   cachedLink{link: urlstore.Link{ExpiresAt: now.Add(-time.Second)}, isFound: true},
+			expected: 5 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, sut.linkTTL(tt.cached), float64(time.Second))
+		})
+	}
+}
+
+func TestCachedStorage_ExpiringLinkIsReadAgain(t *testing.T) {
+	link := urlstore.Link{ShortURL: "1234567890", OriginalURL: "https://example.com", ExpiresAt: time.Now().Add(50 * time.Millisecond)}
+
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(link, nil).Once()
+	storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(urlstore.Link{}, urlstore.ErrNotFound).Once()
+
+	sut := newCachedStorage(storageMock, testCacheSettings)
+	_, err := sut.Link(context.Background(), "1234567890")
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = sut.Link(context.Background(), "1234567890")
+	assert.ErrorIs(t, err, urlstore.ErrNotFound, "Link purged after expiration must not be returned from cache")
+}
+
+func TestCachedStorage_Invalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(storageMock *MockurlStorage)
+		change func(sut *cachedStorage) error
+	}{
+		{
+			name: "delete",
+			setup: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().Delete(mock.Anything, "owner", "1234567890").Return(nil).Once()
+			},
+			change: func(sut *cachedStorage) error {
+				return sut.Delete(context.Background(), "owner", "1234567890")
+			},
+		},
+		{
+			name: "disable",
+			setup: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().SetDisabled(mock.Anything, "owner", "1234567890", true).Return(nil).Once()
+			},
+			change: func(sut *cachedStorage) error {
+				return sut.SetDisabled(context.Background(), "owner", "1234567890", true)
+			},
+		},
+		{
+			name: "update original url",
+			setup: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().UpdateOriginalURL(mock.Anything, "owner", "1234567890", "https://example.org").Return(nil).Once()
+			},
+			change: func(sut *cachedStorage) error {
+				return sut.UpdateOriginalURL(context.Background(), "owner", "1234567890", "https://example.org")
+			},
+		},
+		{
+			name: "add link with alias",
+			setup: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().
+					AddLink(mock.Anything, urlstore.Link{ShortURL: "1234567890", OriginalURL: "https://example.org"}).
+					Return("1234567890", nil).
+					Once()
+			},
+			change: func(sut *cachedStorage) error {
+				_, err := sut.AddLink(context.Background(), urlstore.Link{ShortURL: "1234567890", OriginalURL: "https://example.org"})
+				return err
+			},
+		},
+		{
+			name: "reused short url",
+			setup: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().ShortURL(mock.Anything, "", "https://example.org").Return("1234567890", nil).Once()
+			},
+			change: func(sut *cachedStorage) error {
+				_, err := sut.ShortURL(context.Background(), "", "https://example.org")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := NewMockurlStorage(t)
+			storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(urlstore.Link{}, urlstore.ErrNotFound).Once()
+			storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(urlstore.Link{ShortURL: "1234567890"}, nil).Once()
+			tt.setup(storageMock)
+
+			sut := newCachedStorage(storageMock, testCacheSettings)
+			_, err := sut.Link(context.Background(), "1234567890")
+			require.ErrorIs(t, err, urlstore.ErrNotFound)
+
+			require.NoError(t, tt.change(sut))
+
+			link, err := sut.Link(context.Background(), "1234567890")
+			require.NoError(t, err)
+			assert.Equal(t, "1234567890", link.ShortURL)
+		})
+	}
+}
+
+func TestCachedStorage_StaleReadIsNotCached(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	sut := newCachedStorage(storageMock, testCacheSettings)
+
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		RunAndReturn(func(_ context.Context, shortURL string) (urlstore.Link, error) {
+			sut.invalidate(shortURL)
+			return urlstore.Link{ShortURL: shortURL, OriginalURL: "https://example.com"}, nil
+		}).
+		Once()
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		Return(urlstore.Link{ShortURL: "1234567890", OriginalURL: "https://example.org"}, nil).
+		Once()
+
+	_, err := sut.Link(context.Background(), "1234567890")
+	require.NoError(t, err)
+
+	link, err := sut.Link(context.Background(), "1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org", link.OriginalURL)
+}
+
+func TestCachedStorage_ReadIsCachedAfterInvalidationOfAnotherShortURL(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	sut := newCachedStorage(storageMock, testCacheSettings)
+
+	storageMock.EXPECT().ShortURL(mock.Anything, "", "https://example.org").Return("0987654321", nil).Once()
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		RunAndReturn(func(ctx context.Context, shortURL string) (urlstore.Link, error) {
+			_, err := sut.ShortURL(ctx, "", "https://example.org")
+			require.NoError(t, err)
+			return urlstore.Link{ShortURL: shortURL, OriginalURL: "https://example.com"}, nil
+		}).
+		Once()
+
+	for range 2 {
+		link, err := sut.Link(context.Background(), "1234567890")
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com", link.OriginalURL)
+	}
+
+	assert.Empty(t, sut.reads, "Finished reads are kept")
+}
+
+func TestCachedStorage_Links(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Link(mock.Anything, "aaaaaaaaaa").Return(urlstore.Link{ShortURL: "aaaaaaaaaa"}, nil).Once()
+	storageMock.EXPECT().
+		Links(mock.Anything, []string{"bbbbbbbbbb", "cccccccccc"}).
+		Return([]urlstore.Link{{ShortURL: "bbbbbbbbbb"}}, nil).
+		Once()
+
+	sut := newCachedStorage(storageMock, testCacheSettings)
+	_, err := sut.Link(context.Background(), "aaaaaaaaaa")
+	require.NoError(t, err)
+
+	for range 2 {
+		links, err := sut.Links(context.Background(), []string{"aaaaaaaaaa", "bbbbbbbbbb", "cccccccccc"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []urlstore.Link{{ShortURL: "aaaaaaaaaa"}, {ShortURL: "bbbbbbbbbb"}}, links)
+	}
+
+	_, err = sut.Link(context.Background(), "cccccccccc")
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+}
+
+func TestCachedStorage_WithMetrics(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(urlstore.Link{}, nil).Once()
+
+	metricsMock := NewMockMetrics(t)
+	metricsMock.EXPECT().ObserveStorageOperation("unknown", "link", mock.Anything, mock.Anything).Once()
+	metricsMock.EXPECT().AddLinksResolved(1).Twice()
+
+	sut := ShortURLService{storage: newCachedStorage(storageMock, testCacheSettings)}
+	WithMetrics(metricsMock)(&sut)
+
+	for range 2 {
+		_, err := sut.OriginalURL(context.Background(), "1234567890")
+		require.NoError(t, err)
+	}
+
+	_, ok := sut.storage.(*cachedStorage)
+	assert.True(t, ok, "cache must stay in front of instrumented storage")
+}
+
+func TestWithCache(t *testing.T) {
+	sut := NewShortURLService(nil, 10, WithCache(WithInMemoryStorage(), testCacheSettings), WithMetrics(NewMockMetrics(t)))
+
+	cached, ok := sut.storage.(*cachedStorage)
+	require.True(t, ok)
+
+	storage, ok := cached.urlStorage.(*instrumentedStorage)
+	require.True(t, ok)
+	assert.Equal(t, "memstore", storage.name)
+}
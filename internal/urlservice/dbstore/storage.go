@@ -8,12 +8,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	// maxEncodingAttempts limits count of ids tried to encode a short URL that is not saved yet.
+	maxEncodingAttempts = 100
+	// linksPageSize is a count of links read by a single query while iterating over all links.
+	linksPageSize = 1000
 )
 
 // PostgreSQLStorage is a database URL storage using PostgreSQL.
@@ -35,69 +44,486 @@ func NewPostgreSQLStorage(pool *pgxpool.Pool, idEncoder encoder.IDEncoder, short
 	}
 }
 
-// OriginalURL is looking for the original URL by passed short URL.
-// If the short URL does not exist in the storage, it returns an error.
-func (s PostgreSQLStorage) OriginalURL(ctx context.Context, shortURL string) (string, error) {
+// Link is looking for the link by passed short URL.
+// If the short URL does not exist in the storage, it returns an error wrapping urlstore.ErrNotFound.
+func (s PostgreSQLStorage) Link(ctx context.Context, shortURL string) (urlstore.Link, error) {
 	const sql = `
-		SELECT original_url FROM short_urls
+		SELECT original_url, expires_at, disabled, owner FROM short_urls
 		WHERE url = $1;
 	`
 
-	var originalURL string
-	err := s.pool.QueryRow(ctx, sql, shortURL).Scan(&originalURL)
+	var expiresAt *time.Time
+	link := urlstore.Link{ShortURL: shortURL}
+	err := s.pool.QueryRow(ctx, sql, shortURL).Scan(&link.OriginalURL, &expiresAt, &link.Disabled, &link.Owner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return urlstore.Link{}, notFoundError(shortURL)
+	}
+
 	if err != nil {
-		return "", fmt.Errorf("failed to get %q url from db: %w", shortURL, err)
+		return urlstore.Link{}, fmt.Errorf("failed to get %q url from db: %w", shortURL, err)
 	}
 
-	return originalURL, nil
+	if expiresAt != nil {
+		link.ExpiresAt = *expiresAt
+	}
+
+	return link, nil
 }
 
-// ShortURL should always return short URL for provided original URL value.
+// ShortURL should always return the owner's short URL for provided original URL value.
 // At first, it tries to find saved value, but if it does not exist, it encodes the
 // original URL by incremented ID from the database and returns a new value.
 //
 // If inserting transaction returned error that some unique value is already saved in the database,
 // the function checks once more if the original URL exists.
 // It can happen in a case where another same transaction was executed before.
-func (s PostgreSQLStorage) ShortURL(ctx context.Context, originalURL string) (string, error) {
-	shortURL, err := s.tryFindShortURL(ctx, originalURL)
+func (s PostgreSQLStorage) ShortURL(ctx context.Context, owner, originalURL string) (string, error) {
+	shortURL, err := s.tryFindShortURL(ctx, owner, originalURL)
+	if err == nil {
+		return shortURL, nil
+	}
+
 	if !errors.Is(err, pgx.ErrNoRows) {
-		return shortURL, fmt.Errorf("unexpected eror in db for %q url: %w", originalURL, err)
+		return "", fmt.Errorf("unexpected error in db for %q url: %w", originalURL, err)
+	}
+
+	shortURL, err = s.addNewURL(ctx, owner, originalURL)
+	if isURLAddedByOtherTransaction(err) {
+		shortURL, err = s.tryFindShortURL(ctx, owner, originalURL)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get %q url from db: %w", originalURL, err)
+	}
+
+	return shortURL, nil
+}
+
+// ShortURLs returns the owner's short URLs for all provided original URLs in the same order. Like ShortURL,
+// it returns saved values or encodes new ones, but it does it for the whole batch in a single
+// transaction with a constant count of queries.
+//
+// New short URLs are encoded by ids of original URLs saved by the call. Short URLs of original URLs
+// saved before, that may be short URLs of deleted links, and short URLs that are taken by updated or
+// imported links or by links of other owners are replaced with ones encoded by next ids from the sequence.
+func (s PostgreSQLStorage) ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	uniqueURLs := uniqueValues(originalURLs)
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction for batch of urls: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+	newIDsByURLs, err := insertOriginalURLs(ctx, uniqueURLs, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save batch of urls in db: %w", err)
+	}
+
+	shortByOriginalURLs, err := s.insertShortURLs(ctx, owner, uniqueURLs, newIDsByURLs, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save batch of short urls in db: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit batch of urls in db: %w", err)
+	}
+
+	shortURLs := make([]string, 0, len(originalURLs))
+	for _, originalURL := range originalURLs {
+		shortURLs = append(shortURLs, shortByOriginalURLs[originalURL])
+	}
+
+	return shortURLs, nil
+}
+
+// Links is looking for links by passed short URLs with a single query.
+// Short URLs that do not exist in the storage are skipped.
+func (s PostgreSQLStorage) Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	const sql = `
+		SELECT url, original_url, expires_at, disabled, owner FROM short_urls
+		WHERE url = ANY($1);
+	`
+
+	rows, err := s.pool.Query(ctx, sql, shortURLs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch of short urls from db: %w", err)
+	}
+
+	links, err := pgx.CollectRows(rows, scanLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch of short urls from db: %w", err)
+	}
+
+	return links, nil
+}
+
+// AddLink saves the link as not reusable, so it is never returned by ShortURL, and returns
+// its short URL. If the link has no short URL, it encodes a new one by next ID from the
+// sequence of original URLs ids, skipping short URLs that are already saved.
+// The link is saved disabled at once if it is disabled.
+//
+// If the short URL is mapped with an expired link, the link is replaced and its click statistics are removed.
+// Otherwise, if it is already mapped with the same original URL of the same owner, it does nothing, the saved
+// link is not changed, and if it is mapped with another original URL or owned by another owner, it returns
+// an error wrapping urlstore.ErrShortURLTaken.
+func (s PostgreSQLStorage) AddLink(ctx context.Context, link urlstore.Link) (string, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction for %q url: %w", link.OriginalURL, err)
+	}
+
+	defer tx.Rollback(ctx)
+	if _, _, err := s.insertOriginalURL(ctx, link.OriginalURL, tx); err != nil {
+		return "", fmt.Errorf("failed to save %q url in db: %w", link.OriginalURL, err)
+	}
+
+	if link.ShortURL == "" {
+		return s.addEncodedLink(ctx, link, tx)
+	}
+
+	isInserted, err := s.insertLink(ctx, link, tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to save %q short url in db: %w", link.ShortURL, err)
+	}
+
+	if !isInserted {
+		return link.ShortURL, s.checkLinkMapping(ctx, link, tx)
+	}
+
+	return link.ShortURL, tx.Commit(ctx)
+}
+
+// addEncodedLink saves the link with a short URL encoded by next ID and commits the transaction.
+// Short URLs that are already saved, for example imported ones, are skipped.
+func (s PostgreSQLStorage) addEncodedLink(ctx context.Context, link urlstore.Link, tx pgx.Tx) (string, error) {
+	for range maxEncodingAttempts {
+		shortURL, err := s.encodeNextID(ctx, tx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get new id from db: %w", err)
+		}
+
+		link.ShortURL = shortURL
+		isInserted, err := s.insertLink(ctx, link, tx)
+		if err != nil {
+			return "", fmt.Errorf("failed to save %q short url in db: %w", link.ShortURL, err)
+		}
+
+		if isInserted {
+			return link.ShortURL, tx.Commit(ctx)
+		}
+	}
+
+	return "", fmt.Errorf("failed to encode unique short url for %q url", link.OriginalURL)
+}
+
+// ForEachLink calls fn for every link saved in the storage in order of short URLs. Links are
+// read by pages using keyset pagination, so the connection is not held while fn is called
+// and links changed during iteration may be missed or returned in their new state.
+// It stops on the first error returned by fn and returns it.
+func (s PostgreSQLStorage) ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error {
+	var lastShortURL string
+	for {
+		page, err := s.linksPage(ctx, lastShortURL)
+		if err != nil {
+			return fmt.Errorf("failed to get links after %q from db: %w", lastShortURL, err)
+		}
+
+		for _, link := range page {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < linksPageSize {
+			return nil
+		}
+
+		lastShortURL = page[len(page)-1].ShortURL
+	}
+}
+
+// OwnerLinks returns up to limit links of the owner with short URLs greater than
+// the passed one in order of short URLs. Pass empty short URL to get the first page.
+func (s PostgreSQLStorage) OwnerLinks(ctx context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	const sql = `
+		SELECT url, original_url, expires_at, disabled, owner FROM short_urls
+		WHERE owner = $1 AND url > $2
+		ORDER BY url
+		LIMIT $3;
+	`
+
+	rows, err := s.pool.Query(ctx, sql, owner, afterShortURL, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links of owner after %q from db: %w", afterShortURL, err)
+	}
+
+	links, err := pgx.CollectRows(rows, scanLink)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read links of owner after %q from db: %w", afterShortURL, err)
+	}
+
+	return links, nil
+}
+
+// DeleteExpired removes all links that have expired at the moment and returns their count.
+func (s PostgreSQLStorage) DeleteExpired(ctx context.Context, moment time.Time) (int64, error) {
+	const sql = `
+		DELETE FROM short_urls
+		WHERE expires_at <= $1;
+	`
+
+	tag, err := s.pool.Exec(ctx, sql, moment)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired urls from db: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// Delete removes the link with its click statistics. If the link is returned by ShortURL
+// for its original URL, the next call of ShortURL encodes a new short URL.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s PostgreSQLStorage) Delete(ctx context.Context, owner, shortURL string) error {
+	const sql = `
+		DELETE FROM short_urls
+		WHERE url = $1 AND ($2 OR owner = $3);
+	`
+
+	tag, err := s.pool.Exec(ctx, sql, shortURL, owner == urlstore.AnyOwner, owner)
+	if err != nil {
+		return fmt.Errorf("failed to delete %q url from db: %w", shortURL, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFoundError(shortURL)
+	}
+
+	return nil
+}
+
+// SetDisabled disables or enables the link. Disabled link is still returned by ShortURL
+// for its original URL, so it is not replaced with a new one while it is disabled.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s PostgreSQLStorage) SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error {
+	const sql = `
+		UPDATE short_urls SET disabled = $2
+		WHERE url = $1 AND ($3 OR owner = $4);
+	`
+
+	tag, err := s.pool.Exec(ctx, sql, shortURL, disabled, owner == urlstore.AnyOwner, owner)
+	if err != nil {
+		return fmt.Errorf("failed to update %q url in db: %w", shortURL, err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return notFoundError(shortURL)
+	}
+
+	return nil
+}
+
+// UpdateOriginalURL maps the short URL with another original URL and records the change in
+// a single transaction. Updated link is not returned by ShortURL for any original URL anymore.
+// Its expiration, status and click statistics are kept. If the original URL is the same, it does nothing.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s PostgreSQLStorage) UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error {
+	const selectSQL = `
+		SELECT original_url FROM short_urls
+		WHERE url = $1 AND ($2 OR owner = $3)
+		FOR UPDATE;
+	`
+	const updateSQL = `
+		UPDATE short_urls SET original_url = $2, reusable = FALSE
+		WHERE url = $1;
+	`
+	const changeSQL = `
+		INSERT INTO short_url_changes (short_url, previous_url, new_url)
+		VALUES ($1, $2, $3);
+	`
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction for %q short url: %w", shortURL, err)
+	}
+
+	defer tx.Rollback(ctx)
+	var previousURL string
+	err = tx.QueryRow(ctx, selectSQL, shortURL, owner == urlstore.AnyOwner, owner).Scan(&previousURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return notFoundError(shortURL)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to get %q short url from db: %w", shortURL, err)
+	}
+
+	if previousURL == originalURL {
+		return nil
+	}
+
+	if _, _, err := s.insertOriginalURL(ctx, originalURL, tx); err != nil {
+		return fmt.Errorf("failed to save %q url in db: %w", originalURL, err)
+	}
+
+	if _, err := tx.Exec(ctx, updateSQL, shortURL, originalURL); err != nil {
+		return fmt.Errorf("failed to update %q short url in db: %w", shortURL, err)
+	}
+
+	if _, err := tx.Exec(ctx, changeSQL, shortURL, previousURL, originalURL); err != nil {
+		return fmt.Errorf("failed to save change of %q short url in db: %w", shortURL, err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// History returns changes of original URL mapped with the short URL, the oldest first.
+func (s PostgreSQLStorage) History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	const sql = `
+		SELECT previous_url, new_url, changed_at FROM short_url_changes
+		WHERE short_url = $1
+		ORDER BY id;
+	`
+
+	rows, err := s.pool.Query(ctx, sql, shortURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes of %q short url from db: %w", shortURL, err)
+	}
+
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (urlstore.DestinationChange, error) {
+		change := urlstore.DestinationChange{ShortURL: shortURL}
+		err := row.Scan(&change.PreviousURL, &change.NewURL, &change.ChangedAt)
+		return change, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read changes of %q short url from db: %w", shortURL, err)
+	}
+
+	return changes, nil
+}
+
+// AddClickStats adds clicks to statistics of their short URLs in a single batch.
+// Clicks of short URLs that do not exist in the storage are ignored.
+func (s PostgreSQLStorage) AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error {
+	const statsSQL = `
+		INSERT INTO url_stats (short_url, clicks, last_click_at)
+		SELECT $1, $2, $3
+		WHERE EXISTS (SELECT 1 FROM short_urls WHERE url = $1)
+		ON CONFLICT (short_url) DO UPDATE
+			SET clicks = url_stats.clicks + EXCLUDED.clicks,
+				last_click_at = GREATEST(url_stats.last_click_at, EXCLUDED.last_click_at);
+	`
+	const countsSQL = `
+		INSERT INTO url_click_counts (short_url, dimension, value, clicks)
+		SELECT $1, $2, $3, $4
+		WHERE EXISTS (SELECT 1 FROM short_urls WHERE url = $1)
+		ON CONFLICT (short_url, dimension, value) DO UPDATE
+			SET clicks = url_click_counts.clicks + EXCLUDED.clicks;
+	`
+
+	batch := &pgx.Batch{}
+	for _, toAdd := range stats {
+		batch.Queue(statsSQL, toAdd.ShortURL, toAdd.Clicks, toAdd.LastClickAt)
+		for dimension, counts := range toAdd.Counts {
+			for value, clicks := range counts {
+				batch.Queue(countsSQL, toAdd.ShortURL, string(dimension), value, clicks)
+			}
+		}
+	}
+
+	if err := s.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save click stats in db: %w", err)
+	}
+
+	return nil
+}
+
+// ClickStats returns click statistics of the short URL.
+// If the short URL has never been clicked, it returns empty statistics.
+func (s PostgreSQLStorage) ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error) {
+	const statsSQL = `
+		SELECT clicks, last_click_at FROM url_stats
+		WHERE short_url = $1;
+	`
+	const countsSQL = `
+		SELECT dimension, value, clicks FROM url_click_counts
+		WHERE short_url = $1;
+	`
+
+	stats := urlstore.NewClickStats(shortURL)
+	err := s.pool.QueryRow(ctx, statsSQL, shortURL).Scan(&stats.Clicks, &stats.LastClickAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stats, nil
+	}
+
+	if err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to get stats of %q url from db: %w", shortURL, err)
+	}
+
+	rows, err := s.pool.Query(ctx, countsSQL, shortURL)
+	if err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to get click counts of %q url from db: %w", shortURL, err)
 	}
 
-	var newSearchError error
-	shortURL, insertError := s.addNewURL(ctx, originalURL, shortURL)
-	if isURLAddedByOtherTransaction(insertError) {
-		shortURL, newSearchError = s.tryFindShortURL(ctx, originalURL)
+	var (
+		dimension, value string
+		clicks           int64
+	)
+	_, err = pgx.ForEachRow(rows, []any{&dimension, &value, &clicks}, func() error {
+		stats.AddCount(urlstore.ClickDimension(dimension), value, clicks)
+		return nil
+	})
+	if err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to read click counts of %q url from db: %w", shortURL, err)
 	}
 
-	return shortURL, fmt.Errorf("failed to get %q url from db: %w: %w", originalURL, insertError, newSearchError)
+	return stats, nil
 }
 
-func (s PostgreSQLStorage) tryFindShortURL(ctx context.Context, originalURL string) (string, error) {
+// linksPage returns the next page of links with short URLs greater than the last one.
+func (s PostgreSQLStorage) linksPage(ctx context.Context, lastShortURL string) ([]urlstore.Link, error) {
+	const sql = `
+		SELECT url, original_url, expires_at, disabled, owner FROM short_urls
+		WHERE url > $1
+		ORDER BY url
+		LIMIT $2;
+	`
+
+	rows, err := s.pool.Query(ctx, sql, lastShortURL, linksPageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, scanLink)
+}
+
+func (s PostgreSQLStorage) tryFindShortURL(ctx context.Context, owner, originalURL string) (string, error) {
 	const sql = `
 		SELECT url FROM short_urls
-		WHERE original_url = $1;
+		WHERE owner = $1 AND original_url = $2 AND reusable;
 	`
 
 	var shortURL string
-	err := s.pool.QueryRow(ctx, sql, originalURL).Scan(&shortURL)
+	err := s.pool.QueryRow(ctx, sql, owner, originalURL).Scan(&shortURL)
 	return shortURL, err
 }
 
-func (s PostgreSQLStorage) addNewURL(ctx context.Context, originalURL string, shortURL string) (string, error) {
+func (s PostgreSQLStorage) addNewURL(ctx context.Context, owner, originalURL string) (string, error) {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return "", err
 	}
 
 	defer tx.Rollback(ctx)
-	newID, err := s.insertOriginalURL(ctx, originalURL, tx)
+	newID, isNew, err := s.insertOriginalURL(ctx, originalURL, tx)
 	if err != nil {
 		return "", err
 	}
 
-	shortURL, err = s.setShortURL(ctx, originalURL, newID, tx)
+	shortURL, err := s.setShortURL(ctx, owner, originalURL, newID, isNew, tx)
 	if err != nil {
 		return "", err
 	}
@@ -117,26 +543,343 @@ func isURLAddedByOtherTransaction(insertError error) bool {
 	return postgresError.Code == postgresUniqueDuplicateErrorCode
 }
 
-func (s PostgreSQLStorage) insertOriginalURL(ctx context.Context, originalURL string, tx pgx.Tx) (uint, error) {
+// insertOriginalURL saves the original URL if it is not saved yet. It returns the id of
+// the original URL and true if it is saved by the call, otherwise it returns false.
+func (s PostgreSQLStorage) insertOriginalURL(ctx context.Context, originalURL string, tx pgx.Tx) (uint, bool, error) {
 	const sql = `
 		INSERT INTO original_urls (url)
 		VALUES ($1)
-		
+		ON CONFLICT (url) DO NOTHING
 		RETURNING id;
 	`
 	var newID uint
 	err := tx.QueryRow(ctx, sql, originalURL).Scan(&newID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
 
-	return newID, err
+	return newID, err == nil, err
 }
 
-func (s PostgreSQLStorage) setShortURL(ctx context.Context, originalURL string, urlID uint, tx pgx.Tx) (string, error) {
+// setShortURL saves the owner's reusable short URL encoded by id of the original URL if it is new.
+// If the original URL was saved before, its short URL may belong to a deleted link, that must not
+// be restored, so a short URL encoded by next id from the sequence is saved. Next ids are also used
+// if the short URL is taken by an updated or imported link or by a link of another owner.
+func (s PostgreSQLStorage) setShortURL(ctx context.Context, owner, originalURL string, urlID uint, isNew bool, tx pgx.Tx) (string, error) {
 	const sql = `
-		INSERT INTO short_urls (original_url, url) 
-		VALUES ($1, $2);
+		INSERT INTO short_urls (original_url, url, owner)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (url) DO NOTHING;
 	`
-	shortURL := s.idEncoder.EncodeID(urlID, s.shortURLLength)
-	_, err := tx.Exec(ctx, sql, originalURL, shortURL)
+	var (
+		shortURL string
+		err      error
+	)
+	if isNew {
+		shortURL, err = s.encodeID(urlID)
+	} else {
+		shortURL, err = s.encodeNextID(ctx, tx)
+	}
 
-	return shortURL, err
+	if err != nil {
+		return "", err
+	}
+
+	for range maxEncodingAttempts {
+		tag, err := tx.Exec(ctx, sql, originalURL, shortURL, owner)
+		if err != nil || tag.RowsAffected() != 0 {
+			return shortURL, err
+		}
+
+		shortURL, err = s.encodeNextID(ctx, tx)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("failed to encode unique short url for %q url", originalURL)
+}
+
+// insertOriginalURLs saves all unique original URLs that are not saved yet and returns ids of saved ones.
+func insertOriginalURLs(ctx context.Context, uniqueURLs []string, tx pgx.Tx) (map[string]uint, error) {
+	const sql = `
+		INSERT INTO original_urls (url)
+		SELECT unnest($1::TEXT[])
+		ON CONFLICT (url) DO NOTHING
+		RETURNING url, id;
+	`
+
+	rows, err := tx.Query(ctx, sql, uniqueURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	newIDsByURLs := make(map[string]uint, len(uniqueURLs))
+	var (
+		originalURL string
+		id          uint
+	)
+	_, err = pgx.ForEachRow(rows, []any{&originalURL, &id}, func() error {
+		newIDsByURLs[originalURL] = id
+		return nil
+	})
+
+	return newIDsByURLs, err
+}
+
+// insertShortURLs returns the owner's reusable short URLs of the original URLs, saving new ones for original URLs
+// that have no short URL yet. New short URLs are encoded like by setShortURL: by ids of new original URLs or
+// by next ids for the rest. If some of them are taken, short URLs encoded by next ids are tried again.
+func (s PostgreSQLStorage) insertShortURLs(ctx context.Context, owner string, originalURLs []string, newIDsByURLs map[string]uint, tx pgx.Tx) (map[string]string, error) {
+	const insertSQL = `
+		INSERT INTO short_urls (original_url, url, owner)
+		SELECT original_url, url, $3 FROM unnest($1::TEXT[], $2::TEXT[]) AS new_urls (original_url, url)
+		ON CONFLICT DO NOTHING;
+	`
+
+	shortByOriginalURLs, err := findShortURLs(ctx, owner, originalURLs, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	missingURLs := missingKeys(originalURLs, shortByOriginalURLs)
+	for attempt := 0; attempt < maxEncodingAttempts && len(missingURLs) != 0; attempt++ {
+		var newShortURLs []string
+		if attempt == 0 {
+			newShortURLs, err = s.encodeOriginalIDs(ctx, missingURLs, newIDsByURLs, tx)
+		} else {
+			newShortURLs, err = s.encodeNextIDs(ctx, len(missingURLs), tx)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx, insertSQL, missingURLs, newShortURLs, owner); err != nil {
+			return nil, err
+		}
+
+		foundURLs, err := findShortURLs(ctx, owner, missingURLs, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		for originalURL, shortURL := range foundURLs {
+			shortByOriginalURLs[originalURL] = shortURL
+		}
+
+		missingURLs = missingKeys(missingURLs, shortByOriginalURLs)
+	}
+
+	if len(missingURLs) != 0 {
+		return nil, fmt.Errorf("failed to encode unique short urls for %d urls", len(missingURLs))
+	}
+
+	return shortByOriginalURLs, nil
+}
+
+func findShortURLs(ctx context.Context, owner string, originalURLs []string, tx pgx.Tx) (map[string]string, error) {
+	const sql = `
+		SELECT original_url, url FROM short_urls
+		WHERE owner = $1 AND original_url = ANY($2) AND reusable;
+	`
+
+	rows, err := tx.Query(ctx, sql, owner, originalURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	shortByOriginalURLs := make(map[string]string, len(originalURLs))
+	var originalURL, shortURL string
+	_, err = pgx.ForEachRow(rows, []any{&originalURL, &shortURL}, func() error {
+		shortByOriginalURLs[originalURL] = shortURL
+		return nil
+	})
+
+	return shortByOriginalURLs, err
+}
+
+// encodeOriginalIDs returns short URLs of the original URLs encoded by their ids if they are new
+// or by next ids from the sequence otherwise.
+func (s PostgreSQLStorage) encodeOriginalIDs(ctx context.Context, originalURLs []string, newIDsByURLs map[string]uint, tx pgx.Tx) ([]string, error) {
+	savedCount := len(originalURLs)
+	for _, originalURL := range originalURLs {
+		if _, isNew := newIDsByURLs[originalURL]; isNew {
+			savedCount--
+		}
+	}
+
+	var nextShortURLs []string
+	if savedCount != 0 {
+		var err error
+		if nextShortURLs, err = s.encodeNextIDs(ctx, savedCount, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	shortURLs := make([]string, 0, len(originalURLs))
+	for _, originalURL := range originalURLs {
+		id, isNew := newIDsByURLs[originalURL]
+		if !isNew {
+			shortURLs = append(shortURLs, nextShortURLs[0])
+			nextShortURLs = nextShortURLs[1:]
+			continue
+		}
+
+		shortURL, err := s.encodeID(id)
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	return shortURLs, nil
+}
+
+func (s PostgreSQLStorage) encodeNextIDs(ctx context.Context, count int, tx pgx.Tx) ([]string, error) {
+	const sql = `
+		SELECT nextval(pg_get_serial_sequence('original_urls', 'id')) FROM generate_series(1, $1);
+	`
+
+	rows, err := tx.Query(ctx, sql, count)
+	if err != nil {
+		return nil, err
+	}
+
+	shortURLs := make([]string, 0, count)
+	var newID uint
+	_, err = pgx.ForEachRow(rows, []any{&newID}, func() error {
+		shortURL, err := s.encodeID(newID)
+		shortURLs = append(shortURLs, shortURL)
+		return err
+	})
+
+	return shortURLs, err
+}
+
+func (s PostgreSQLStorage) encodeNextID(ctx context.Context, tx pgx.Tx) (string, error) {
+	const sql = `
+		SELECT nextval(pg_get_serial_sequence('original_urls', 'id'));
+	`
+
+	var newID uint
+	if err := tx.QueryRow(ctx, sql).Scan(&newID); err != nil {
+		return "", err
+	}
+
+	return s.encodeID(newID)
+}
+
+// encodeID encodes the id to a short URL. It returns an error if encoded value has an incorrect length.
+func (s PostgreSQLStorage) encodeID(id uint) (string, error) {
+	shortURL := s.idEncoder.EncodeID(id, s.shortURLLength)
+	if len(shortURL) != int(s.shortURLLength) {
+		return "", fmt.Errorf("unexpected length of encoded url, expected=%d, actual=%d", s.shortURLLength, len(shortURL))
+	}
+
+	return shortURL, nil
+}
+
+// insertLink saves the link if its short URL is not saved yet or its saved mapping has expired.
+// Expired mapping is deleted before inserting, so its click statistics are deleted too.
+// It returns false if the short URL was already saved.
+func (s PostgreSQLStorage) insertLink(ctx context.Context, link urlstore.Link, tx pgx.Tx) (bool, error) {
+	const deleteExpiredSQL = `
+		DELETE FROM short_urls
+		WHERE url = $1 AND expires_at <= now();
+	`
+	const insertSQL = `
+		INSERT INTO short_urls (original_url, url, reusable, expires_at, disabled, owner)
+		VALUES ($1, $2, FALSE, $3, $4, $5)
+		ON CONFLICT (url) DO NOTHING;
+	`
+	if _, err := tx.Exec(ctx, deleteExpiredSQL, link.ShortURL); err != nil {
+		return false, err
+	}
+
+	tag, err := tx.Exec(ctx, insertSQL, link.OriginalURL, link.ShortURL, nullableTime(link.ExpiresAt), link.Disabled, link.Owner)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() != 0, nil
+}
+
+func (s PostgreSQLStorage) checkLinkMapping(ctx context.Context, link urlstore.Link, tx pgx.Tx) error {
+	const sql = `
+		SELECT original_url, owner FROM short_urls
+		WHERE url = $1;
+	`
+
+	var savedURL, savedOwner string
+	if err := tx.QueryRow(ctx, sql, link.ShortURL).Scan(&savedURL, &savedOwner); err != nil {
+		return fmt.Errorf("failed to get %q short url from db: %w", link.ShortURL, err)
+	}
+
+	if savedURL != link.OriginalURL || savedOwner != link.Owner {
+		return fmt.Errorf("%w: %q in db", urlstore.ErrShortURLTaken, link.ShortURL)
+	}
+
+	return nil
+}
+
+func scanLink(row pgx.CollectableRow) (urlstore.Link, error) {
+	var (
+		link      urlstore.Link
+		expiresAt *time.Time
+	)
+	if err := row.Scan(&link.ShortURL, &link.OriginalURL, &expiresAt, &link.Disabled, &link.Owner); err != nil {
+		return urlstore.Link{}, err
+	}
+
+	if expiresAt != nil {
+		link.ExpiresAt = *expiresAt
+	}
+
+	return link, nil
+}
+
+func uniqueValues(values []string) []string {
+	unique := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if _, isSeen := seen[value]; isSeen {
+			continue
+		}
+
+		seen[value] = struct{}{}
+		unique = append(unique, value)
+	}
+
+	return unique
+}
+
+func missingKeys(keys []string, values map[string]string) []string {
+	missing := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, isFound := values[key]; !isFound {
+			missing = append(missing, key)
+		}
+	}
+
+	return missing
+}
+
+func notFoundError(shortURL string) error {
+	return fmt.Errorf("%w: %q in db", urlstore.ErrNotFound, shortURL)
+}
+
+// nullableTime returns nil for zero time to save it as NULL.
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// Ping acquires a connection from the pool and checks that the database responds.
+func (s PostgreSQLStorage) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
 }
@@ -0,0 +1,52 @@
+package dbstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/migrations"
+	"shorturl/internal/pgtest"
+	"shorturl/internal/urlservice/storagetest"
+)
+
+func TestMain(m *testing.M) {
+	pgtest.Main(m)
+}
+
+// openTestPool connects to the test database and applies migrations of the scheme.
+// The pool is closed when the test ends, the test is skipped if the database is unavailable.
+func openTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, pgtest.DatabaseURL(t))
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	migrator, err := migrations.New(pool)
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	return pool
+}
+
+// newTestStorage returns a storage over the empty test database.
+func newTestStorage(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) storagetest.Storage {
+	t.Helper()
+
+	pool := openTestPool(t)
+	_, err := pool.Exec(context.Background(),
+		"TRUNCATE original_urls, short_urls, url_stats, url_click_counts, short_url_changes RESTART IDENTITY CASCADE")
+	require.NoError(t, err)
+
+	return NewPostgreSQLStorage(pool, idEncoder, shortURLLength)
+}
+
+func TestPostgreSQLStorage_Contract(t *testing.T) {
+	storagetest.Run(t, newTestStorage)
+}
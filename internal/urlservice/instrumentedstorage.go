@@ -0,0 +1,180 @@
+package urlservice
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"shorturl/internal/urlservice/boltstore"
+	"shorturl/internal/urlservice/dbstore"
+	"shorturl/internal/urlservice/redisstore"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// storageName returns the name of storage used as a label of its metrics and spans.
+func storageName(storage urlStorage) string {
+	switch storage.(type) {
+	case inMemoryURLStorageAdapter:
+		return "memstore"
+	case *dbstore.PostgreSQLStorage:
+		return "dbstore"
+	case *redisstore.RedisStorage:
+		return "redisstore"
+	case *boltstore.BoltStorage:
+		return "boltstore"
+	default:
+		return "unknown"
+	}
+}
+
+// instrumentedStorage is a decorator of urlStorage that records durations of all its operations
+// if metrics are set and traces them if tracer is set.
+type instrumentedStorage struct {
+	storage urlStorage
+	name    string
+	metrics Metrics
+	tracer  trace.Tracer
+}
+
+// instrumentedStorage returns the decorator of the service storage, wrapping the storage on the first call.
+// If the storage is cached, the storage behind the cache is wrapped, so only operations of the storage
+// itself are recorded.
+func (s *ShortURLService) instrumentedStorage() *instrumentedStorage {
+	target := &s.storage
+	if cached, ok := s.storage.(*cachedStorage); ok {
+		target = &cached.urlStorage
+	}
+
+	if storage, ok := (*target).(*instrumentedStorage); ok {
+		return storage
+	}
+
+	storage := &instrumentedStorage{
+		storage: *target,
+		name:    storageName(*target),
+	}
+
+	*target = storage
+	return storage
+}
+
+// observe calls the operation in a span and records its duration and result.
+func observe[T any](ctx context.Context, s *instrumentedStorage, operation string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var span trace.Span
+	if s.tracer != nil {
+		ctx, span = s.tracer.Start(ctx, s.name+"."+operation, trace.WithAttributes(attribute.String("storage", s.name)))
+	}
+
+	startingTime := time.Now()
+	result, err := fn(ctx)
+	if s.metrics != nil {
+		s.metrics.ObserveStorageOperation(s.name, operation, time.Since(startingTime), err)
+	}
+
+	if span != nil {
+		endSpan(span, err)
+	}
+
+	return result, err
+}
+
+func observeError(ctx context.Context, s *instrumentedStorage, operation string, fn func(ctx context.Context) error) error {
+	_, err := observe(ctx, s, operation, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+
+	return err
+}
+
+func (s *instrumentedStorage) Link(ctx context.Context, shortURL string) (urlstore.Link, error) {
+	return observe(ctx, s, "link", func(ctx context.Context) (urlstore.Link, error) {
+		return s.storage.Link(ctx, shortURL)
+	})
+}
+
+func (s *instrumentedStorage) ShortURL(ctx context.Context, owner, originalURL string) (string, error) {
+	return observe(ctx, s, "short_url", func(ctx context.Context) (string, error) {
+		return s.storage.ShortURL(ctx, owner, originalURL)
+	})
+}
+
+func (s *instrumentedStorage) AddLink(ctx context.Context, link urlstore.Link) (string, error) {
+	return observe(ctx, s, "add_link", func(ctx context.Context) (string, error) {
+		return s.storage.AddLink(ctx, link)
+	})
+}
+
+func (s *instrumentedStorage) ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	return observe(ctx, s, "short_urls", func(ctx context.Context) ([]string, error) {
+		return s.storage.ShortURLs(ctx, owner, originalURLs)
+	})
+}
+
+func (s *instrumentedStorage) Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	return observe(ctx, s, "links", func(ctx context.Context) ([]urlstore.Link, error) {
+		return s.storage.Links(ctx, shortURLs)
+	})
+}
+
+func (s *instrumentedStorage) OwnerLinks(ctx context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	return observe(ctx, s, "owner_links", func(ctx context.Context) ([]urlstore.Link, error) {
+		return s.storage.OwnerLinks(ctx, owner, afterShortURL, limit)
+	})
+}
+
+// ForEachLink records duration of the whole iteration, including calls of fn.
+func (s *instrumentedStorage) ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error {
+	return observeError(ctx, s, "for_each_link", func(ctx context.Context) error {
+		return s.storage.ForEachLink(ctx, fn)
+	})
+}
+
+func (s *instrumentedStorage) DeleteExpired(ctx context.Context, moment time.Time) (int64, error) {
+	return observe(ctx, s, "delete_expired", func(ctx context.Context) (int64, error) {
+		return s.storage.DeleteExpired(ctx, moment)
+	})
+}
+
+func (s *instrumentedStorage) Delete(ctx context.Context, owner, shortURL string) error {
+	return observeError(ctx, s, "delete", func(ctx context.Context) error {
+		return s.storage.Delete(ctx, owner, shortURL)
+	})
+}
+
+func (s *instrumentedStorage) SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error {
+	return observeError(ctx, s, "set_disabled", func(ctx context.Context) error {
+		return s.storage.SetDisabled(ctx, owner, shortURL, disabled)
+	})
+}
+
+func (s *instrumentedStorage) UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error {
+	return observeError(ctx, s, "update_original_url", func(ctx context.Context) error {
+		return s.storage.UpdateOriginalURL(ctx, owner, shortURL, originalURL)
+	})
+}
+
+func (s *instrumentedStorage) History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	return observe(ctx, s, "history", func(ctx context.Context) ([]urlstore.DestinationChange, error) {
+		return s.storage.History(ctx, shortURL)
+	})
+}
+
+func (s *instrumentedStorage) AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error {
+	return observeError(ctx, s, "add_click_stats", func(ctx context.Context) error {
+		return s.storage.AddClickStats(ctx, stats)
+	})
+}
+
+func (s *instrumentedStorage) ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error) {
+	return observe(ctx, s, "click_stats", func(ctx context.Context) (urlstore.ClickStats, error) {
+		return s.storage.ClickStats(ctx, shortURL)
+	})
+}
+
+func (s *instrumentedStorage) Ping(ctx context.Context) error {
+	return observeError(ctx, s, "ping", func(ctx context.Context) error {
+		return s.storage.Ping(ctx)
+	})
+}
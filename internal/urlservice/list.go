@@ -0,0 +1,84 @@
+package urlservice
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	// DefaultPageSize is a count of links in a page if page size is not set.
+	DefaultPageSize = 100
+	// MaxPageSize is the maximum count of links in a single page.
+	MaxPageSize = 1000
+)
+
+var (
+	// ErrOwnerRequired is returned when links are listed without owner.
+	ErrOwnerRequired = errors.New("owner of links is required")
+
+	// ErrInvalidPage is returned when page size is out of range or cursor is not returned by ListShortURLs.
+	ErrInvalidPage = errors.New("invalid page")
+)
+
+// LinkPage is a page of links listed in order of short URLs. NextCursor is used to get
+// the next page, it is empty on the last page.
+type LinkPage struct {
+	Links      []Link
+	NextCursor string
+}
+
+// ListShortURLs returns a page of the owner's links following the cursor, including expired and
+// disabled ones. Pass empty cursor to get the first page and zero size to get DefaultPageSize links.
+//
+// It returns ErrOwnerRequired if the owner is empty and ErrInvalidPage if the size is negative or
+// greater than MaxPageSize, or if the cursor is malformed.
+func (s ShortURLService) ListShortURLs(ctx context.Context, owner, cursor string, size int) (LinkPage, error) {
+	if owner == "" {
+		return LinkPage{}, ErrOwnerRequired
+	}
+
+	if size < 0 || size > MaxPageSize {
+		return LinkPage{}, fmt.Errorf("%w: size must be between 1 and %d", ErrInvalidPage, MaxPageSize)
+	}
+
+	if size == 0 {
+		size = DefaultPageSize
+	}
+
+	afterShortURL, err := decodeCursor(cursor)
+	if err != nil {
+		return LinkPage{}, err
+	}
+
+	links, err := s.storage.OwnerLinks(ctx, owner, afterShortURL, size+1)
+	if err != nil {
+		return LinkPage{}, fmt.Errorf("failed to list short urls: %w", err)
+	}
+
+	page := LinkPage{Links: make([]Link, 0, min(size, len(links)))}
+	for _, link := range links[:min(size, len(links))] {
+		page.Links = append(page.Links, newLink(link))
+	}
+
+	if len(links) > size {
+		page.NextCursor = encodeCursor(links[size-1].ShortURL)
+	}
+
+	return page, nil
+}
+
+// encodeCursor returns an opaque cursor pointing after the short URL.
+func encodeCursor(shortURL string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(shortURL))
+}
+
+func decodeCursor(cursor string) (string, error) {
+	shortURL, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", fmt.Errorf("%w: malformed cursor", ErrInvalidPage)
+	}
+
+	return string(shortURL), nil
+}
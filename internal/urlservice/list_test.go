@@ -0,0 +1,112 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestShortURLService_ListShortURLs(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		OwnerLinks(mock.Anything, "owner", "", 3).
+		Return([]urlstore.Link{
+			{ShortURL: "a", OriginalURL: "first", Owner: "owner"},
+			{ShortURL: "b", OriginalURL: "second", Owner: "owner"},
+			{ShortURL: "c", OriginalURL: "third", Owner: "owner"},
+		}, nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	page, err := sut.ListShortURLs(context.Background(), "owner", "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Link{
+		{ShortURL: "a", OriginalURL: "first", Owner: "owner"},
+		{ShortURL: "b", OriginalURL: "second", Owner: "owner"},
+	}, page.Links)
+	require.NotEmpty(t, page.NextCursor)
+
+	storageMock.EXPECT().
+		OwnerLinks(mock.Anything, "owner", "b", 3).
+		Return([]urlstore.Link{{ShortURL: "c", OriginalURL: "third", Owner: "owner"}}, nil).
+		Once()
+
+	page, err = sut.ListShortURLs(context.Background(), "owner", page.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []Link{{ShortURL: "c", OriginalURL: "third", Owner: "owner"}}, page.Links)
+	assert.Empty(t, page.NextCursor, "Last page should have no cursor")
+}
+
+func TestShortURLService_ListShortURLs_DefaultSize(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		OwnerLinks(mock.Anything, "owner", "", DefaultPageSize+1).
+		Return(nil, nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	page, err := sut.ListShortURLs(context.Background(), "owner", "", 0)
+	require.NoError(t, err)
+	assert.Empty(t, page.Links)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestShortURLService_ListShortURLs_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name        string
+		owner       string
+		cursor      string
+		size        int
+		expectedErr error
+	}{
+		{
+			name:        "empty owner",
+			expectedErr: ErrOwnerRequired,
+		},
+		{
+			name:        "negative size",
+			owner:       "owner",
+			size:        -1,
+			expectedErr: ErrInvalidPage,
+		},
+		{
+			name:        "too large size",
+			owner:       "owner",
+			size:        MaxPageSize + 1,
+			expectedErr: ErrInvalidPage,
+		},
+		{
+			name:        "malformed cursor",
+			owner:       "owner",
+			cursor:      "not base64!",
+			expectedErr: ErrInvalidPage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := ShortURLService{storage: NewMockurlStorage(t)}
+
+			_, err := sut.ListShortURLs(context.Background(), tt.owner, tt.cursor, tt.size)
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestShortURLService_ListShortURLs_StorageError(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		OwnerLinks(mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("some error")).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	_, err := sut.ListShortURLs(context.Background(), "owner", "", 0)
+	assert.Error(t, err)
+}
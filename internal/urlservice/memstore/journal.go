@@ -0,0 +1,434 @@
+package memstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	journalFileName  = "journal.log"
+	snapshotFileName = "snapshot.json"
+)
+
+// JournalSettings are settings of the journal of in-memory storage.
+//
+// Zero SyncInterval means that every change is synced to disk before it is applied,
+// otherwise changes are synced by Run every interval, so changes made after the last sync
+// may be lost on a crash of the system. Snapshots of the storage are written by Run every
+// SnapshotInterval, zero interval disables them until Close.
+type JournalSettings struct {
+	Dir              string
+	SyncInterval     time.Duration
+	SnapshotInterval time.Duration
+}
+
+// Journal keeps changes of in-memory storage on disk, so the storage can be restored on launch.
+//
+// Changes are appended as records to the log, which is compacted by writing a snapshot of the
+// storage and truncating the log. Every record has a sequence number, so records written before
+// the snapshot are skipped on restore even if the log was not truncated due to a crash.
+//
+// The zero value is not useful, you must use OpenJournal to create an instance.
+type Journal struct {
+	settings JournalSettings
+	file     *os.File
+	size     int64
+	seq      uint64
+	isDirty  bool
+	mutex    sync.Mutex
+
+	storage  *InMemoryURLStorage
+	restored snapshot
+	records  []record
+}
+
+// OpenJournal reads the snapshot and the log in the directory, creating it if needed, and opens
+// the log to append records. Read changes are applied to the storage created with WithJournal.
+//
+// A torn record at the end of the log, left by a crash during writing, is discarded.
+// Any other record that can not be read is an error.
+func OpenJournal(settings JournalSettings) (*Journal, error) {
+	if err := os.MkdirAll(settings.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	journal := &Journal{settings: settings}
+	if err := journal.readSnapshot(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(settings.Dir, journalFileName), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	journal.file = file
+	if err := journal.readRecords(); err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+
+	return journal, nil
+}
+
+// Run syncs the log and writes snapshots at intervals of settings until the context is done.
+// Errors are logged, so the next attempt is made anyway.
+func (j *Journal) Run(ctx context.Context) {
+	syncTicks, stopSyncTicker := newTicks(j.settings.SyncInterval)
+	defer stopSyncTicker()
+
+	snapshotTicks, stopSnapshotTicker := newTicks(j.settings.SnapshotInterval)
+	defer stopSnapshotTicker()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-syncTicks:
+			if err := j.Sync(); err != nil {
+				slog.Error("Failed to sync journal", slog.String("error", err.Error()))
+			}
+		case <-snapshotTicks:
+			if err := j.Snapshot(); err != nil {
+				slog.Error("Failed to write snapshot", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+// Sync commits records appended since the last sync to disk.
+func (j *Journal) Sync() error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	return j.sync()
+}
+
+// Snapshot writes a snapshot of the storage and truncates the log. The storage is locked
+// for writing meanwhile. If the log is empty, it does nothing.
+func (j *Journal) Snapshot() error {
+	if j.storage == nil {
+		return nil
+	}
+
+	return j.storage.compact()
+}
+
+// Close writes a snapshot of the storage and closes the log.
+func (j *Journal) Close() error {
+	return errors.Join(j.Snapshot(), j.Sync(), j.file.Close())
+}
+
+// attach sets the storage of the journal and restores it from read changes.
+func (j *Journal) attach(storage *InMemoryURLStorage) {
+	j.storage = storage
+	storage.restore(j.restored)
+	for _, toApply := range j.records {
+		storage.apply(toApply)
+	}
+
+	j.restored, j.records = snapshot{}, nil
+}
+
+// append writes records to the log in a single write. If writing fails, the log is truncated
+// to its previous size, so it does not end with a torn record.
+func (j *Journal) append(records []record) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	seq := j.seq
+	for _, toWrite := range records {
+		seq++
+		toWrite.Seq = seq
+		if err := encoder.Encode(toWrite); err != nil {
+			return fmt.Errorf("failed to encode journal record: %w", err)
+		}
+	}
+
+	if _, err := j.file.Write(buffer.Bytes()); err != nil {
+		return errors.Join(fmt.Errorf("failed to write journal: %w", err), j.file.Truncate(j.size))
+	}
+
+	j.seq = seq
+	j.size += int64(buffer.Len())
+	j.isDirty = true
+	if j.settings.SyncInterval == 0 {
+		return j.sync()
+	}
+
+	return nil
+}
+
+// writeSnapshot replaces the snapshot with the state and truncates the log.
+// The storage must be locked by caller, so no records are appended meanwhile.
+func (j *Journal) writeSnapshot(state snapshot) error {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.size == 0 {
+		return nil
+	}
+
+	state.Seq = j.seq
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	path := filepath.Join(j.settings.Dir, snapshotFileName)
+	if err := writeFileAtomically(path, raw); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate journal: %w", err)
+	}
+
+	j.size = 0
+	j.isDirty = true
+	return j.sync()
+}
+
+func (j *Journal) sync() error {
+	if !j.isDirty {
+		return nil
+	}
+
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync journal: %w", err)
+	}
+
+	j.isDirty = false
+	return nil
+}
+
+func (j *Journal) readSnapshot() error {
+	raw, err := os.ReadFile(filepath.Join(j.settings.Dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(raw, &j.restored); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+
+	j.seq = j.restored.Seq
+	return nil
+}
+
+// readRecords reads records of the log written after the snapshot.
+func (j *Journal) readRecords() error {
+	reader := bufio.NewReader(j.file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) != 0 {
+				slog.Warn("Torn record at the end of journal is discarded", slog.Int64("offset", j.size))
+				return j.file.Truncate(j.size)
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to read journal: %w", err)
+		}
+
+		var read record
+		if err := json.Unmarshal(line, &read); err != nil {
+			return fmt.Errorf("failed to decode journal record at offset %d: %w", j.size, err)
+		}
+
+		j.size += int64(len(line))
+		if read.Seq <= j.seq {
+			continue
+		}
+
+		j.seq = read.Seq
+		j.records = append(j.records, read)
+	}
+}
+
+// writeFileAtomically writes data to a temporary file and renames it to the path,
+// so the file at the path is either old or new one after a crash.
+func writeFileAtomically(path string, data []byte) error {
+	temporaryPath := path + ".tmp"
+	file, err := os.OpenFile(temporaryPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if err = errors.Join(err, file.Close()); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temporaryPath, path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+
+	return errors.Join(dir.Sync(), dir.Close())
+}
+
+// newTicks returns a channel of ticks every interval and a function stopping them.
+// If interval is not positive, the channel is nil, so it never ticks.
+func newTicks(interval time.Duration) (<-chan time.Time, func()) {
+	if interval <= 0 {
+		return nil, func() {}
+	}
+
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+type operation string
+
+const (
+	operationSetLink           operation = "set_link"
+	operationDelete            operation = "delete"
+	operationSetDisabled       operation = "set_disabled"
+	operationUpdateOriginalURL operation = "update_original_url"
+	operationAddClickStats     operation = "add_click_stats"
+)
+
+// record is a change of the storage. Fields are set depending on the operation.
+type record struct {
+	Seq       uint64       `json:"seq"`
+	Operation operation    `json:"op"`
+	Link      *savedLink   `json:"link,omitempty"`
+	CurrentID uint         `json:"current_id,omitempty"`
+	ShortURL  string       `json:"short_url,omitempty"`
+	Disabled  bool         `json:"disabled,omitempty"`
+	Change    *savedChange `json:"change,omitempty"`
+	Stats     []savedStats `json:"stats,omitempty"`
+}
+
+// snapshot is a state of the storage after applying records up to Seq.
+type snapshot struct {
+	Seq       uint64        `json:"seq"`
+	CurrentID uint          `json:"current_id"`
+	Links     []savedLink   `json:"links"`
+	Changes   []savedChange `json:"changes,omitempty"`
+	Stats     []savedStats  `json:"stats,omitempty"`
+}
+
+// savedLink is a link with a flag of being returned by ShortURL for its original URL.
+type savedLink struct {
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url"`
+	Owner       string    `json:"owner,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Disabled    bool      `json:"disabled,omitempty"`
+	Reusable    bool      `json:"reusable,omitempty"`
+}
+
+type savedChange struct {
+	ShortURL    string    `json:"short_url"`
+	PreviousURL string    `json:"previous_url"`
+	NewURL      string    `json:"new_url"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+type savedStats struct {
+	ShortURL    string                      `json:"short_url"`
+	Clicks      int64                       `json:"clicks"`
+	LastClickAt time.Time                   `json:"last_click_at"`
+	Counts      map[string]map[string]int64 `json:"counts,omitempty"`
+}
+
+func setLinkRecord(link urlstore.Link, isReusable bool, currentID uint) record {
+	return record{
+		Operation: operationSetLink,
+		Link: &savedLink{
+			ShortURL:    link.ShortURL,
+			OriginalURL: link.OriginalURL,
+			Owner:       link.Owner,
+			ExpiresAt:   link.ExpiresAt,
+			Disabled:    link.Disabled,
+			Reusable:    isReusable,
+		},
+		CurrentID: currentID,
+	}
+}
+
+func (l savedLink) link() urlstore.Link {
+	return urlstore.Link{
+		ShortURL:    l.ShortURL,
+		OriginalURL: l.OriginalURL,
+		Owner:       l.Owner,
+		ExpiresAt:   l.ExpiresAt,
+		Disabled:    l.Disabled,
+	}
+}
+
+func newSavedChange(change urlstore.DestinationChange) *savedChange {
+	return &savedChange{
+		ShortURL:    change.ShortURL,
+		PreviousURL: change.PreviousURL,
+		NewURL:      change.NewURL,
+		ChangedAt:   change.ChangedAt,
+	}
+}
+
+func (c savedChange) change() urlstore.DestinationChange {
+	return urlstore.DestinationChange{
+		ShortURL:    c.ShortURL,
+		PreviousURL: c.PreviousURL,
+		NewURL:      c.NewURL,
+		ChangedAt:   c.ChangedAt,
+	}
+}
+
+func newSavedStats(stats urlstore.ClickStats) savedStats {
+	saved := savedStats{
+		ShortURL:    stats.ShortURL,
+		Clicks:      stats.Clicks,
+		LastClickAt: stats.LastClickAt,
+		Counts:      make(map[string]map[string]int64, len(stats.Counts)),
+	}
+
+	for dimension, counts := range stats.Counts {
+		saved.Counts[string(dimension)] = counts
+	}
+
+	return saved
+}
+
+func (s savedStats) stats() urlstore.ClickStats {
+	stats := urlstore.NewClickStats(s.ShortURL)
+	stats.Clicks = s.Clicks
+	stats.LastClickAt = s.LastClickAt
+	for dimension, counts := range s.Counts {
+		for value, clicks := range counts {
+			stats.AddCount(urlstore.ClickDimension(dimension), value, clicks)
+		}
+	}
+
+	return stats
+}
@@ -0,0 +1,192 @@
+package memstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// storageState is everything saved in the storage, used to compare restored storages.
+// Times are compared in UTC without monotonic clock readings.
+type storageState struct {
+	links    []urlstore.Link
+	reusable map[ownedURL]string
+	changes  map[string][]urlstore.DestinationChange
+	stats    map[string]urlstore.ClickStats
+	id       uint
+}
+
+func stateOf(t *testing.T, storage *InMemoryURLStorage) storageState {
+	t.Helper()
+
+	state := storageState{
+		links:    storage.snapshot(),
+		reusable: storage.encodedByOriginalURLs,
+		changes:  make(map[string][]urlstore.DestinationChange),
+		stats:    make(map[string]urlstore.ClickStats),
+		id:       storage.currentID,
+	}
+
+	for i, link := range state.links {
+		state.links[i].ExpiresAt = link.ExpiresAt.Round(0).UTC()
+		for _, change := range storage.History(link.ShortURL) {
+			change.ChangedAt = change.ChangedAt.Round(0).UTC()
+			state.changes[link.ShortURL] = append(state.changes[link.ShortURL], change)
+		}
+
+		if stats := storage.ClickStats(link.ShortURL); stats.Clicks != 0 {
+			stats.LastClickAt = stats.LastClickAt.UTC()
+			state.stats[link.ShortURL] = stats
+		}
+	}
+
+	return state
+}
+
+func openTestJournal(t *testing.T, dir string) *Journal {
+	t.Helper()
+
+	journal, err := OpenJournal(JournalSettings{Dir: dir})
+	require.NoError(t, err)
+
+	return journal
+}
+
+// fillStorage makes every kind of change in the storage.
+func fillStorage(t *testing.T, storage *InMemoryURLStorage) {
+	t.Helper()
+
+	updated, err := storage.ShortURL("", "https://example.com")
+	require.NoError(t, err)
+	owned, err := storage.ShortURL("owner", "https://example.com")
+	require.NoError(t, err)
+	_, err = storage.ShortURLs("owner", []string{"https://example.org", "https://example.net"})
+	require.NoError(t, err)
+	_, err = storage.AddLink(urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com/alias", ExpiresAt: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	_, err = storage.AddLink(urlstore.Link{ShortURL: "expired", OriginalURL: "https://example.com/expired", ExpiresAt: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	deleted, err := storage.AddLink(urlstore.Link{OriginalURL: "https://example.com/deleted", Owner: "owner"})
+	require.NoError(t, err)
+
+	require.NoError(t, storage.SetDisabled("owner", owned, true))
+	require.NoError(t, storage.UpdateOriginalURL("", updated, "https://example.com/updated"))
+	stats := urlstore.NewClickStats(owned)
+	stats.Clicks, stats.LastClickAt = 3, time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	stats.AddCount(urlstore.DimensionDay, "2024-03-02", 3)
+	require.NoError(t, storage.AddClickStats([]urlstore.ClickStats{stats}))
+	require.NoError(t, storage.Delete("owner", deleted))
+	deletedCount, err := storage.DeleteExpired(time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, deletedCount)
+}
+
+func TestJournal_RestoresFromLog(t *testing.T) {
+	dir := t.TempDir()
+	idEncoder := encoder.NewIDEncoder()
+	journal := openTestJournal(t, dir)
+	original := NewInMemoryURLStorage(idEncoder, 10, WithJournal(journal))
+	fillStorage(t, original)
+	require.NoError(t, journal.file.Close(), "Journal is closed without snapshot like on a crash")
+
+	journal = openTestJournal(t, dir)
+	defer journal.Close()
+	restored := NewInMemoryURLStorage(idEncoder, 10, WithJournal(journal))
+
+	assert.Equal(t, stateOf(t, original), stateOf(t, restored))
+}
+
+func TestJournal_RestoresFromSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	idEncoder := encoder.NewIDEncoder()
+	journal := openTestJournal(t, dir)
+	original := NewInMemoryURLStorage(idEncoder, 10, WithJournal(journal))
+	fillStorage(t, original)
+	require.NoError(t, journal.Close())
+
+	info, err := os.Stat(filepath.Join(dir, journalFileName))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size(), "Journal is not truncated after snapshot")
+
+	journal = openTestJournal(t, dir)
+	defer journal.Close()
+	restored := NewInMemoryURLStorage(idEncoder, 10, WithJournal(journal))
+
+	assert.Equal(t, stateOf(t, original), stateOf(t, restored))
+
+	shortURL, err := restored.ShortURL("", "https://example.com/new")
+	require.NoError(t, err)
+	assert.Equal(t, idEncoder.EncodeID(original.currentID+1, 10), shortURL, "Ids are reused after restore")
+}
+
+func TestJournal_SkipsRecordsBeforeSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	journal := openTestJournal(t, dir)
+	storage := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10, WithJournal(journal))
+	shortURL, err := storage.ShortURL("", "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, storage.AddClickStats([]urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1}}))
+
+	logPath := filepath.Join(dir, journalFileName)
+	log, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+	require.NoError(t, journal.Close())
+	require.NoError(t, os.WriteFile(logPath, log, 0o600), "Journal is not truncated after snapshot like on a crash")
+
+	journal = openTestJournal(t, dir)
+	defer journal.Close()
+	restored := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10, WithJournal(journal))
+
+	assert.EqualValues(t, 1, restored.ClickStats(shortURL).Clicks)
+}
+
+func TestJournal_DiscardsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	journal := openTestJournal(t, dir)
+	storage := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10, WithJournal(journal))
+	shortURL, err := storage.ShortURL("", "https://example.com")
+	require.NoError(t, err)
+	_, err = journal.file.WriteString(`{"seq":2,"op":"set_li`)
+	require.NoError(t, err)
+	require.NoError(t, journal.file.Close())
+
+	journal = openTestJournal(t, dir)
+	defer journal.Close()
+	restored := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10, WithJournal(journal))
+
+	link, err := restored.Link(shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", link.OriginalURL)
+
+	_, err = restored.ShortURL("", "https://example.org")
+	require.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, journalFileName))
+	require.NoError(t, err)
+	assert.Equal(t, journal.size, info.Size(), "Torn record is not truncated")
+}
+
+func TestJournal_FailsOnCorruptedRecord(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, journalFileName), []byte("not json\n"), 0o600))
+
+	_, err := OpenJournal(JournalSettings{Dir: dir})
+	assert.Error(t, err)
+}
+
+func TestJournal_WriteFailureKeepsStorage(t *testing.T) {
+	journal := openTestJournal(t, t.TempDir())
+	storage := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10, WithJournal(journal))
+	require.NoError(t, journal.file.Close())
+
+	_, err := storage.ShortURL("", "https://example.com")
+	assert.Error(t, err)
+	assert.Empty(t, storage.originalByEncodedURLs)
+	assert.Empty(t, storage.encodedByOriginalURLs)
+}
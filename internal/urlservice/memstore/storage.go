@@ -1,59 +1,108 @@
 // Package memstore provides an in-memory URL storage.
 //
 // It allows for the storing and retrieval of original URLs using
-// encoded keys. It's safe for concurrent use.
+// encoded keys. It's safe for concurrent use. Optionally, changes
+// are written to a journal on disk and restored on launch.
 package memstore
 
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
 )
 
 // InMemoryURLStorage is an in-memory storage for URLs.
 //
-// It maps both original URL by encoded URLs and encoded urls by original URLs.
-// This is needed for fast search both values.
+// It maps both original URL by encoded URLs and encoded urls by owned original URLs.
+// This is needed for fast search both values. Links added with AddLink are mapped
+// only by their short URLs, because they are never reused for the original URL.
+// Owners are mapped by short URLs of owned links only.
+// Expiration times are mapped by short URLs of expiring links only, click statistics
+// are mapped by short URLs of clicked links only. Short URLs of disabled links are kept in a set.
+// Changes of original URLs are mapped by short URLs of changed links only.
 // Encoding depends on URL id, so it also stores the current value of incrementing id.
 //
+// All changes are made by applying records, so if the journal is set, they are written
+// to it before they are applied and the same records restore the storage on launch.
+//
 // The zero value is not useful, you must use NewInMemoryURLStorage to create an instance.
 type InMemoryURLStorage struct {
-	originalByEncodedURLs map[string]string
-	encodedByOriginalURLs map[string]string
-	idEncoder             encoder.IDEncoder
-	currentID             uint
-	shortURLLength        uint
-	mutex                 sync.RWMutex
+	originalByEncodedURLs   map[string]string
+	encodedByOriginalURLs   map[ownedURL]string
+	ownerByEncodedURLs      map[string]string
+	expirationByEncodedURLs map[string]time.Time
+	statsByEncodedURLs      map[string]urlstore.ClickStats
+	disabledEncodedURLs     map[string]struct{}
+	changesByEncodedURLs    map[string][]urlstore.DestinationChange
+	idEncoder               encoder.IDEncoder
+	currentID               uint
+	shortURLLength          uint
+	journal                 *Journal
+	mutex                   sync.RWMutex
 }
 
-// NewInMemoryURLStorage initializes a new InMemoryURLStorage instance with the given ID encoder
-// and the specified length for short URLs. It returns a pointer to created object.
-func NewInMemoryURLStorage(idEncoder encoder.IDEncoder, shortURLLength uint) *InMemoryURLStorage {
-	return &InMemoryURLStorage{
-		idEncoder:             idEncoder,
-		shortURLLength:        shortURLLength,
-		encodedByOriginalURLs: make(map[string]string),
-		originalByEncodedURLs: make(map[string]string),
+// Option is an optional setting of InMemoryURLStorage.
+type Option func(s *InMemoryURLStorage)
+
+// WithJournal returns an option that restores the storage from the journal and writes
+// all following changes to it. The journal must be set to a single storage only.
+func WithJournal(journal *Journal) Option {
+	return func(s *InMemoryURLStorage) {
+		journal.attach(s)
+		s.journal = journal
 	}
 }
 
-// OriginalURL is looking for the original URL by passed short URL.
-// If the short URL does not exist in the storage, it returns an error.
-func (s *InMemoryURLStorage) OriginalURL(shortURL string) (string, error) {
+// NewInMemoryURLStorage initializes a new InMemoryURLStorage instance with the given ID encoder,
+// the specified length for short URLs and optional settings. It returns a pointer to created object.
+func NewInMemoryURLStorage(idEncoder encoder.IDEncoder, shortURLLength uint, options ...Option) *InMemoryURLStorage {
+	storage := &InMemoryURLStorage{
+		idEncoder:               idEncoder,
+		shortURLLength:          shortURLLength,
+		encodedByOriginalURLs:   make(map[ownedURL]string),
+		ownerByEncodedURLs:      make(map[string]string),
+		originalByEncodedURLs:   make(map[string]string),
+		expirationByEncodedURLs: make(map[string]time.Time),
+		statsByEncodedURLs:      make(map[string]urlstore.ClickStats),
+		disabledEncodedURLs:     make(map[string]struct{}),
+		changesByEncodedURLs:    make(map[string][]urlstore.DestinationChange),
+	}
+
+	for _, option := range options {
+		option(storage)
+	}
+
+	return storage
+}
+
+// Link is looking for the link by passed short URL.
+// If the short URL does not exist in the storage, it returns an error wrapping urlstore.ErrNotFound.
+func (s *InMemoryURLStorage) Link(shortURL string) (urlstore.Link, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	originalURL, isFound := s.originalByEncodedURLs[shortURL]
+	link, isFound := s.lookForLink(shortURL)
 	if !isFound {
-		return "", fmt.Errorf("%q url not found in im-memory storage", shortURL)
+		return urlstore.Link{}, notFoundError(shortURL)
 	}
 
-	return originalURL, nil
+	return link, nil
+}
+
+// ownedURL is an original URL shortened by the owner. Every owner gets its own short URL
+// for the same original URL.
+type ownedURL struct {
+	owner       string
+	originalURL string
 }
 
-// ShortURL should always return short URL for provided original URL value.
+// ShortURL should always return the owner's short URL for provided original URL value.
 //
 // At first, it tries to find saved value, but if it does not exist, it encodes the
 // original URL by incremented ID and returns a new value.
@@ -63,47 +112,530 @@ func (s *InMemoryURLStorage) OriginalURL(shortURL string) (string, error) {
 //
 // It might return an error if encoder returns a short URL that already exists in storage
 // or if encoded value has an incorrect length.
-func (s *InMemoryURLStorage) ShortURL(originalURL string) (string, error) {
-	shortURL, isFound := s.lookForShortURL(originalURL)
+func (s *InMemoryURLStorage) ShortURL(owner, originalURL string) (string, error) {
+	toAdd := ownedURL{owner: owner, originalURL: originalURL}
+	shortURL, isFound := s.lookForShortURL(toAdd)
 	if isFound {
 		return shortURL, nil
 	}
 
-	return s.saveNewURL(originalURL)
+	return s.saveNewURL(toAdd)
+}
+
+// ShortURLs returns the owner's short URLs for all provided original URLs in the same order under a single
+// lock acquisition. Like ShortURL, it returns saved values or encodes new ones.
+// If encoding or writing of any short URL fails, it returns an error, but short URLs saved before are kept.
+func (s *InMemoryURLStorage) ShortURLs(owner string, originalURLs []string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	shortURLs := make([]string, 0, len(originalURLs))
+	for _, originalURL := range originalURLs {
+		shortURL, err := s.saveURL(ownedURL{owner: owner, originalURL: originalURL})
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	return shortURLs, nil
+}
+
+// Links is looking for links by passed short URLs under a single lock acquisition.
+// Short URLs that do not exist in the storage are skipped.
+func (s *InMemoryURLStorage) Links(shortURLs []string) []urlstore.Link {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	links := make([]urlstore.Link, 0, len(shortURLs))
+	for _, shortURL := range shortURLs {
+		if link, isFound := s.lookForLink(shortURL); isFound {
+			links = append(links, link)
+		}
+	}
+
+	return links
+}
+
+// AddLink saves the link that is never returned by ShortURL and returns its short URL.
+// If the link has no short URL, it encodes a new one by incremented ID.
+// The link is saved disabled at once if it is disabled.
+//
+// If the short URL is mapped with an expired link, the link is replaced and its click statistics are removed.
+// Otherwise, if it is already mapped with the same original URL of the same owner, it does nothing, the saved
+// link is not changed, and if it is mapped with another original URL or owned by another owner, it returns
+// an error wrapping urlstore.ErrShortURLTaken.
+func (s *InMemoryURLStorage) AddLink(link urlstore.Link) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if link.ShortURL == "" {
+		return s.saveNewLink(link)
+	}
+
+	if savedLink, isSaved := s.lookForLink(link.ShortURL); isSaved && !savedLink.IsExpired(time.Now()) {
+		if savedLink.OriginalURL == link.OriginalURL && savedLink.Owner == link.Owner {
+			return link.ShortURL, nil
+		}
+
+		return "", fmt.Errorf("%w: %q in in-memory storage", urlstore.ErrShortURLTaken, link.ShortURL)
+	}
+
+	if err := s.commit(setLinkRecord(link, false, s.currentID)); err != nil {
+		return "", err
+	}
+
+	return link.ShortURL, nil
+}
+
+// DeleteExpired removes all links that have expired at the moment and returns their count.
+func (s *InMemoryURLStorage) DeleteExpired(moment time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var records []record
+	for shortURL, expiresAt := range s.expirationByEncodedURLs {
+		if moment.Before(expiresAt) {
+			continue
+		}
+
+		records = append(records, record{Operation: operationDelete, ShortURL: shortURL})
+	}
+
+	if err := s.commit(records...); err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
+// Delete removes the link with its click statistics. If the link is returned by ShortURL
+// for its original URL, the next call of ShortURL encodes a new short URL.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *InMemoryURLStorage) Delete(owner, shortURL string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.lookForOwnedLink(owner, shortURL); err != nil {
+		return err
+	}
+
+	return s.commit(record{Operation: operationDelete, ShortURL: shortURL})
+}
+
+// SetDisabled disables or enables the link. Disabled link is still returned by ShortURL
+// for its original URL, so it is not replaced with a new one while it is disabled.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *InMemoryURLStorage) SetDisabled(owner, shortURL string, disabled bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, err := s.lookForOwnedLink(owner, shortURL); err != nil {
+		return err
+	}
+
+	return s.commit(record{Operation: operationSetDisabled, ShortURL: shortURL, Disabled: disabled})
+}
+
+// UpdateOriginalURL maps the short URL with another original URL and records the change.
+// Updated link is not returned by ShortURL for any original URL anymore. Its expiration,
+// status and click statistics are kept. If the original URL is the same, it does nothing.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *InMemoryURLStorage) UpdateOriginalURL(owner, shortURL, originalURL string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	link, err := s.lookForOwnedLink(owner, shortURL)
+	if err != nil {
+		return err
+	}
+
+	if link.OriginalURL == originalURL {
+		return nil
+	}
+
+	return s.commit(record{Operation: operationUpdateOriginalURL, Change: newSavedChange(urlstore.DestinationChange{
+		ShortURL:    shortURL,
+		PreviousURL: link.OriginalURL,
+		NewURL:      originalURL,
+		ChangedAt:   time.Now(),
+	})})
+}
+
+// History returns a copy of changes of original URL mapped with the short URL, the oldest first.
+func (s *InMemoryURLStorage) History(shortURL string) []urlstore.DestinationChange {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	changes := s.changesByEncodedURLs[shortURL]
+	result := make([]urlstore.DestinationChange, len(changes))
+	copy(result, changes)
+
+	return result
+}
+
+// ForEachLink calls fn for every link saved in the storage in order of short URLs. Links are
+// copied under the lock, so fn is called with a snapshot and may use the storage itself.
+// It stops on the first error returned by fn and returns it.
+func (s *InMemoryURLStorage) ForEachLink(fn func(link urlstore.Link) error) error {
+	for _, link := range s.snapshot() {
+		if err := fn(link); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OwnerLinks returns up to limit links of the owner with short URLs greater than
+// the passed one in order of short URLs. Pass empty short URL to get the first page.
+func (s *InMemoryURLStorage) OwnerLinks(owner, afterShortURL string, limit int) []urlstore.Link {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var shortURLs []string
+	for shortURL, linkOwner := range s.ownerByEncodedURLs {
+		if linkOwner == owner && shortURL > afterShortURL {
+			shortURLs = append(shortURLs, shortURL)
+		}
+	}
+
+	slices.Sort(shortURLs)
+	links := make([]urlstore.Link, 0, min(limit, len(shortURLs)))
+	for _, shortURL := range shortURLs[:min(limit, len(shortURLs))] {
+		link, _ := s.lookForLink(shortURL)
+		links = append(links, link)
+	}
+
+	return links
 }
 
-func (s *InMemoryURLStorage) lookForShortURL(originalURL string) (string, bool) {
+// AddClickStats adds clicks to statistics of their short URLs.
+// Clicks of short URLs that do not exist in the storage are ignored.
+func (s *InMemoryURLStorage) AddClickStats(stats []urlstore.ClickStats) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	toAdd := record{Operation: operationAddClickStats}
+	for _, clicks := range stats {
+		if _, isFound := s.originalByEncodedURLs[clicks.ShortURL]; isFound {
+			toAdd.Stats = append(toAdd.Stats, newSavedStats(clicks))
+		}
+	}
+
+	if len(toAdd.Stats) == 0 {
+		return nil
+	}
+
+	return s.commit(toAdd)
+}
+
+// ClickStats returns a copy of click statistics of the short URL.
+// If the short URL has never been clicked, it returns empty statistics.
+func (s *InMemoryURLStorage) ClickStats(shortURL string) urlstore.ClickStats {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	shortURL, isFound := s.encodedByOriginalURLs[originalURL]
+	result := urlstore.NewClickStats(shortURL)
+	if saved, isFound := s.statsByEncodedURLs[shortURL]; isFound {
+		result.Merge(saved)
+	}
+
+	return result
+}
+
+func (s *InMemoryURLStorage) lookForLink(shortURL string) (urlstore.Link, bool) {
+	originalURL, isFound := s.originalByEncodedURLs[shortURL]
+	if !isFound {
+		return urlstore.Link{}, false
+	}
+
+	link := urlstore.Link{
+		ShortURL:    shortURL,
+		OriginalURL: originalURL,
+		ExpiresAt:   s.expirationByEncodedURLs[shortURL],
+		Owner:       s.ownerByEncodedURLs[shortURL],
+	}
+	_, link.Disabled = s.disabledEncodedURLs[shortURL]
+
+	return link, true
+}
+
+// lookForOwnedLink returns the link that may be changed by the owner. It returns an error wrapping
+// urlstore.ErrNotFound if the link is not found or is owned by another owner.
+func (s *InMemoryURLStorage) lookForOwnedLink(owner, shortURL string) (urlstore.Link, error) {
+	link, isFound := s.lookForLink(shortURL)
+	if !isFound || !link.IsOwnedBy(owner) {
+		return urlstore.Link{}, notFoundError(shortURL)
+	}
+
+	return link, nil
+}
+
+func (s *InMemoryURLStorage) snapshot() []urlstore.Link {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	links := make([]urlstore.Link, 0, len(s.originalByEncodedURLs))
+	for shortURL := range s.originalByEncodedURLs {
+		link, _ := s.lookForLink(shortURL)
+		links = append(links, link)
+	}
+
+	slices.SortFunc(links, func(a, b urlstore.Link) int {
+		return strings.Compare(a.ShortURL, b.ShortURL)
+	})
+
+	return links
+}
+
+// commit writes records to the journal, if it is set, and applies them. Nothing is applied
+// if writing fails. Mutex must be locked by caller.
+func (s *InMemoryURLStorage) commit(records ...record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	if s.journal != nil {
+		if err := s.journal.append(records); err != nil {
+			return err
+		}
+	}
+
+	for _, toApply := range records {
+		s.apply(toApply)
+	}
+
+	return nil
+}
+
+// apply makes the change of the record. Mutex must be locked by caller.
+func (s *InMemoryURLStorage) apply(toApply record) {
+	switch toApply.Operation {
+	case operationSetLink:
+		link := toApply.Link.link()
+		s.deleteLink(link.ShortURL)
+		s.setLink(link)
+		if toApply.Link.Reusable {
+			s.encodedByOriginalURLs[ownedURL{owner: link.Owner, originalURL: link.OriginalURL}] = link.ShortURL
+		}
+
+		s.currentID = max(s.currentID, toApply.CurrentID)
+	case operationDelete:
+		s.deleteLink(toApply.ShortURL)
+	case operationSetDisabled:
+		if toApply.Disabled {
+			s.disabledEncodedURLs[toApply.ShortURL] = struct{}{}
+		} else {
+			delete(s.disabledEncodedURLs, toApply.ShortURL)
+		}
+	case operationUpdateOriginalURL:
+		s.updateOriginalURL(toApply.Change.change())
+	case operationAddClickStats:
+		for _, toAdd := range toApply.Stats {
+			s.addClickStats(toAdd.stats())
+		}
+	}
+}
+
+func (s *InMemoryURLStorage) updateOriginalURL(change urlstore.DestinationChange) {
+	previous := ownedURL{owner: s.ownerByEncodedURLs[change.ShortURL], originalURL: change.PreviousURL}
+	if s.encodedByOriginalURLs[previous] == change.ShortURL {
+		delete(s.encodedByOriginalURLs, previous)
+	}
+
+	s.originalByEncodedURLs[change.ShortURL] = change.NewURL
+	s.changesByEncodedURLs[change.ShortURL] = append(s.changesByEncodedURLs[change.ShortURL], change)
+}
+
+func (s *InMemoryURLStorage) addClickStats(toAdd urlstore.ClickStats) {
+	if _, isFound := s.originalByEncodedURLs[toAdd.ShortURL]; !isFound {
+		return
+	}
+
+	saved, isFound := s.statsByEncodedURLs[toAdd.ShortURL]
+	if !isFound {
+		saved = urlstore.NewClickStats(toAdd.ShortURL)
+	}
+
+	saved.Merge(toAdd)
+	s.statsByEncodedURLs[toAdd.ShortURL] = saved
+}
+
+// restore sets the state of the snapshot. It is called before the storage is used, so it is not locked.
+func (s *InMemoryURLStorage) restore(state snapshot) {
+	s.currentID = state.CurrentID
+	for _, saved := range state.Links {
+		link := saved.link()
+		s.setLink(link)
+		if saved.Reusable {
+			s.encodedByOriginalURLs[ownedURL{owner: link.Owner, originalURL: link.OriginalURL}] = link.ShortURL
+		}
+	}
+
+	for _, change := range state.Changes {
+		s.changesByEncodedURLs[change.ShortURL] = append(s.changesByEncodedURLs[change.ShortURL], change.change())
+	}
+
+	for _, stats := range state.Stats {
+		s.statsByEncodedURLs[stats.ShortURL] = stats.stats()
+	}
+}
+
+// compact writes the snapshot of the storage to the journal under the lock.
+func (s *InMemoryURLStorage) compact() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := snapshot{
+		CurrentID: s.currentID,
+		Links:     make([]savedLink, 0, len(s.originalByEncodedURLs)),
+	}
+
+	for shortURL := range s.originalByEncodedURLs {
+		link, _ := s.lookForLink(shortURL)
+		state.Links = append(state.Links, savedLink{
+			ShortURL:    shortURL,
+			OriginalURL: link.OriginalURL,
+			Owner:       link.Owner,
+			ExpiresAt:   link.ExpiresAt,
+			Disabled:    link.Disabled,
+			Reusable:    s.encodedByOriginalURLs[ownedURL{owner: link.Owner, originalURL: link.OriginalURL}] == shortURL,
+		})
+	}
+
+	for _, changes := range s.changesByEncodedURLs {
+		for _, change := range changes {
+			state.Changes = append(state.Changes, *newSavedChange(change))
+		}
+	}
+
+	for _, stats := range s.statsByEncodedURLs {
+		state.Stats = append(state.Stats, newSavedStats(stats))
+	}
+
+	return s.journal.writeSnapshot(state)
+}
+
+// deleteLink removes the link and everything mapped by its short URL. Mutex must be locked by caller.
+func (s *InMemoryURLStorage) deleteLink(shortURL string) {
+	originalURL, isFound := s.originalByEncodedURLs[shortURL]
+	owned := ownedURL{owner: s.ownerByEncodedURLs[shortURL], originalURL: originalURL}
+	if isFound && s.encodedByOriginalURLs[owned] == shortURL {
+		delete(s.encodedByOriginalURLs, owned)
+	}
+
+	delete(s.originalByEncodedURLs, shortURL)
+	delete(s.ownerByEncodedURLs, shortURL)
+	delete(s.expirationByEncodedURLs, shortURL)
+	delete(s.statsByEncodedURLs, shortURL)
+	delete(s.disabledEncodedURLs, shortURL)
+	delete(s.changesByEncodedURLs, shortURL)
+}
+
+func (s *InMemoryURLStorage) setLink(link urlstore.Link) {
+	s.originalByEncodedURLs[link.ShortURL] = link.OriginalURL
+	s.setOwner(link.ShortURL, link.Owner)
+	if link.Disabled {
+		s.disabledEncodedURLs[link.ShortURL] = struct{}{}
+	}
+
+	if link.ExpiresAt.IsZero() {
+		delete(s.expirationByEncodedURLs, link.ShortURL)
+		return
+	}
+
+	s.expirationByEncodedURLs[link.ShortURL] = link.ExpiresAt
+}
+
+func (s *InMemoryURLStorage) setOwner(shortURL, owner string) {
+	if owner == "" {
+		delete(s.ownerByEncodedURLs, shortURL)
+		return
+	}
+
+	s.ownerByEncodedURLs[shortURL] = owner
+}
+
+func (s *InMemoryURLStorage) lookForShortURL(owned ownedURL) (string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	shortURL, isFound := s.encodedByOriginalURLs[owned]
 	return shortURL, isFound
 }
 
-func (s *InMemoryURLStorage) saveNewURL(toAdd string) (string, error) {
+func (s *InMemoryURLStorage) saveNewURL(toAdd ownedURL) (string, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	return s.saveURL(toAdd)
+}
+
+// saveURL returns the owner's saved short URL for the original URL or encodes and saves a new one.
+// Mutex must be locked by caller.
+func (s *InMemoryURLStorage) saveURL(toAdd ownedURL) (string, error) {
 	if shortURL, isAddedAlready := s.encodedByOriginalURLs[toAdd]; isAddedAlready {
 		return shortURL, nil
 	}
 
-	s.currentID++
-	newShortURL := s.idEncoder.EncodeID(s.currentID, s.shortURLLength)
-	if err := s.checkResultLength(newShortURL); err != nil {
+	newShortURL, err := s.encodeNewShortURL()
+	if err != nil {
 		return "", err
 	}
 
-	if err := s.checkIfResultUnique(newShortURL); err != nil {
+	link := urlstore.Link{ShortURL: newShortURL, OriginalURL: toAdd.originalURL, Owner: toAdd.owner}
+	if err := s.commit(setLinkRecord(link, true, s.currentID)); err != nil {
 		return "", err
 	}
 
-	s.originalByEncodedURLs[newShortURL] = toAdd
-	s.encodedByOriginalURLs[toAdd] = newShortURL
+	return newShortURL, nil
+}
+
+// saveNewLink encodes a new short URL for the link and saves it. Mutex must be locked by caller.
+func (s *InMemoryURLStorage) saveNewLink(link urlstore.Link) (string, error) {
+	newShortURL, err := s.encodeNewShortURL()
+	if err != nil {
+		return "", err
+	}
+
+	link.ShortURL = newShortURL
+	if err := s.commit(setLinkRecord(link, false, s.currentID)); err != nil {
+		return "", err
+	}
 
 	return newShortURL, nil
 }
 
+// maxEncodingAttempts limits count of ids tried to encode a short URL that is not saved yet.
+const maxEncodingAttempts = 100
+
+// encodeNewShortURL increments ID and encodes it. Short URLs that are already saved,
+// for example imported ones, are skipped. Mutex must be locked by caller.
+//
+// It returns an error if encoded value has an incorrect length or if all attempts
+// returned short URLs that are already saved.
+func (s *InMemoryURLStorage) encodeNewShortURL() (string, error) {
+	var err error
+	for range maxEncodingAttempts {
+		s.currentID++
+		newShortURL := s.idEncoder.EncodeID(s.currentID, s.shortURLLength)
+		if err := s.checkResultLength(newShortURL); err != nil {
+			return "", err
+		}
+
+		if err = s.checkIfResultUnique(newShortURL); err == nil {
+			return newShortURL, nil
+		}
+	}
+
+	return "", err
+}
+
 func (s *InMemoryURLStorage) checkIfResultUnique(shortURL string) error {
 	if _, containsShortURL := s.originalByEncodedURLs[shortURL]; containsShortURL {
 		return errors.New("encoded url is not unique")
@@ -112,6 +644,10 @@ func (s *InMemoryURLStorage) checkIfResultUnique(shortURL string) error {
 	return nil
 }
 
+func notFoundError(shortURL string) error {
+	return fmt.Errorf("%w: %q in in-memory storage", urlstore.ErrNotFound, shortURL)
+}
+
 func (s *InMemoryURLStorage) checkResultLength(shortURL string) error {
 	if len(shortURL) != int(s.shortURLLength) {
 		return fmt.Errorf("unexpected length of encoded url, expected=%d, actual=%d", s.shortURLLength, len(shortURL))
@@ -1,12 +1,15 @@
 package memstore
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
 )
 
 type encoderStub struct{}
@@ -24,21 +27,33 @@ func TestNewInMemoryURLStorage(t *testing.T) {
 	assert.Equal(t, shortURLLength, result.shortURLLength)
 	assert.NotNil(t, result.originalByEncodedURLs, "Map originals was not init")
 	assert.NotNil(t, result.encodedByOriginalURLs, "Map shorts was not init")
+	assert.NotNil(t, result.expirationByEncodedURLs, "Map expirations was not init")
 }
 
-func TestInMemoryURLStorage_OriginalURL(t *testing.T) {
+func TestInMemoryURLStorage_Link(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
 	tests := []struct {
 		name           string
 		originals      map[string]string
+		expirations    map[string]time.Time
 		shortURL       string
-		expectedResult string
+		expectedResult urlstore.Link
 		requireError   require.ErrorAssertionFunc
 	}{
 		{
 			name:           "short url exist",
 			originals:      map[string]string{"short": "original"},
 			shortURL:       "short",
-			expectedResult: "original",
+			expectedResult: urlstore.Link{ShortURL: "short", OriginalURL: "original"},
+			requireError:   require.NoError,
+		},
+		{
+			name:           "expiring short url exist",
+			originals:      map[string]string{"short": "original"},
+			expirations:    map[string]time.Time{"short": expiresAt},
+			shortURL:       "short",
+			expectedResult: urlstore.Link{ShortURL: "short", OriginalURL: "original", ExpiresAt: expiresAt},
 			requireError:   require.NoError,
 		},
 		{
@@ -53,8 +68,11 @@ func TestInMemoryURLStorage_OriginalURL(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			sut := NewInMemoryURLStorage(encoderStub{}, 10)
 			sut.originalByEncodedURLs = tt.originals
+			if tt.expirations != nil {
+				sut.expirationByEncodedURLs = tt.expirations
+			}
 
-			result, err := sut.OriginalURL(tt.shortURL)
+			result, err := sut.Link(tt.shortURL)
 			tt.requireError(t, err)
 			if err != nil {
 				return
@@ -70,7 +88,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 		name           string
 		shortURLLength int
 		originals      map[string]string
-		shorts         map[string]string
+		shorts         map[ownedURL]string
 		originalURL    string
 		expectedResult string
 		requireError   require.ErrorAssertionFunc
@@ -79,7 +97,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			name:           "original url exist",
 			shortURLLength: len(stubReturnValue),
 			originals:      map[string]string{"short": "original"},
-			shorts:         map[string]string{"original": "short"},
+			shorts:         map[ownedURL]string{{originalURL: "original"}: "short"},
 			originalURL:    "original",
 			expectedResult: "short",
 			requireError:   require.NoError,
@@ -88,7 +106,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			name:           "original url not exist when short was not collided",
 			shortURLLength: len(stubReturnValue),
 			originals:      map[string]string{"short": "original"},
-			shorts:         map[string]string{"original": "short"},
+			shorts:         map[ownedURL]string{{originalURL: "original"}: "short"},
 			originalURL:    "new",
 			requireError:   require.NoError,
 		},
@@ -96,7 +114,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			name:           "original url not exist when short collided",
 			shortURLLength: len(stubReturnValue),
 			originals:      map[string]string{stubReturnValue: "original"},
-			shorts:         map[string]string{"original": stubReturnValue},
+			shorts:         map[ownedURL]string{{originalURL: "original"}: stubReturnValue},
 			originalURL:    "new",
 			requireError:   require.Error,
 		},
@@ -104,7 +122,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			name:           "length is greater than requested",
 			shortURLLength: len(stubReturnValue) - 1,
 			originals:      map[string]string{"short": "original"},
-			shorts:         map[string]string{"original": "short"},
+			shorts:         map[ownedURL]string{{originalURL: "original"}: "short"},
 			originalURL:    "new",
 			requireError:   require.Error,
 		},
@@ -112,7 +130,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			name:           "length is less than requested",
 			shortURLLength: len(stubReturnValue) + 1,
 			originals:      map[string]string{"short": "original"},
-			shorts:         map[string]string{"original": "short"},
+			shorts:         map[ownedURL]string{{originalURL: "original"}: "short"},
 			originalURL:    "new",
 			requireError:   require.Error,
 		},
@@ -124,7 +142,7 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 			sut.originalByEncodedURLs = tt.originals
 			sut.encodedByOriginalURLs = tt.shorts
 
-			result, err := sut.ShortURL(tt.originalURL)
+			result, err := sut.ShortURL("", tt.originalURL)
 			tt.requireError(t, err)
 			if err != nil {
 				assert.NotContains(t, sut.originalByEncodedURLs, tt.originals, "URL should not be added on error")
@@ -135,19 +153,332 @@ func TestInMemoryURLStorage_ShortURL(t *testing.T) {
 				assert.Equal(t, tt.expectedResult, result)
 			}
 
-			assert.Equal(t, sut.encodedByOriginalURLs[tt.originalURL], result, "Original url was not saved")
+			assert.Equal(t, sut.encodedByOriginalURLs[ownedURL{originalURL: tt.originalURL}], result, "Original url was not saved")
 			assert.Equal(t, sut.originalByEncodedURLs[result], tt.originalURL, "Short url was not saved")
 		})
 	}
 }
 
+func TestInMemoryURLStorage_AddLink(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		originals      map[string]string
+		expirations    map[string]time.Time
+		link           urlstore.Link
+		expectedResult string
+		requireError   require.ErrorAssertionFunc
+	}{
+		{
+			name:           "alias is free",
+			originals:      map[string]string{"short": "original"},
+			link:           urlstore.Link{ShortURL: "launch-2026", OriginalURL: "new"},
+			expectedResult: "launch-2026",
+			requireError:   require.NoError,
+		},
+		{
+			name:           "alias is mapped with same url",
+			originals:      map[string]string{"launch-2026": "original"},
+			link:           urlstore.Link{ShortURL: "launch-2026", OriginalURL: "original"},
+			expectedResult: "launch-2026",
+			requireError:   require.NoError,
+		},
+		{
+			name:         "alias is mapped with other url",
+			originals:    map[string]string{"launch-2026": "original"},
+			link:         urlstore.Link{ShortURL: "launch-2026", OriginalURL: "new"},
+			requireError: require.Error,
+		},
+		{
+			name:           "alias is mapped with other url and expired",
+			originals:      map[string]string{"launch-2026": "original"},
+			expirations:    map[string]time.Time{"launch-2026": time.Now().Add(-time.Hour)},
+			link:           urlstore.Link{ShortURL: "launch-2026", OriginalURL: "new"},
+			expectedResult: "launch-2026",
+			requireError:   require.NoError,
+		},
+		{
+			name:           "expiring alias",
+			originals:      map[string]string{"short": "original"},
+			link:           urlstore.Link{ShortURL: "launch-2026", OriginalURL: "new", ExpiresAt: expiresAt},
+			expectedResult: "launch-2026",
+			requireError:   require.NoError,
+		},
+		{
+			name:           "link without short url",
+			originals:      map[string]string{"short": "original"},
+			link:           urlstore.Link{OriginalURL: "new", ExpiresAt: expiresAt},
+			expectedResult: stubReturnValue,
+			requireError:   require.NoError,
+		},
+		{
+			name:         "link without short url when short collided",
+			originals:    map[string]string{stubReturnValue: "original"},
+			link:         urlstore.Link{OriginalURL: "new", ExpiresAt: expiresAt},
+			requireError: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := NewInMemoryURLStorage(encoderStub{}, uint(len(stubReturnValue)))
+			sut.originalByEncodedURLs = tt.originals
+			if tt.expirations != nil {
+				sut.expirationByEncodedURLs = tt.expirations
+			}
+
+			result, err := sut.AddLink(tt.link)
+			tt.requireError(t, err)
+			if err != nil {
+				assert.NotEqual(t, tt.link.OriginalURL, sut.originalByEncodedURLs[tt.link.ShortURL], "Short url should not be remapped")
+				return
+			}
+
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.link.OriginalURL, sut.originalByEncodedURLs[result], "Link was not saved")
+			assert.NotContains(t, sut.encodedByOriginalURLs, ownedURL{originalURL: tt.link.OriginalURL}, "Link should not be reused for original url")
+			if !tt.link.ExpiresAt.IsZero() {
+				assert.Equal(t, tt.link.ExpiresAt, sut.expirationByEncodedURLs[result], "Expiration was not saved")
+			}
+		})
+	}
+}
+
+func TestInMemoryURLStorage_DeleteExpired(t *testing.T) {
+	now := time.Now()
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	sut.originalByEncodedURLs = map[string]string{"permanent": "original", "expired": "original", "active": "original"}
+	sut.expirationByEncodedURLs = map[string]time.Time{"expired": now.Add(-time.Minute), "active": now.Add(time.Minute)}
+	sut.statsByEncodedURLs = map[string]urlstore.ClickStats{"expired": urlstore.NewClickStats("expired")}
+
+	deletedCount, err := sut.DeleteExpired(now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, deletedCount)
+	assert.NotContains(t, sut.originalByEncodedURLs, "expired")
+	assert.NotContains(t, sut.expirationByEncodedURLs, "expired")
+	assert.NotContains(t, sut.statsByEncodedURLs, "expired")
+	assert.Contains(t, sut.originalByEncodedURLs, "permanent")
+	assert.Contains(t, sut.originalByEncodedURLs, "active")
+}
+
+func TestInMemoryURLStorage_ClickStats(t *testing.T) {
+	clickTime := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	sut.originalByEncodedURLs = map[string]string{"clicked": "original"}
+
+	toAdd := urlstore.NewClickStats("clicked")
+	toAdd.Clicks = 2
+	toAdd.LastClickAt = clickTime
+	toAdd.AddCount(urlstore.DimensionDay, "2024-03-02", 2)
+	deleted := urlstore.NewClickStats("deleted")
+	deleted.Clicks = 1
+
+	require.NoError(t, sut.AddClickStats([]urlstore.ClickStats{toAdd, deleted}))
+	require.NoError(t, sut.AddClickStats([]urlstore.ClickStats{toAdd}))
+
+	stats := sut.ClickStats("clicked")
+	assert.Equal(t, int64(4), stats.Clicks)
+	assert.Equal(t, clickTime, stats.LastClickAt)
+	assert.Equal(t, int64(4), stats.Counts[urlstore.DimensionDay]["2024-03-02"])
+	assert.NotContains(t, sut.statsByEncodedURLs, "deleted", "Stats of not existing short url are saved")
+
+	stats.AddCount(urlstore.DimensionDay, "2024-03-02", 1)
+	assert.Equal(t, int64(4), sut.ClickStats("clicked").Counts[urlstore.DimensionDay]["2024-03-02"], "Saved stats are changed by returned copy")
+	assert.Zero(t, sut.ClickStats("not clicked").Clicks)
+}
+
+func TestInMemoryURLStorage_AddLink_RemovesStatsOfExpiredLink(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	sut.originalByEncodedURLs = map[string]string{"alias": "old"}
+	sut.expirationByEncodedURLs = map[string]time.Time{"alias": time.Now().Add(-time.Minute)}
+	sut.statsByEncodedURLs = map[string]urlstore.ClickStats{"alias": {ShortURL: "alias", Clicks: 5}}
+
+	_, err := sut.AddLink(urlstore.Link{ShortURL: "alias", OriginalURL: "new"})
+	require.NoError(t, err)
+
+	assert.Zero(t, sut.ClickStats("alias").Clicks)
+}
+
+func TestInMemoryURLStorage_Delete(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10)
+	shortURL, err := sut.ShortURL("", "original")
+	require.NoError(t, err)
+	require.NoError(t, sut.AddClickStats([]urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1}}))
+
+	require.NoError(t, sut.Delete("", shortURL))
+
+	_, err = sut.Link(shortURL)
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+	assert.NotContains(t, sut.statsByEncodedURLs, shortURL)
+
+	newShortURL, err := sut.ShortURL("", "original")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, newShortURL, "Deleted short url is returned")
+
+	assert.ErrorIs(t, sut.Delete("", shortURL), urlstore.ErrNotFound)
+}
+
+func TestInMemoryURLStorage_SetDisabled(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	sut.originalByEncodedURLs = map[string]string{"short": "original"}
+
+	require.NoError(t, sut.SetDisabled("", "short", true))
+	link, err := sut.Link("short")
+	require.NoError(t, err)
+	assert.True(t, link.Disabled)
+
+	require.NoError(t, sut.SetDisabled("", "short", false))
+	link, err = sut.Link("short")
+	require.NoError(t, err)
+	assert.False(t, link.Disabled)
+
+	assert.ErrorIs(t, sut.SetDisabled("", "not found", true), urlstore.ErrNotFound)
+}
+
+func TestInMemoryURLStorage_UpdateOriginalURL(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10)
+	shortURL, err := sut.ShortURL("", "old")
+	require.NoError(t, err)
+	require.NoError(t, sut.AddClickStats([]urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1}}))
+
+	require.NoError(t, sut.UpdateOriginalURL("", shortURL, "new"))
+	require.NoError(t, sut.UpdateOriginalURL("", shortURL, "new"), "Same original url is not ignored")
+
+	link, err := sut.Link(shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "new", link.OriginalURL)
+	assert.Equal(t, int64(1), sut.ClickStats(shortURL).Clicks, "Stats are not kept")
+
+	history := sut.History(shortURL)
+	require.Len(t, history, 1)
+	assert.Equal(t, "old", history[0].PreviousURL)
+	assert.Equal(t, "new", history[0].NewURL)
+
+	newShortURL, err := sut.ShortURL("", "old")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, newShortURL, "Updated short url is returned for previous original url")
+
+	newShortURL, err = sut.ShortURL("", "new")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, newShortURL, "Updated short url is returned for new original url")
+
+	assert.ErrorIs(t, sut.UpdateOriginalURL("", "not found", "new"), urlstore.ErrNotFound)
+}
+
+func TestInMemoryURLStorage_ShortURLs(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10)
+	savedShortURL, err := sut.ShortURL("", "saved")
+	require.NoError(t, err)
+
+	shortURLs, err := sut.ShortURLs("", []string{"new", "saved", "new"})
+	require.NoError(t, err)
+
+	require.Len(t, shortURLs, 3)
+	assert.Equal(t, savedShortURL, shortURLs[1])
+	assert.Equal(t, shortURLs[0], shortURLs[2])
+	assert.NotEqual(t, shortURLs[0], shortURLs[1])
+
+	links := sut.Links([]string{shortURLs[0], "missing", savedShortURL})
+	require.Len(t, links, 2)
+	assert.Equal(t, "new", links[0].OriginalURL)
+	assert.Equal(t, "saved", links[1].OriginalURL)
+}
+
+func TestInMemoryURLStorage_ShortURLOfOwners(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoder.NewIDEncoder(), 10)
+	firstShortURL, err := sut.ShortURL("first", "original")
+	require.NoError(t, err)
+
+	secondShortURL, err := sut.ShortURL("second", "original")
+	require.NoError(t, err)
+	assert.NotEqual(t, firstShortURL, secondShortURL, "Owners should get separate short urls")
+
+	sameShortURL, err := sut.ShortURL("first", "original")
+	require.NoError(t, err)
+	assert.Equal(t, firstShortURL, sameShortURL)
+
+	link, err := sut.Link(secondShortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "second", link.Owner)
+
+	require.NoError(t, sut.Delete("first", firstShortURL))
+	assert.NotContains(t, sut.ownerByEncodedURLs, firstShortURL)
+	newShortURL, err := sut.ShortURL("first", "original")
+	require.NoError(t, err)
+	assert.NotEqual(t, secondShortURL, newShortURL)
+}
+
+func TestInMemoryURLStorage_OwnerLinks(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	for _, link := range []urlstore.Link{
+		{ShortURL: "c", OriginalURL: "third", Owner: "owner"},
+		{ShortURL: "a", OriginalURL: "first", Owner: "owner"},
+		{ShortURL: "b", OriginalURL: "other", Owner: "other"},
+		{ShortURL: "d", OriginalURL: "anonymous"},
+		{ShortURL: "e", OriginalURL: "fourth", Owner: "owner"},
+	} {
+		_, err := sut.AddLink(link)
+		require.NoError(t, err)
+	}
+
+	firstPage := sut.OwnerLinks("owner", "", 2)
+	assert.Equal(t, []urlstore.Link{
+		{ShortURL: "a", OriginalURL: "first", Owner: "owner"},
+		{ShortURL: "c", OriginalURL: "third", Owner: "owner"},
+	}, firstPage)
+
+	lastPage := sut.OwnerLinks("owner", "c", 2)
+	assert.Equal(t, []urlstore.Link{{ShortURL: "e", OriginalURL: "fourth", Owner: "owner"}}, lastPage)
+
+	assert.Empty(t, sut.OwnerLinks("unknown", "", 2))
+}
+
+func TestInMemoryURLStorage_ForEachLink(t *testing.T) {
+	sut := NewInMemoryURLStorage(encoderStub{}, 10)
+	sut.originalByEncodedURLs = map[string]string{"b": "second", "a": "first"}
+	sut.disabledEncodedURLs = map[string]struct{}{"b": {}}
+
+	var links []urlstore.Link
+	err := sut.ForEachLink(func(link urlstore.Link) error {
+		links = append(links, link)
+		return sut.SetDisabled(urlstore.AnyOwner, link.ShortURL, false)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []urlstore.Link{{ShortURL: "a", OriginalURL: "first"}, {ShortURL: "b", OriginalURL: "second", Disabled: true}}, links)
+
+	expectedErr := errors.New("stop")
+	calls := 0
+	err = sut.ForEachLink(func(_ urlstore.Link) error {
+		calls++
+		return expectedErr
+	})
+	assert.ErrorIs(t, err, expectedErr)
+	assert.Equal(t, 1, calls)
+}
+
+func TestInMemoryURLStorage_ShortURL_SkipsImportedShortURL(t *testing.T) {
+	idEncoder := encoder.NewIDEncoder()
+	sut := NewInMemoryURLStorage(idEncoder, 10)
+	importedShortURL := idEncoder.EncodeID(1, 10)
+	_, err := sut.AddLink(urlstore.Link{ShortURL: importedShortURL, OriginalURL: "imported"})
+	require.NoError(t, err)
+
+	shortURL, err := sut.ShortURL("", "new")
+	require.NoError(t, err)
+
+	assert.Equal(t, idEncoder.EncodeID(2, 10), shortURL)
+}
+
 func TestInMemoryURLStorage_ShortURL_CheckThatIDIsIncrementing(t *testing.T) {
 	idEncoder := encoder.NewIDEncoder()
 	sut := NewInMemoryURLStorage(idEncoder, 10)
-	result1, err := sut.ShortURL("url1")
+	result1, err := sut.ShortURL("", "url1")
 	require.NoError(t, err)
 
-	result2, err := sut.ShortURL("url2")
+	result2, err := sut.ShortURL("", "url2")
 	require.NoError(t, err, "Short probably same")
 
 	assert.NotEqual(t, result1, result2)
@@ -157,10 +488,10 @@ func TestInMemoryURLStorage_saveNewURL_ShouldReturnSavedResult_WhenResultWasAlre
 	idEncoder := encoder.NewIDEncoder()
 	sut := NewInMemoryURLStorage(idEncoder, 10)
 
-	result1, err := sut.saveNewURL("url")
+	result1, err := sut.saveNewURL(ownedURL{originalURL: "url"})
 	require.NoError(t, err)
 
-	result2, err := sut.saveNewURL("url")
+	result2, err := sut.saveNewURL(ownedURL{originalURL: "url"})
 	require.NoError(t, err)
 
 	assert.Equal(t, result1, result2, "First result was not checked")
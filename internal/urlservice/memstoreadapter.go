@@ -2,8 +2,10 @@ package urlservice
 
 import (
 	"context"
+	"time"
 
 	"shorturl/internal/urlservice/memstore"
+	"shorturl/internal/urlservice/urlstore"
 )
 
 // inMemoryURLStorageAdapter is used as type InMemoryURLStorage to implement the urlStorage interface.
@@ -15,10 +17,70 @@ func newInMemoryURLStorageAdapter(storage *memstore.InMemoryURLStorage) inMemory
 	return inMemoryURLStorageAdapter{storage}
 }
 
-func (a inMemoryURLStorageAdapter) OriginalURL(_ context.Context, shortURL string) (string, error) {
-	return a.storage.OriginalURL(shortURL)
+func (a inMemoryURLStorageAdapter) Link(_ context.Context, shortURL string) (urlstore.Link, error) {
+	return a.storage.Link(shortURL)
 }
 
-func (a inMemoryURLStorageAdapter) ShortURL(_ context.Context, originalURL string) (string, error) {
-	return a.storage.ShortURL(originalURL)
+func (a inMemoryURLStorageAdapter) ShortURL(_ context.Context, owner, originalURL string) (string, error) {
+	return a.storage.ShortURL(owner, originalURL)
+}
+
+func (a inMemoryURLStorageAdapter) AddLink(_ context.Context, link urlstore.Link) (string, error) {
+	return a.storage.AddLink(link)
+}
+
+func (a inMemoryURLStorageAdapter) DeleteExpired(_ context.Context, moment time.Time) (int64, error) {
+	deletedCount, err := a.storage.DeleteExpired(moment)
+	return int64(deletedCount), err
+}
+
+// Ping always succeeds, in-memory storage is available while the program runs.
+func (a inMemoryURLStorageAdapter) Ping(_ context.Context) error {
+	return nil
+}
+
+func (a inMemoryURLStorageAdapter) AddClickStats(_ context.Context, stats []urlstore.ClickStats) error {
+	return a.storage.AddClickStats(stats)
+}
+
+func (a inMemoryURLStorageAdapter) ClickStats(_ context.Context, shortURL string) (urlstore.ClickStats, error) {
+	return a.storage.ClickStats(shortURL), nil
+}
+
+func (a inMemoryURLStorageAdapter) Delete(_ context.Context, owner, shortURL string) error {
+	return a.storage.Delete(owner, shortURL)
+}
+
+func (a inMemoryURLStorageAdapter) SetDisabled(_ context.Context, owner, shortURL string, disabled bool) error {
+	return a.storage.SetDisabled(owner, shortURL, disabled)
+}
+
+func (a inMemoryURLStorageAdapter) UpdateOriginalURL(_ context.Context, owner, shortURL, originalURL string) error {
+	return a.storage.UpdateOriginalURL(owner, shortURL, originalURL)
+}
+
+func (a inMemoryURLStorageAdapter) History(_ context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	return a.storage.History(shortURL), nil
+}
+
+func (a inMemoryURLStorageAdapter) ShortURLs(_ context.Context, owner string, originalURLs []string) ([]string, error) {
+	return a.storage.ShortURLs(owner, originalURLs)
+}
+
+func (a inMemoryURLStorageAdapter) OwnerLinks(_ context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	return a.storage.OwnerLinks(owner, afterShortURL, limit), nil
+}
+
+func (a inMemoryURLStorageAdapter) Links(_ context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	return a.storage.Links(shortURLs), nil
+}
+
+func (a inMemoryURLStorageAdapter) ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error {
+	return a.storage.ForEachLink(func(link urlstore.Link) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		return fn(link)
+	})
 }
@@ -4,8 +4,11 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"shorturl/internal/encoder"
 	"shorturl/internal/urlservice/memstore"
+	"shorturl/internal/urlservice/storagetest"
 )
 
 type encoderStub struct{}
@@ -20,3 +23,21 @@ func Test_newInMemoryURLStorageAdapter(t *testing.T) {
 
 	assert.Implements(t, (*urlStorage)(nil), adapter)
 }
+
+func TestInMemoryURLStorage_Contract(t *testing.T) {
+	storagetest.Run(t, func(_ *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) storagetest.Storage {
+		return WithInMemoryStorage()(idEncoder, shortURLLength)
+	})
+}
+
+func TestInMemoryURLStorage_Contract_WithJournal(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) storagetest.Storage {
+		journal, err := memstore.OpenJournal(memstore.JournalSettings{Dir: t.TempDir()})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, journal.Close())
+		})
+
+		return WithInMemoryStorage(memstore.WithJournal(journal))(idEncoder, shortURLLength)
+	})
+}
@@ -0,0 +1,41 @@
+package urlservice
+
+import (
+	"time"
+)
+
+// Metrics records durations of storage operations and counts of created and resolved short URLs.
+type Metrics interface {
+	ObserveStorageOperation(storage, operation string, duration time.Duration, err error)
+	AddLinksCreated(count int)
+	AddLinksResolved(count int)
+}
+
+// ServiceOption is used to set optional settings of ShortURLService on its initialization.
+type ServiceOption func(service *ShortURLService)
+
+// WithMetrics returns an option that records metrics of the service and of every operation of its storage.
+// Nil metrics are ignored.
+func WithMetrics(metrics Metrics) ServiceOption {
+	return func(service *ShortURLService) {
+		if metrics == nil {
+			return
+		}
+
+		service.metrics = metrics
+		service.instrumentedStorage().metrics = metrics
+	}
+}
+
+// addLinksCreated and addLinksResolved record counts of short URLs if the service has metrics.
+func (s ShortURLService) addLinksCreated(count int) {
+	if s.metrics != nil && count != 0 {
+		s.metrics.AddLinksCreated(count)
+	}
+}
+
+func (s ShortURLService) addLinksResolved(count int) {
+	if s.metrics != nil && count != 0 {
+		s.metrics.AddLinksResolved(count)
+	}
+}
@@ -0,0 +1,57 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestWithMetrics(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Link(mock.Anything, "1234567890").Return(urlstore.Link{OriginalURL: "https://example.com"}, nil).Once()
+	storageMock.EXPECT().Link(mock.Anything, "0987654321").Return(urlstore.Link{}, errors.New("some error")).Once()
+
+	metricsMock := NewMockMetrics(t)
+	metricsMock.EXPECT().ObserveStorageOperation("unknown", "link", mock.Anything, mock.MatchedBy(func(err error) bool { return err == nil })).Once()
+	metricsMock.EXPECT().ObserveStorageOperation("unknown", "link", mock.Anything, mock.MatchedBy(func(err error) bool { return err != nil })).Once()
+	metricsMock.EXPECT().AddLinksResolved(1).Once()
+
+	sut := ShortURLService{storage: storageMock}
+	WithMetrics(metricsMock)(&sut)
+
+	originalURL, err := sut.OriginalURL(context.Background(), "1234567890")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", originalURL)
+
+	_, err = sut.OriginalURL(context.Background(), "0987654321")
+	assert.Error(t, err)
+}
+
+func TestStorageName(t *testing.T) {
+	tests := []struct {
+		name          string
+		storageOption StorageOptionFunc
+		expected      string
+	}{
+		{name: "in-memory", storageOption: WithInMemoryStorage(), expected: "memstore"},
+		{name: "redis", storageOption: WithRedisStorage(redis.NewClient(&redis.Options{})), expected: "redisstore"},
+		{name: "bolt", storageOption: WithBoltStorage(nil), expected: "boltstore"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := NewShortURLService(nil, 10, tt.storageOption, WithMetrics(NewMockMetrics(t)))
+
+			storage, ok := sut.storage.(*instrumentedStorage)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, storage.name)
+		})
+	}
+}
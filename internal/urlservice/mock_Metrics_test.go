@@ -0,0 +1,138 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package urlservice
+
+import (
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockMetrics is an autogenerated mock type for the Metrics type
+type MockMetrics struct {
+	mock.Mock
+}
+
+type MockMetrics_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMetrics) EXPECT() *MockMetrics_Expecter {
+	return &MockMetrics_Expecter{mock: &_m.Mock}
+}
+
+// AddLinksCreated provides a mock function with given fields: count
+func (_m *MockMetrics) AddLinksCreated(count int) {
+	_m.Called(count)
+}
+
+// MockMetrics_AddLinksCreated_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddLinksCreated'
+type MockMetrics_AddLinksCreated_Call struct {
+	*mock.Call
+}
+
+// AddLinksCreated is a helper method to define mock.On call
+//   - count int
+func (_e *MockMetrics_Expecter) AddLinksCreated(count interface{}) *MockMetrics_AddLinksCreated_Call {
+	return &MockMetrics_AddLinksCreated_Call{Call: _e.mock.On("AddLinksCreated", count)}
+}
+
+func (_c *MockMetrics_AddLinksCreated_Call) Run(run func(count int)) *MockMetrics_AddLinksCreated_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *MockMetrics_AddLinksCreated_Call) Return() *MockMetrics_AddLinksCreated_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockMetrics_AddLinksCreated_Call) RunAndReturn(run func(int)) *MockMetrics_AddLinksCreated_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddLinksResolved provides a mock function with given fields: count
+func (_m *MockMetrics) AddLinksResolved(count int) {
+	_m.Called(count)
+}
+
+// MockMetrics_AddLinksResolved_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddLinksResolved'
+type MockMetrics_AddLinksResolved_Call struct {
+	*mock.Call
+}
+
+// AddLinksResolved is a helper method to define mock.On call
+//   - count int
+func (_e *MockMetrics_Expecter) AddLinksResolved(count interface{}) *MockMetrics_AddLinksResolved_Call {
+	return &MockMetrics_AddLinksResolved_Call{Call: _e.mock.On("AddLinksResolved", count)}
+}
+
+func (_c *MockMetrics_AddLinksResolved_Call) Run(run func(count int)) *MockMetrics_AddLinksResolved_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int))
+	})
+	return _c
+}
+
+func (_c *MockMetrics_AddLinksResolved_Call) Return() *MockMetrics_AddLinksResolved_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockMetrics_AddLinksResolved_Call) RunAndReturn(run func(int)) *MockMetrics_AddLinksResolved_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ObserveStorageOperation provides a mock function with given fields: storage, operation, duration, err
+func (_m *MockMetrics) ObserveStorageOperation(storage string, operation string, duration time.Duration, err error) {
+	_m.Called(storage, operation, duration, err)
+}
+
+// MockMetrics_ObserveStorageOperation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ObserveStorageOperation'
+type MockMetrics_ObserveStorageOperation_Call struct {
+	*mock.Call
+}
+
+// ObserveStorageOperation is a helper method to define mock.On call
+//   - storage string
+//   - operation string
+//   - duration time.Duration
+//   - err error
+func (_e *MockMetrics_Expecter) ObserveStorageOperation(storage interface{}, operation interface{}, duration interface{}, err interface{}) *MockMetrics_ObserveStorageOperation_Call {
+	return &MockMetrics_ObserveStorageOperation_Call{Call: _e.mock.On("ObserveStorageOperation", storage, operation, duration, err)}
+}
+
+func (_c *MockMetrics_ObserveStorageOperation_Call) Run(run func(storage string, operation string, duration time.Duration, err error)) *MockMetrics_ObserveStorageOperation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(time.Duration), args[3].(error))
+	})
+	return _c
+}
+
+func (_c *MockMetrics_ObserveStorageOperation_Call) Return() *MockMetrics_ObserveStorageOperation_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockMetrics_ObserveStorageOperation_Call) RunAndReturn(run func(string, string, time.Duration, error)) *MockMetrics_ObserveStorageOperation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockMetrics creates a new instance of MockMetrics. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMetrics(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMetrics {
+	mock := &MockMetrics{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
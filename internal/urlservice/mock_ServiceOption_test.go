@@ -0,0 +1,65 @@
+// Code generated by mockery v2.36.0. DO NOT EDIT.
+
+package urlservice
+
+import mock "github.com/stretchr/testify/mock"
+
+// MockServiceOption is an autogenerated mock type for the ServiceOption type
+type MockServiceOption struct {
+	mock.Mock
+}
+
+type MockServiceOption_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockServiceOption) EXPECT() *MockServiceOption_Expecter {
+	return &MockServiceOption_Expecter{mock: &_m.Mock}
+}
+
+// Execute provides a mock function with given fields: service
+func (_m *MockServiceOption) Execute(service *ShortURLService) {
+	_m.Called(service)
+}
+
+// MockServiceOption_Execute_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Execute'
+type MockServiceOption_Execute_Call struct {
+	*mock.Call
+}
+
+// Execute is a helper method to define mock.On call
+//   - service *ShortURLService
+func (_e *MockServiceOption_Expecter) Execute(service interface{}) *MockServiceOption_Execute_Call {
+	return &MockServiceOption_Execute_Call{Call: _e.mock.On("Execute", service)}
+}
+
+func (_c *MockServiceOption_Execute_Call) Run(run func(service *ShortURLService)) *MockServiceOption_Execute_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*ShortURLService))
+	})
+	return _c
+}
+
+func (_c *MockServiceOption_Execute_Call) Return() *MockServiceOption_Execute_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockServiceOption_Execute_Call) RunAndReturn(run func(*ShortURLService)) *MockServiceOption_Execute_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMockServiceOption creates a new instance of MockServiceOption. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockServiceOption(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockServiceOption {
+	mock := &MockServiceOption{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
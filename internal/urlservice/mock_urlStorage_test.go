@@ -4,6 +4,8 @@ package urlservice
 
 import (
 	context "context"
+	urlstore "shorturl/internal/urlservice/urlstore"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -21,19 +23,363 @@ func (_m *MockurlStorage) EXPECT() *MockurlStorage_Expecter {
 	return &MockurlStorage_Expecter{mock: &_m.Mock}
 }
 
-// OriginalURL provides a mock function with given fields: ctx, shortURL
-func (_m *MockurlStorage) OriginalURL(ctx context.Context, shortURL string) (string, error) {
-	ret := _m.Called(ctx, shortURL)
+// AddClickStats provides a mock function with given fields: ctx, stats
+func (_m *MockurlStorage) AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error {
+	ret := _m.Called(ctx, stats)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []urlstore.ClickStats) error); ok {
+		r0 = rf(ctx, stats)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_AddClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddClickStats'
+type MockurlStorage_AddClickStats_Call struct {
+	*mock.Call
+}
+
+// AddClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - stats []urlstore.ClickStats
+func (_e *MockurlStorage_Expecter) AddClickStats(ctx interface{}, stats interface{}) *MockurlStorage_AddClickStats_Call {
+	return &MockurlStorage_AddClickStats_Call{Call: _e.mock.On("AddClickStats", ctx, stats)}
+}
+
+func (_c *MockurlStorage_AddClickStats_Call) Run(run func(ctx context.Context, stats []urlstore.ClickStats)) *MockurlStorage_AddClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]urlstore.ClickStats))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_AddClickStats_Call) Return(_a0 error) *MockurlStorage_AddClickStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_AddClickStats_Call) RunAndReturn(run func(context.Context, []urlstore.ClickStats) error) *MockurlStorage_AddClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddLink provides a mock function with given fields: ctx, link
+func (_m *MockurlStorage) AddLink(ctx context.Context, link urlstore.Link) (string, error) {
+	ret := _m.Called(ctx, link)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, urlstore.Link) (string, error)); ok {
+		return rf(ctx, link)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, urlstore.Link) string); ok {
+		r0 = rf(ctx, link)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, urlstore.Link) error); ok {
+		r1 = rf(ctx, link)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_AddLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddLink'
+type MockurlStorage_AddLink_Call struct {
+	*mock.Call
+}
+
+// AddLink is a helper method to define mock.On call
+//   - ctx context.Context
+//   - link urlstore.Link
+func (_e *MockurlStorage_Expecter) AddLink(ctx interface{}, link interface{}) *MockurlStorage_AddLink_Call {
+	return &MockurlStorage_AddLink_Call{Call: _e.mock.On("AddLink", ctx, link)}
+}
+
+func (_c *MockurlStorage_AddLink_Call) Run(run func(ctx context.Context, link urlstore.Link)) *MockurlStorage_AddLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(urlstore.Link))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_AddLink_Call) Return(_a0 string, _a1 error) *MockurlStorage_AddLink_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_AddLink_Call) RunAndReturn(run func(context.Context, urlstore.Link) (string, error)) *MockurlStorage_AddLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ClickStats provides a mock function with given fields: ctx, shortURL
+func (_m *MockurlStorage) ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error) {
+	ret := _m.Called(ctx, shortURL)
+
+	var r0 urlstore.ClickStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (urlstore.ClickStats, error)); ok {
 		return rf(ctx, shortURL)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) urlstore.ClickStats); ok {
 		r0 = rf(ctx, shortURL)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(urlstore.ClickStats)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_ClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ClickStats'
+type MockurlStorage_ClickStats_Call struct {
+	*mock.Call
+}
+
+// ClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+func (_e *MockurlStorage_Expecter) ClickStats(ctx interface{}, shortURL interface{}) *MockurlStorage_ClickStats_Call {
+	return &MockurlStorage_ClickStats_Call{Call: _e.mock.On("ClickStats", ctx, shortURL)}
+}
+
+func (_c *MockurlStorage_ClickStats_Call) Run(run func(ctx context.Context, shortURL string)) *MockurlStorage_ClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_ClickStats_Call) Return(_a0 urlstore.ClickStats, _a1 error) *MockurlStorage_ClickStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_ClickStats_Call) RunAndReturn(run func(context.Context, string) (urlstore.ClickStats, error)) *MockurlStorage_ClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Delete provides a mock function with given fields: ctx, owner, shortURL
+func (_m *MockurlStorage) Delete(ctx context.Context, owner string, shortURL string) error {
+	ret := _m.Called(ctx, owner, shortURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type MockurlStorage_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+func (_e *MockurlStorage_Expecter) Delete(ctx interface{}, owner interface{}, shortURL interface{}) *MockurlStorage_Delete_Call {
+	return &MockurlStorage_Delete_Call{Call: _e.mock.On("Delete", ctx, owner, shortURL)}
+}
+
+func (_c *MockurlStorage_Delete_Call) Run(run func(ctx context.Context, owner string, shortURL string)) *MockurlStorage_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_Delete_Call) Return(_a0 error) *MockurlStorage_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_Delete_Call) RunAndReturn(run func(context.Context, string, string) error) *MockurlStorage_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpired provides a mock function with given fields: ctx, moment
+func (_m *MockurlStorage) DeleteExpired(ctx context.Context, moment time.Time) (int64, error) {
+	ret := _m.Called(ctx, moment)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, moment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, moment)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, moment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_DeleteExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpired'
+type MockurlStorage_DeleteExpired_Call struct {
+	*mock.Call
+}
+
+// DeleteExpired is a helper method to define mock.On call
+//   - ctx context.Context
+//   - moment time.Time
+func (_e *MockurlStorage_Expecter) DeleteExpired(ctx interface{}, moment interface{}) *MockurlStorage_DeleteExpired_Call {
+	return &MockurlStorage_DeleteExpired_Call{Call: _e.mock.On("DeleteExpired", ctx, moment)}
+}
+
+func (_c *MockurlStorage_DeleteExpired_Call) Run(run func(ctx context.Context, moment time.Time)) *MockurlStorage_DeleteExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_DeleteExpired_Call) Return(_a0 int64, _a1 error) *MockurlStorage_DeleteExpired_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_DeleteExpired_Call) RunAndReturn(run func(context.Context, time.Time) (int64, error)) *MockurlStorage_DeleteExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ForEachLink provides a mock function with given fields: ctx, fn
+func (_m *MockurlStorage) ForEachLink(ctx context.Context, fn func(urlstore.Link) error) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(urlstore.Link) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_ForEachLink_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ForEachLink'
+type MockurlStorage_ForEachLink_Call struct {
+	*mock.Call
+}
+
+// ForEachLink is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(urlstore.Link) error
+func (_e *MockurlStorage_Expecter) ForEachLink(ctx interface{}, fn interface{}) *MockurlStorage_ForEachLink_Call {
+	return &MockurlStorage_ForEachLink_Call{Call: _e.mock.On("ForEachLink", ctx, fn)}
+}
+
+func (_c *MockurlStorage_ForEachLink_Call) Run(run func(ctx context.Context, fn func(urlstore.Link) error)) *MockurlStorage_ForEachLink_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(urlstore.Link) error))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_ForEachLink_Call) Return(_a0 error) *MockurlStorage_ForEachLink_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_ForEachLink_Call) RunAndReturn(run func(context.Context, func(urlstore.Link) error) error) *MockurlStorage_ForEachLink_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// History provides a mock function with given fields: ctx, shortURL
+func (_m *MockurlStorage) History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	ret := _m.Called(ctx, shortURL)
+
+	var r0 []urlstore.DestinationChange
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]urlstore.DestinationChange, error)); ok {
+		return rf(ctx, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []urlstore.DestinationChange); ok {
+		r0 = rf(ctx, shortURL)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]urlstore.DestinationChange)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortURL)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type MockurlStorage_History_Call struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURL string
+func (_e *MockurlStorage_Expecter) History(ctx interface{}, shortURL interface{}) *MockurlStorage_History_Call {
+	return &MockurlStorage_History_Call{Call: _e.mock.On("History", ctx, shortURL)}
+}
+
+func (_c *MockurlStorage_History_Call) Run(run func(ctx context.Context, shortURL string)) *MockurlStorage_History_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_History_Call) Return(_a0 []urlstore.DestinationChange, _a1 error) *MockurlStorage_History_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_History_Call) RunAndReturn(run func(context.Context, string) ([]urlstore.DestinationChange, error)) *MockurlStorage_History_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Link provides a mock function with given fields: ctx, shortURL
+func (_m *MockurlStorage) Link(ctx context.Context, shortURL string) (urlstore.Link, error) {
+	ret := _m.Called(ctx, shortURL)
+
+	var r0 urlstore.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (urlstore.Link, error)); ok {
+		return rf(ctx, shortURL)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) urlstore.Link); ok {
+		r0 = rf(ctx, shortURL)
+	} else {
+		r0 = ret.Get(0).(urlstore.Link)
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
@@ -45,52 +391,251 @@ func (_m *MockurlStorage) OriginalURL(ctx context.Context, shortURL string) (str
 	return r0, r1
 }
 
-// MockurlStorage_OriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OriginalURL'
-type MockurlStorage_OriginalURL_Call struct {
+// MockurlStorage_Link_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Link'
+type MockurlStorage_Link_Call struct {
 	*mock.Call
 }
 
-// OriginalURL is a helper method to define mock.On call
+// Link is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortURL string
-func (_e *MockurlStorage_Expecter) OriginalURL(ctx interface{}, shortURL interface{}) *MockurlStorage_OriginalURL_Call {
-	return &MockurlStorage_OriginalURL_Call{Call: _e.mock.On("OriginalURL", ctx, shortURL)}
+func (_e *MockurlStorage_Expecter) Link(ctx interface{}, shortURL interface{}) *MockurlStorage_Link_Call {
+	return &MockurlStorage_Link_Call{Call: _e.mock.On("Link", ctx, shortURL)}
 }
 
-func (_c *MockurlStorage_OriginalURL_Call) Run(run func(ctx context.Context, shortURL string)) *MockurlStorage_OriginalURL_Call {
+func (_c *MockurlStorage_Link_Call) Run(run func(ctx context.Context, shortURL string)) *MockurlStorage_Link_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockurlStorage_OriginalURL_Call) Return(_a0 string, _a1 error) *MockurlStorage_OriginalURL_Call {
+func (_c *MockurlStorage_Link_Call) Return(_a0 urlstore.Link, _a1 error) *MockurlStorage_Link_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_Link_Call) RunAndReturn(run func(context.Context, string) (urlstore.Link, error)) *MockurlStorage_Link_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Links provides a mock function with given fields: ctx, shortURLs
+func (_m *MockurlStorage) Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	ret := _m.Called(ctx, shortURLs)
+
+	var r0 []urlstore.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]urlstore.Link, error)); ok {
+		return rf(ctx, shortURLs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []urlstore.Link); ok {
+		r0 = rf(ctx, shortURLs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]urlstore.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortURLs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_Links_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Links'
+type MockurlStorage_Links_Call struct {
+	*mock.Call
+}
+
+// Links is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortURLs []string
+func (_e *MockurlStorage_Expecter) Links(ctx interface{}, shortURLs interface{}) *MockurlStorage_Links_Call {
+	return &MockurlStorage_Links_Call{Call: _e.mock.On("Links", ctx, shortURLs)}
+}
+
+func (_c *MockurlStorage_Links_Call) Run(run func(ctx context.Context, shortURLs []string)) *MockurlStorage_Links_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_Links_Call) Return(_a0 []urlstore.Link, _a1 error) *MockurlStorage_Links_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockurlStorage_OriginalURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockurlStorage_OriginalURL_Call {
+func (_c *MockurlStorage_Links_Call) RunAndReturn(run func(context.Context, []string) ([]urlstore.Link, error)) *MockurlStorage_Links_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OwnerLinks provides a mock function with given fields: ctx, owner, afterShortURL, limit
+func (_m *MockurlStorage) OwnerLinks(ctx context.Context, owner string, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	ret := _m.Called(ctx, owner, afterShortURL, limit)
+
+	var r0 []urlstore.Link
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) ([]urlstore.Link, error)); ok {
+		return rf(ctx, owner, afterShortURL, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int) []urlstore.Link); ok {
+		r0 = rf(ctx, owner, afterShortURL, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]urlstore.Link)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int) error); ok {
+		r1 = rf(ctx, owner, afterShortURL, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_OwnerLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OwnerLinks'
+type MockurlStorage_OwnerLinks_Call struct {
+	*mock.Call
+}
+
+// OwnerLinks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - afterShortURL string
+//   - limit int
+func (_e *MockurlStorage_Expecter) OwnerLinks(ctx interface{}, owner interface{}, afterShortURL interface{}, limit interface{}) *MockurlStorage_OwnerLinks_Call {
+	return &MockurlStorage_OwnerLinks_Call{Call: _e.mock.On("OwnerLinks", ctx, owner, afterShortURL, limit)}
+}
+
+func (_c *MockurlStorage_OwnerLinks_Call) Run(run func(ctx context.Context, owner string, afterShortURL string, limit int)) *MockurlStorage_OwnerLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_OwnerLinks_Call) Return(_a0 []urlstore.Link, _a1 error) *MockurlStorage_OwnerLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_OwnerLinks_Call) RunAndReturn(run func(context.Context, string, string, int) ([]urlstore.Link, error)) *MockurlStorage_OwnerLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *MockurlStorage) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_Ping_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Ping'
+type MockurlStorage_Ping_Call struct {
+	*mock.Call
+}
+
+// Ping is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockurlStorage_Expecter) Ping(ctx interface{}) *MockurlStorage_Ping_Call {
+	return &MockurlStorage_Ping_Call{Call: _e.mock.On("Ping", ctx)}
+}
+
+func (_c *MockurlStorage_Ping_Call) Run(run func(ctx context.Context)) *MockurlStorage_Ping_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_Ping_Call) Return(_a0 error) *MockurlStorage_Ping_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_Ping_Call) RunAndReturn(run func(context.Context) error) *MockurlStorage_Ping_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ShortURL provides a mock function with given fields: ctx, originalURL
-func (_m *MockurlStorage) ShortURL(ctx context.Context, originalURL string) (string, error) {
-	ret := _m.Called(ctx, originalURL)
+// SetDisabled provides a mock function with given fields: ctx, owner, shortURL, disabled
+func (_m *MockurlStorage) SetDisabled(ctx context.Context, owner string, shortURL string, disabled bool) error {
+	ret := _m.Called(ctx, owner, shortURL, disabled)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, bool) error); ok {
+		r0 = rf(ctx, owner, shortURL, disabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_SetDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetDisabled'
+type MockurlStorage_SetDisabled_Call struct {
+	*mock.Call
+}
+
+// SetDisabled is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+//   - disabled bool
+func (_e *MockurlStorage_Expecter) SetDisabled(ctx interface{}, owner interface{}, shortURL interface{}, disabled interface{}) *MockurlStorage_SetDisabled_Call {
+	return &MockurlStorage_SetDisabled_Call{Call: _e.mock.On("SetDisabled", ctx, owner, shortURL, disabled)}
+}
+
+func (_c *MockurlStorage_SetDisabled_Call) Run(run func(ctx context.Context, owner string, shortURL string, disabled bool)) *MockurlStorage_SetDisabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_SetDisabled_Call) Return(_a0 error) *MockurlStorage_SetDisabled_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_SetDisabled_Call) RunAndReturn(run func(context.Context, string, string, bool) error) *MockurlStorage_SetDisabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShortURL provides a mock function with given fields: ctx, owner, originalURL
+func (_m *MockurlStorage) ShortURL(ctx context.Context, owner string, originalURL string) (string, error) {
+	ret := _m.Called(ctx, owner, originalURL)
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (string, error)); ok {
-		return rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (string, error)); ok {
+		return rf(ctx, owner, originalURL)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) string); ok {
-		r0 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) string); ok {
+		r0 = rf(ctx, owner, originalURL)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, owner, originalURL)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -105,14 +650,15 @@ type MockurlStorage_ShortURL_Call struct {
 
 // ShortURL is a helper method to define mock.On call
 //   - ctx context.Context
+//   - owner string
 //   - originalURL string
-func (_e *MockurlStorage_Expecter) ShortURL(ctx interface{}, originalURL interface{}) *MockurlStorage_ShortURL_Call {
-	return &MockurlStorage_ShortURL_Call{Call: _e.mock.On("ShortURL", ctx, originalURL)}
+func (_e *MockurlStorage_Expecter) ShortURL(ctx interface{}, owner interface{}, originalURL interface{}) *MockurlStorage_ShortURL_Call {
+	return &MockurlStorage_ShortURL_Call{Call: _e.mock.On("ShortURL", ctx, owner, originalURL)}
 }
 
-func (_c *MockurlStorage_ShortURL_Call) Run(run func(ctx context.Context, originalURL string)) *MockurlStorage_ShortURL_Call {
+func (_c *MockurlStorage_ShortURL_Call) Run(run func(ctx context.Context, owner string, originalURL string)) *MockurlStorage_ShortURL_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -122,7 +668,108 @@ func (_c *MockurlStorage_ShortURL_Call) Return(_a0 string, _a1 error) *MockurlSt
 	return _c
 }
 
-func (_c *MockurlStorage_ShortURL_Call) RunAndReturn(run func(context.Context, string) (string, error)) *MockurlStorage_ShortURL_Call {
+func (_c *MockurlStorage_ShortURL_Call) RunAndReturn(run func(context.Context, string, string) (string, error)) *MockurlStorage_ShortURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShortURLs provides a mock function with given fields: ctx, owner, originalURLs
+func (_m *MockurlStorage) ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	ret := _m.Called(ctx, owner, originalURLs)
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]string, error)); ok {
+		return rf(ctx, owner, originalURLs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []string); ok {
+		r0 = rf(ctx, owner, originalURLs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, owner, originalURLs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockurlStorage_ShortURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShortURLs'
+type MockurlStorage_ShortURLs_Call struct {
+	*mock.Call
+}
+
+// ShortURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - originalURLs []string
+func (_e *MockurlStorage_Expecter) ShortURLs(ctx interface{}, owner interface{}, originalURLs interface{}) *MockurlStorage_ShortURLs_Call {
+	return &MockurlStorage_ShortURLs_Call{Call: _e.mock.On("ShortURLs", ctx, owner, originalURLs)}
+}
+
+func (_c *MockurlStorage_ShortURLs_Call) Run(run func(ctx context.Context, owner string, originalURLs []string)) *MockurlStorage_ShortURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_ShortURLs_Call) Return(_a0 []string, _a1 error) *MockurlStorage_ShortURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockurlStorage_ShortURLs_Call) RunAndReturn(run func(context.Context, string, []string) ([]string, error)) *MockurlStorage_ShortURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateOriginalURL provides a mock function with given fields: ctx, owner, shortURL, originalURL
+func (_m *MockurlStorage) UpdateOriginalURL(ctx context.Context, owner string, shortURL string, originalURL string) error {
+	ret := _m.Called(ctx, owner, shortURL, originalURL)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string) error); ok {
+		r0 = rf(ctx, owner, shortURL, originalURL)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockurlStorage_UpdateOriginalURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateOriginalURL'
+type MockurlStorage_UpdateOriginalURL_Call struct {
+	*mock.Call
+}
+
+// UpdateOriginalURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - owner string
+//   - shortURL string
+//   - originalURL string
+func (_e *MockurlStorage_Expecter) UpdateOriginalURL(ctx interface{}, owner interface{}, shortURL interface{}, originalURL interface{}) *MockurlStorage_UpdateOriginalURL_Call {
+	return &MockurlStorage_UpdateOriginalURL_Call{Call: _e.mock.On("UpdateOriginalURL", ctx, owner, shortURL, originalURL)}
+}
+
+func (_c *MockurlStorage_UpdateOriginalURL_Call) Run(run func(ctx context.Context, owner string, shortURL string, originalURL string)) *MockurlStorage_UpdateOriginalURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockurlStorage_UpdateOriginalURL_Call) Return(_a0 error) *MockurlStorage_UpdateOriginalURL_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockurlStorage_UpdateOriginalURL_Call) RunAndReturn(run func(context.Context, string, string, string) error) *MockurlStorage_UpdateOriginalURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
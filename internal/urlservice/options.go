@@ -0,0 +1,119 @@
+package urlservice
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// LinkOptions are optional settings of a new short URL. Short URL created with any of
+// them except Owner is never reused for the same original URL.
+//
+// Alias is used as short URL instead of encoded one. ExpiresAt and TTL are mutually
+// exclusive ways to set the moment when short URL stops resolving. Owner is recorded
+// as a creator of short URL, short URLs of different owners are never shared.
+type LinkOptions struct {
+	Alias     string
+	ExpiresAt time.Time
+	TTL       time.Duration
+	Owner     string
+}
+
+// isReusable reports whether short URL created with options may be reused for the same original URL.
+func (o LinkOptions) isReusable() bool {
+	return o.Alias == "" && o.ExpiresAt.IsZero() && o.TTL == 0
+}
+
+// maxAliasLength is the maximum length of alias, it is limited by storage.
+const maxAliasLength = 64
+
+// aliasSeparator is the only symbol allowed in alias in addition to the encoder's symbols.
+// Encoder never returns it, so alias containing it never collides with encoded short URLs.
+const aliasSeparator = "-"
+
+// reservedAliases contains paths used by API, that cannot be short URLs.
+var reservedAliases = []string{"api", "healthz", "livez", "readyz", "metrics"}
+
+// newLink validates options and returns the link to save with them.
+func (s ShortURLService) newLink(originalURL string, options LinkOptions) (urlstore.Link, error) {
+	if options.Alias != "" {
+		if err := s.validateAlias(options.Alias); err != nil {
+			return urlstore.Link{}, err
+		}
+	}
+
+	expiresAt, err := expirationTime(options, time.Now())
+	if err != nil {
+		return urlstore.Link{}, err
+	}
+
+	link := urlstore.Link{
+		ShortURL:    options.Alias,
+		OriginalURL: originalURL,
+		ExpiresAt:   expiresAt,
+		Owner:       options.Owner,
+	}
+
+	return link, nil
+}
+
+// expirationTime returns the moment when the link with options expires. Zero value
+// means that the link never expires. Expiration time must be in the future.
+func expirationTime(options LinkOptions, now time.Time) (time.Time, error) {
+	switch {
+	case !options.ExpiresAt.IsZero() && options.TTL != 0:
+		return time.Time{}, fmt.Errorf("%w: only one of expiration time and ttl can be set", ErrInvalidExpiration)
+	case options.TTL < 0:
+		return time.Time{}, fmt.Errorf("%w: ttl must be positive", ErrInvalidExpiration)
+	case options.TTL > 0:
+		return now.Add(options.TTL), nil
+	case !options.ExpiresAt.IsZero() && !options.ExpiresAt.After(now):
+		return time.Time{}, fmt.Errorf("%w: expiration time must be in the future", ErrInvalidExpiration)
+	default:
+		return options.ExpiresAt, nil
+	}
+}
+
+// validateAlias checks that the alias can be used as short URL. Alias must contain only
+// symbols of encoder and separators. Also, it must not be a reserved word.
+//
+// Alias of the short URL length without separators has a form of encoded id, and it
+// could collide with short URL generated in future, so it is not allowed too.
+func (s ShortURLService) validateAlias(alias string) error {
+	if err := validateShortURLSymbols(alias); err != nil {
+		return err
+	}
+
+	if uint(len(alias)) == s.shortURLLength && encoder.IsBaseEncoded(alias) {
+		return fmt.Errorf("%w: alias of %d symbols must contain %q to not collide with generated short urls", ErrInvalidAlias, s.shortURLLength, aliasSeparator)
+	}
+
+	return nil
+}
+
+// validateShortURLSymbols checks that the short URL is not empty, not too long, contains only
+// symbols of encoder and separators and is not a reserved word.
+func validateShortURLSymbols(shortURL string) error {
+	switch {
+	case shortURL == "":
+		return fmt.Errorf("%w: alias is empty", ErrInvalidAlias)
+	case len(shortURL) > maxAliasLength:
+		return fmt.Errorf("%w: alias is longer than %d symbols", ErrInvalidAlias, maxAliasLength)
+	case !encoder.IsBaseEncoded(strings.ReplaceAll(shortURL, aliasSeparator, "")):
+		return fmt.Errorf("%w: alias must contain only latin letters, digits, '_' and %q", ErrInvalidAlias, aliasSeparator)
+	case isReservedAlias(shortURL):
+		return fmt.Errorf("%w: alias %q is reserved", ErrInvalidAlias, shortURL)
+	default:
+		return nil
+	}
+}
+
+func isReservedAlias(alias string) bool {
+	return slices.ContainsFunc(reservedAliases, func(reserved string) bool {
+		return strings.EqualFold(reserved, alias)
+	})
+}
@@ -0,0 +1,40 @@
+package urlservice
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var (
+	// ErrMissingURL is returned by NormalizeURL when original URL is empty.
+	ErrMissingURL = errors.New("missing original url")
+
+	// ErrInvalidURL is returned by NormalizeURL when original URL cannot be a target of redirects.
+	ErrInvalidURL = errors.New("invalid url")
+)
+
+// NormalizeURL returns the original URL in normalized form. It returns ErrMissingURL if
+// the URL is empty or ErrInvalidURL if it is not an absolute URL with scheme http or https
+// and a host, so redirects never resolve it relative to the server. Original URLs are normalized
+// before they are passed to the service by every client, so equal URLs have equal short URLs.
+func NormalizeURL(rawURL string) (string, error) {
+	if rawURL == "" {
+		return "", ErrMissingURL
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidURL, err)
+	}
+
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return "", fmt.Errorf("%w: scheme must be http or https, got %q", ErrInvalidURL, parsedURL.Scheme)
+	}
+
+	if parsedURL.Host == "" {
+		return "", fmt.Errorf("%w: missing host", ErrInvalidURL)
+	}
+
+	return parsedURL.String(), nil
+}
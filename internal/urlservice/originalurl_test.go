@@ -0,0 +1,41 @@
+package urlservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		rawURL        string
+		expected      string
+		expectedError string
+	}{
+		{name: "valid url", rawURL: "https://example.com/path?q=1", expected: "https://example.com/path?q=1"},
+		{name: "escaped path", rawURL: "https://example.com/a b", expected: "https://example.com/a%20b"},
+		{name: "empty url", rawURL: "", expectedError: ErrMissingURL.Error()},
+		{name: "invalid url", rawURL: "https://example.com/%zz", expectedError: "invalid url"},
+		{name: "uppercase scheme", rawURL: "HTTP://example.com/", expected: "http://example.com/"},
+		{name: "relative path", rawURL: "relative/path", expectedError: "invalid url: scheme must be http or https"},
+		{name: "absolute path", rawURL: "/relative", expectedError: "invalid url: scheme must be http or https"},
+		{name: "protocol-relative url", rawURL: "//example.com/path", expectedError: "invalid url: scheme must be http or https"},
+		{name: "javascript url", rawURL: "javascript:alert(1)", expectedError: `scheme must be http or https, got "javascript"`},
+		{name: "missing host", rawURL: "https:///path", expectedError: "invalid url: missing host"},
+		{name: "opaque url", rawURL: "https:example.com", expectedError: "invalid url: missing host"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeURL(tt.rawURL)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
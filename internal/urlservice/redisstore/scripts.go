@@ -0,0 +1,225 @@
+package redisstore
+
+import "github.com/redis/go-redis/v9"
+
+// Scripts change links atomically, because a link is kept in several keys. The prefix is always
+// passed as the first argument, keys are built by scripts from it instead of being declared in KEYS,
+// because keys of owners and short URLs are known only inside scripts. So scripts need a single
+// server and do not support Redis Cluster.
+//
+// commonScript declares keys and helpers shared by all scripts.
+const commonScript = `
+local prefix = ARGV[1]
+local originals = prefix .. 'original_by_encoded'
+local encoded = prefix .. 'encoded_by_original'
+local owners = prefix .. 'owner_by_encoded'
+local expirations = prefix .. 'expiration_by_encoded'
+local disabled = prefix .. 'disabled'
+local links = prefix .. 'links'
+
+local function ownerLinks(owner)
+	return prefix .. 'owner_links:' .. owner
+end
+
+local function changes(short)
+	return prefix .. 'changes:' .. short
+end
+
+local function stats(short)
+	return prefix .. 'stats:' .. short
+end
+
+local function ownedURL(owner, original)
+	return owner .. '\n' .. original
+end
+
+local function setLink(short, original, owner, expiresAt)
+	redis.call('HSET', originals, short, original)
+	redis.call('ZADD', links, 0, short)
+	if owner ~= '' then
+		redis.call('HSET', owners, short, owner)
+		redis.call('ZADD', ownerLinks(owner), 0, short)
+	end
+
+	if expiresAt ~= '' then
+		redis.call('ZADD', expirations, expiresAt, short)
+	end
+end
+
+-- isOwned reports whether the link exists and may be changed by the owner. Owner '*' is urlstore.AnyOwner.
+local function isOwned(short, owner)
+	if redis.call('HEXISTS', originals, short) == 0 then
+		return false
+	end
+
+	return owner == '*' or (redis.call('HGET', owners, short) or '') == owner
+end
+
+local function deleteLink(short)
+	local original = redis.call('HGET', originals, short)
+	if not original then
+		return 0
+	end
+
+	local owner = redis.call('HGET', owners, short) or ''
+	local owned = ownedURL(owner, original)
+	if redis.call('HGET', encoded, owned) == short then
+		redis.call('HDEL', encoded, owned)
+	end
+
+	redis.call('HDEL', originals, short)
+	redis.call('HDEL', owners, short)
+	redis.call('ZREM', expirations, short)
+	redis.call('SREM', disabled, short)
+	redis.call('ZREM', links, short)
+	if owner ~= '' then
+		redis.call('ZREM', ownerLinks(owner), short)
+	end
+
+	redis.call('DEL', changes(short), stats(short))
+	return 1
+end
+`
+
+// saveURLScript returns the owner's saved short URL for the original URL or saves the candidate.
+// It returns nil if the candidate is already saved for another link.
+// Arguments: prefix, owner, original URL, candidate short URL.
+var saveURLScript = redis.NewScript(commonScript + `
+local owned = ownedURL(ARGV[2], ARGV[3])
+local existing = redis.call('HGET', encoded, owned)
+if existing then
+	return existing
+end
+
+if redis.call('HEXISTS', originals, ARGV[4]) == 1 then
+	return false
+end
+
+redis.call('HSET', encoded, owned, ARGV[4])
+setLink(ARGV[4], ARGV[3], ARGV[2], '')
+return ARGV[4]
+`)
+
+// addLinkScript saves the link that is never returned for its original URL, replacing an expired link.
+// It returns "ok" if the link is saved or the same mapping of the same owner exists and has not expired,
+// "taken" if the short URL is mapped with another original URL or owned by another owner and has not expired,
+// "exists" if the short URL is saved and the link must be new.
+// Arguments: prefix, short URL, original URL, owner, expiration in microseconds or empty string,
+// current time in microseconds, "1" if the short URL must be new, "1" if the link is disabled.
+var addLinkScript = redis.NewScript(commonScript + `
+local short = ARGV[2]
+local saved = redis.call('HGET', originals, short)
+if saved then
+	if ARGV[7] == '1' then
+		return 'exists'
+	end
+
+	local expiresAt = redis.call('ZSCORE', expirations, short)
+	if not expiresAt or tonumber(expiresAt) > tonumber(ARGV[6]) then
+		if saved == ARGV[3] and (redis.call('HGET', owners, short) or '') == ARGV[4] then
+			return 'ok'
+		end
+
+		return 'taken'
+	end
+
+	deleteLink(short)
+end
+
+setLink(short, ARGV[3], ARGV[4], ARGV[5])
+if ARGV[8] == '1' then
+	redis.call('SADD', disabled, short)
+end
+
+return 'ok'
+`)
+
+// deleteScript removes the link and everything kept by its short URL. It returns 0 if the link is not found
+// or is owned by another owner.
+// Arguments: prefix, short URL, owner.
+var deleteScript = redis.NewScript(commonScript + `
+if not isOwned(ARGV[2], ARGV[3]) then
+	return 0
+end
+
+return deleteLink(ARGV[2])
+`)
+
+// deleteExpiredScript removes links expired at the moment and returns their count.
+// Arguments: prefix, moment in microseconds.
+var deleteExpiredScript = redis.NewScript(commonScript + `
+local expired = redis.call('ZRANGEBYSCORE', expirations, '-inf', ARGV[2])
+for _, short in ipairs(expired) do
+	deleteLink(short)
+end
+
+return #expired
+`)
+
+// setDisabledScript disables or enables the link. It returns 0 if the link is not found or is owned
+// by another owner.
+// Arguments: prefix, short URL, "1" to disable or "0" to enable, owner.
+var setDisabledScript = redis.NewScript(commonScript + `
+if not isOwned(ARGV[2], ARGV[4]) then
+	return 0
+end
+
+if ARGV[3] == '1' then
+	redis.call('SADD', disabled, ARGV[2])
+else
+	redis.call('SREM', disabled, ARGV[2])
+end
+
+return 1
+`)
+
+// updateOriginalURLScript maps the short URL with another original URL and records the change
+// as JSON. It returns 0 if the link is not found or is owned by another owner.
+// Arguments: prefix, short URL, new original URL, current time in microseconds, owner.
+var updateOriginalURLScript = redis.NewScript(commonScript + `
+local short = ARGV[2]
+if not isOwned(short, ARGV[5]) then
+	return 0
+end
+
+local previous = redis.call('HGET', originals, short)
+
+if previous == ARGV[3] then
+	return 1
+end
+
+local owned = ownedURL(redis.call('HGET', owners, short) or '', previous)
+if redis.call('HGET', encoded, owned) == short then
+	redis.call('HDEL', encoded, owned)
+end
+
+redis.call('HSET', originals, short, ARGV[3])
+redis.call('RPUSH', changes(short), cjson.encode({
+	previous_url = previous,
+	new_url = ARGV[3],
+	changed_at = tonumber(ARGV[4]),
+}))
+
+return 1
+`)
+
+// addClickStatsScript adds clicks to statistics of the short URL if it exists.
+// Arguments: prefix, short URL, clicks, last click time in microseconds, then pairs of count field and clicks.
+var addClickStatsScript = redis.NewScript(commonScript + `
+if redis.call('HEXISTS', originals, ARGV[2]) == 0 then
+	return 0
+end
+
+local key = stats(ARGV[2])
+redis.call('HINCRBY', key, 'clicks', ARGV[3])
+local last = redis.call('HGET', key, 'last_click_at')
+if not last or tonumber(last) < tonumber(ARGV[4]) then
+	redis.call('HSET', key, 'last_click_at', ARGV[4])
+end
+
+for i = 5, #ARGV, 2 do
+	redis.call('HINCRBY', key, ARGV[i], ARGV[i + 1])
+end
+
+return 1
+`)
@@ -0,0 +1,520 @@
+// Package redisstore provides a URL storage in Redis or another server speaking its protocol.
+//
+// It allows for the storing and retrieval of original URLs using
+// encoded keys. The storage can be shared by several instances of the program.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	// DefaultKeyPrefix is a prefix of all keys of the storage.
+	DefaultKeyPrefix = "{shorturl}:"
+
+	// maxEncodingAttempts limits count of ids tried to encode a short URL that is not saved yet.
+	maxEncodingAttempts = 100
+	// linksPageSize is a count of links read at once while iterating over all links.
+	linksPageSize = 1000
+)
+
+// RedisStorage is a URL storage using Redis.
+//
+// Like memstore, it maps both original URLs by short URLs and short URLs by owned original URLs
+// in two hashes. Owners are mapped by short URLs in a hash, expiration times are kept in a sorted
+// set scored by time, short URLs of disabled links are kept in a set. Short URLs of all links and
+// of links of every owner are kept in sorted sets to read them in order. Changes of original URLs
+// and click statistics are kept in keys of their short URLs. IDs to encode short URLs are taken
+// from an atomic counter. Changes of links are made by scripts, so they are atomic. Scripts build
+// keys themselves, so the storage needs a single server and does not support Redis Cluster.
+//
+// The zero value is not useful, you must use NewRedisStorage to create an instance.
+type RedisStorage struct {
+	client         redis.UniversalClient
+	prefix         string
+	idEncoder      encoder.IDEncoder
+	shortURLLength uint
+}
+
+// NewRedisStorage initializes a new RedisStorage instance with the given client, prefix of keys,
+// ID encoder, and the specified length for short URLs. It returns a pointer to created object.
+func NewRedisStorage(client redis.UniversalClient, prefix string, idEncoder encoder.IDEncoder, shortURLLength uint) *RedisStorage {
+	return &RedisStorage{
+		client:         client,
+		prefix:         prefix,
+		idEncoder:      idEncoder,
+		shortURLLength: shortURLLength,
+	}
+}
+
+// Link is looking for the link by passed short URL.
+// If the short URL does not exist in the storage, it returns an error wrapping urlstore.ErrNotFound.
+func (s *RedisStorage) Link(ctx context.Context, shortURL string) (urlstore.Link, error) {
+	links, err := s.Links(ctx, []string{shortURL})
+	if err != nil {
+		return urlstore.Link{}, err
+	}
+
+	if len(links) == 0 {
+		return urlstore.Link{}, notFoundError(shortURL)
+	}
+
+	return links[0], nil
+}
+
+// ShortURL should always return the owner's short URL for provided original URL value.
+//
+// At first, it tries to find saved value, but if it does not exist, it encodes the
+// original URL by incremented ID and saves it, unless another client has already saved
+// the original URL, then its short URL is returned.
+//
+// It might return an error if all encoded short URLs already exist in storage
+// or if encoded value has an incorrect length.
+func (s *RedisStorage) ShortURL(ctx context.Context, owner, originalURL string) (string, error) {
+	shortURL, err := s.client.HGet(ctx, s.key("encoded_by_original"), ownedURL(owner, originalURL)).Result()
+	if err == nil {
+		return shortURL, nil
+	}
+
+	if !errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("failed to get short url of %q url from redis: %w", originalURL, err)
+	}
+
+	return s.saveNewURL(ctx, owner, originalURL)
+}
+
+// ShortURLs returns the owner's short URLs for all provided original URLs in the same order.
+// Like ShortURL, it returns saved values or encodes new ones.
+// If encoding of any short URL fails, it returns an error, but short URLs encoded before are kept.
+func (s *RedisStorage) ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error) {
+	shortURLs := make([]string, 0, len(originalURLs))
+	for _, originalURL := range originalURLs {
+		shortURL, err := s.ShortURL(ctx, owner, originalURL)
+		if err != nil {
+			return nil, err
+		}
+
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	return shortURLs, nil
+}
+
+// Links is looking for links by passed short URLs in a single transaction.
+// Short URLs that do not exist in the storage are skipped.
+func (s *RedisStorage) Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error) {
+	type linkCommands struct {
+		original  *redis.StringCmd
+		owner     *redis.StringCmd
+		expiresAt *redis.FloatCmd
+		disabled  *redis.BoolCmd
+	}
+
+	commands := make([]linkCommands, len(shortURLs))
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, shortURL := range shortURLs {
+			commands[i] = linkCommands{
+				original:  pipe.HGet(ctx, s.key("original_by_encoded"), shortURL),
+				owner:     pipe.HGet(ctx, s.key("owner_by_encoded"), shortURL),
+				expiresAt: pipe.ZScore(ctx, s.key("expiration_by_encoded"), shortURL),
+				disabled:  pipe.SIsMember(ctx, s.key("disabled"), shortURL),
+			}
+		}
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("failed to get %d short urls from redis: %w", len(shortURLs), err)
+	}
+
+	links := make([]urlstore.Link, 0, len(shortURLs))
+	for i, shortURL := range shortURLs {
+		originalURL, err := commands[i].original.Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		link := urlstore.Link{
+			ShortURL:    shortURL,
+			OriginalURL: originalURL,
+			Owner:       commands[i].owner.Val(),
+			Disabled:    commands[i].disabled.Val(),
+		}
+
+		if expiresAt, err := commands[i].expiresAt.Result(); err == nil {
+			link.ExpiresAt = time.UnixMicro(int64(expiresAt))
+		}
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// AddLink saves the link that is never returned by ShortURL and returns its short URL.
+// If the link has no short URL, it encodes a new one by incremented ID.
+// The link is saved disabled at once if it is disabled.
+//
+// If the short URL is mapped with an expired link, the link is replaced and its click statistics are removed.
+// Otherwise, if it is already mapped with the same original URL of the same owner, it does nothing, the saved
+// link is not changed, and if it is mapped with another original URL or owned by another owner, it returns
+// an error wrapping urlstore.ErrShortURLTaken.
+func (s *RedisStorage) AddLink(ctx context.Context, link urlstore.Link) (string, error) {
+	if link.ShortURL == "" {
+		return s.saveNewLink(ctx, link)
+	}
+
+	result, err := s.addLink(ctx, link, false)
+	if err != nil {
+		return "", err
+	}
+
+	if result == "taken" {
+		return "", fmt.Errorf("%w: %q in redis", urlstore.ErrShortURLTaken, link.ShortURL)
+	}
+
+	return link.ShortURL, nil
+}
+
+// ForEachLink calls fn for every link saved in the storage in order of short URLs. Links are
+// read by pages, so links changed during iteration may be missed or returned in their new state.
+// It stops on the first error returned by fn and returns it.
+func (s *RedisStorage) ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error {
+	var lastShortURL string
+	for {
+		page, err := s.linksPage(ctx, s.key("links"), lastShortURL, linksPageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range page {
+			if err := fn(link); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < linksPageSize {
+			return nil
+		}
+
+		lastShortURL = page[len(page)-1].ShortURL
+	}
+}
+
+// OwnerLinks returns up to limit links of the owner with short URLs greater than
+// the passed one in order of short URLs. Pass empty short URL to get the first page.
+func (s *RedisStorage) OwnerLinks(ctx context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error) {
+	if owner == "" {
+		return []urlstore.Link{}, nil
+	}
+
+	return s.linksPage(ctx, s.key("owner_links:"+owner), afterShortURL, limit)
+}
+
+// DeleteExpired removes all links that have expired at the moment and returns their count.
+func (s *RedisStorage) DeleteExpired(ctx context.Context, moment time.Time) (int64, error) {
+	count, err := deleteExpiredScript.Run(ctx, s.client, nil, s.prefix, moment.UnixMicro()).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired urls from redis: %w", err)
+	}
+
+	return count, nil
+}
+
+// Delete removes the link with its click statistics. If the link is returned by ShortURL
+// for its original URL, the next call of ShortURL encodes a new short URL.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *RedisStorage) Delete(ctx context.Context, owner, shortURL string) error {
+	isDeleted, err := deleteScript.Run(ctx, s.client, nil, s.prefix, shortURL, owner).Bool()
+	if err != nil {
+		return fmt.Errorf("failed to delete %q url from redis: %w", shortURL, err)
+	}
+
+	if !isDeleted {
+		return notFoundError(shortURL)
+	}
+
+	return nil
+}
+
+// SetDisabled disables or enables the link. Disabled link is still returned by ShortURL
+// for its original URL, so it is not replaced with a new one while it is disabled.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *RedisStorage) SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error {
+	isFound, err := setDisabledScript.Run(ctx, s.client, nil, s.prefix, shortURL, flag(disabled), owner).Bool()
+	if err != nil {
+		return fmt.Errorf("failed to set status of %q url in redis: %w", shortURL, err)
+	}
+
+	if !isFound {
+		return notFoundError(shortURL)
+	}
+
+	return nil
+}
+
+// UpdateOriginalURL maps the short URL with another original URL and records the change.
+// Updated link is not returned by ShortURL for any original URL anymore. Its expiration,
+// status and click statistics are kept. If the original URL is the same, it does nothing.
+// If the short URL does not exist in the storage or the link is owned by another owner,
+// it returns an error wrapping urlstore.ErrNotFound, unless the owner is urlstore.AnyOwner.
+func (s *RedisStorage) UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error {
+	now := time.Now().UnixMicro()
+	isFound, err := updateOriginalURLScript.Run(ctx, s.client, nil, s.prefix, shortURL, originalURL, now, owner).Bool()
+	if err != nil {
+		return fmt.Errorf("failed to update %q url in redis: %w", shortURL, err)
+	}
+
+	if !isFound {
+		return notFoundError(shortURL)
+	}
+
+	return nil
+}
+
+// destinationChange is a change of original URL saved as JSON.
+type destinationChange struct {
+	PreviousURL string `json:"previous_url"`
+	NewURL      string `json:"new_url"`
+	ChangedAt   int64  `json:"changed_at"`
+}
+
+// History returns changes of original URL mapped with the short URL, the oldest first.
+func (s *RedisStorage) History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error) {
+	rawChanges, err := s.client.LRange(ctx, s.key("changes:"+shortURL), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of %q url from redis: %w", shortURL, err)
+	}
+
+	changes := make([]urlstore.DestinationChange, 0, len(rawChanges))
+	for _, rawChange := range rawChanges {
+		var change destinationChange
+		if err := json.Unmarshal([]byte(rawChange), &change); err != nil {
+			return nil, fmt.Errorf("failed to read history of %q url from redis: %w", shortURL, err)
+		}
+
+		changes = append(changes, urlstore.DestinationChange{
+			ShortURL:    shortURL,
+			PreviousURL: change.PreviousURL,
+			NewURL:      change.NewURL,
+			ChangedAt:   time.UnixMicro(change.ChangedAt),
+		})
+	}
+
+	return changes, nil
+}
+
+const (
+	clicksField      = "clicks"
+	lastClickAtField = "last_click_at"
+	countFieldPrefix = "count:"
+)
+
+// AddClickStats adds clicks to statistics of their short URLs in a single pipeline.
+// Clicks of short URLs that do not exist in the storage are ignored.
+func (s *RedisStorage) AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, toAdd := range stats {
+			args := []interface{}{s.prefix, toAdd.ShortURL, toAdd.Clicks, toAdd.LastClickAt.UnixMicro()}
+			for dimension, counts := range toAdd.Counts {
+				for value, clicks := range counts {
+					args = append(args, countField(dimension, value), clicks)
+				}
+			}
+
+			addClickStatsScript.Eval(ctx, pipe, nil, args...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save click stats in redis: %w", err)
+	}
+
+	return nil
+}
+
+// ClickStats returns click statistics of the short URL.
+// If the short URL has never been clicked, it returns empty statistics.
+func (s *RedisStorage) ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error) {
+	fields, err := s.client.HGetAll(ctx, s.key("stats:"+shortURL)).Result()
+	if err != nil {
+		return urlstore.ClickStats{}, fmt.Errorf("failed to get stats of %q url from redis: %w", shortURL, err)
+	}
+
+	stats := urlstore.NewClickStats(shortURL)
+	for field, rawValue := range fields {
+		value, err := strconv.ParseInt(rawValue, 10, 64)
+		if err != nil {
+			return urlstore.ClickStats{}, fmt.Errorf("failed to read stats of %q url from redis: %w", shortURL, err)
+		}
+
+		switch field {
+		case clicksField:
+			stats.Clicks = value
+		case lastClickAtField:
+			stats.LastClickAt = time.UnixMicro(value)
+		default:
+			dimension, dimensionValue, isCount := parseCountField(field)
+			if isCount {
+				stats.AddCount(dimension, dimensionValue, value)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// Ping checks that the server responds.
+func (s *RedisStorage) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// linksPage returns up to limit links from the sorted set of short URLs with short URLs greater than the last one.
+func (s *RedisStorage) linksPage(ctx context.Context, key, lastShortURL string, limit int) ([]urlstore.Link, error) {
+	minShortURL := "-"
+	if lastShortURL != "" {
+		minShortURL = "(" + lastShortURL
+	}
+
+	shortURLs, err := s.client.ZRangeByLex(ctx, key, &redis.ZRangeBy{
+		Min:   minShortURL,
+		Max:   "+",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get links after %q from redis: %w", lastShortURL, err)
+	}
+
+	return s.Links(ctx, shortURLs)
+}
+
+// saveNewURL encodes short URLs by incremented IDs until one of them is saved for the original URL
+// or another client saves the original URL first.
+func (s *RedisStorage) saveNewURL(ctx context.Context, owner, originalURL string) (string, error) {
+	for range maxEncodingAttempts {
+		candidate, err := s.encodeNextID(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		shortURL, err := saveURLScript.Run(ctx, s.client, nil, s.prefix, owner, originalURL, candidate).Text()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("failed to save %q url in redis: %w", originalURL, err)
+		}
+
+		return shortURL, nil
+	}
+
+	return "", errors.New("encoded url is not unique")
+}
+
+// saveNewLink encodes short URLs by incremented IDs until one of them is saved for the link.
+func (s *RedisStorage) saveNewLink(ctx context.Context, link urlstore.Link) (string, error) {
+	for range maxEncodingAttempts {
+		candidate, err := s.encodeNextID(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		link.ShortURL = candidate
+		result, err := s.addLink(ctx, link, true)
+		if err != nil {
+			return "", err
+		}
+
+		if result == "ok" {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.New("encoded url is not unique")
+}
+
+func (s *RedisStorage) addLink(ctx context.Context, link urlstore.Link, mustBeNew bool) (string, error) {
+	var expiresAt string
+	if !link.ExpiresAt.IsZero() {
+		expiresAt = strconv.FormatInt(link.ExpiresAt.UnixMicro(), 10)
+	}
+
+	result, err := addLinkScript.Run(ctx, s.client, nil, s.prefix, link.ShortURL, link.OriginalURL, link.Owner,
+		expiresAt, time.Now().UnixMicro(), flag(mustBeNew), flag(link.Disabled)).Text()
+	if err != nil {
+		return "", fmt.Errorf("failed to save link of %q url in redis: %w", link.OriginalURL, err)
+	}
+
+	return result, nil
+}
+
+// encodeNextID increments the counter of IDs and encodes it.
+// It returns an error if encoded value has an incorrect length.
+func (s *RedisStorage) encodeNextID(ctx context.Context) (string, error) {
+	id, err := s.client.Incr(ctx, s.key("id")).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to get next id from redis: %w", err)
+	}
+
+	shortURL := s.idEncoder.EncodeID(uint(id), s.shortURLLength)
+	if len(shortURL) != int(s.shortURLLength) {
+		return "", fmt.Errorf("unexpected length of encoded url, expected=%d, actual=%d", s.shortURLLength, len(shortURL))
+	}
+
+	return shortURL, nil
+}
+
+func (s *RedisStorage) key(name string) string {
+	return s.prefix + name
+}
+
+// ownedURL is a field of the hash of short URLs by owned original URLs. Owners do not contain
+// line breaks, so the field is unique for every pair.
+func ownedURL(owner, originalURL string) string {
+	return owner + "\n" + originalURL
+}
+
+func countField(dimension urlstore.ClickDimension, value string) string {
+	return countFieldPrefix + string(dimension) + ":" + value
+}
+
+func parseCountField(field string) (urlstore.ClickDimension, string, bool) {
+	rest, isCount := strings.CutPrefix(field, countFieldPrefix)
+	if !isCount {
+		return "", "", false
+	}
+
+	dimension, value, isCount := strings.Cut(rest, ":")
+	return urlstore.ClickDimension(dimension), value, isCount
+}
+
+func flag(value bool) string {
+	if value {
+		return "1"
+	}
+
+	return "0"
+}
+
+func notFoundError(shortURL string) error {
+	return fmt.Errorf("%w: %q in redis", urlstore.ErrNotFound, shortURL)
+}
@@ -0,0 +1,57 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/storagetest"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func newTestStorage(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) (*RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return NewRedisStorage(client, DefaultKeyPrefix, idEncoder, shortURLLength), server
+}
+
+func TestRedisStorage_Contract(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) storagetest.Storage {
+		storage, _ := newTestStorage(t, idEncoder, shortURLLength)
+		return storage
+	})
+}
+
+func TestRedisStorage_Delete_RemovesKeys(t *testing.T) {
+	sut, server := newTestStorage(t, encoder.NewIDEncoder(), storagetest.ShortURLLength)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, sut.AddClickStats(ctx, []urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1}}))
+	require.NoError(t, sut.UpdateOriginalURL(ctx, "owner", shortURL, "https://example.org"))
+
+	require.NoError(t, sut.Delete(ctx, "owner", shortURL))
+
+	assert.Equal(t, []string{DefaultKeyPrefix + "id"}, server.Keys())
+}
+
+func TestRedisStorage_Ping(t *testing.T) {
+	sut, server := newTestStorage(t, encoder.NewIDEncoder(), storagetest.ShortURLLength)
+
+	require.NoError(t, sut.Ping(context.Background()))
+
+	server.Close()
+	assert.Error(t, sut.Ping(context.Background()))
+}
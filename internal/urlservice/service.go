@@ -5,49 +5,264 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"time"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/analytics"
+	"shorturl/internal/urlservice/urlstore"
 )
 
 type urlStorage interface {
-	OriginalURL(ctx context.Context, shortURL string) (string, error)
-	ShortURL(ctx context.Context, originalURL string) (string, error)
+	Link(ctx context.Context, shortURL string) (urlstore.Link, error)
+	ShortURL(ctx context.Context, owner, originalURL string) (string, error)
+	AddLink(ctx context.Context, link urlstore.Link) (string, error)
+	ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error)
+	Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error)
+	OwnerLinks(ctx context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error)
+	ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error
+	DeleteExpired(ctx context.Context, moment time.Time) (int64, error)
+	Delete(ctx context.Context, owner, shortURL string) error
+	SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error
+	UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error
+	History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error)
+	AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error
+	ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error)
+	Ping(ctx context.Context) error
 }
 
+// DestinationChange is a record of the change of original URL mapped with short URL.
+type DestinationChange struct {
+	PreviousURL string
+	NewURL      string
+	ChangedAt   time.Time
+}
+
+// AnyOwner is passed to the service instead of the owner to manage a short URL of any owner.
+// It is used by administrative commands.
+const AnyOwner = urlstore.AnyOwner
+
 // ShortURLService is a service to manipulate with selected URL storage.
 //
 // It must be initialized with NewShortURLService to set desired storage.
 type ShortURLService struct {
-	storage urlStorage
+	storage        urlStorage
+	shortURLLength uint
+	clickRecorder  *analytics.Recorder
+	metrics        Metrics
 }
 
-// NewShortURLService initializes a new ShortURLService instance with a storage, chosen with StorageOptionFunc.
-// It also takes id encoder and length of short URL to set up storage.
-func NewShortURLService(idEncoder encoder.IDEncoder, shortURLLength uint, storageOption StorageOptionFunc) ShortURLService {
-	storage := storageOption(idEncoder, shortURLLength)
-	return ShortURLService{storage}
+// NewShortURLService initializes a new ShortURLService instance with a storage, chosen with StorageOptionFunc,
+// and optional settings. It also takes id encoder and length of short URL to set up storage.
+//
+// Clicks are saved to the storage by recorder, that must be started with RunClickRecorder.
+func NewShortURLService(idEncoder encoder.IDEncoder, shortURLLength uint, storageOption StorageOptionFunc, options ...ServiceOption) ShortURLService {
+	service := ShortURLService{
+		storage:        storageOption(idEncoder, shortURLLength),
+		shortURLLength: shortURLLength,
+	}
+
+	for _, option := range options {
+		option(&service)
+	}
+
+	service.clickRecorder = analytics.NewRecorder(service.storage, clicksBufferSize, clicksFlushInterval)
+	return service
 }
 
-// ErrURLNotFound is returned when provided short URL not maps with any original URL.
-var ErrURLNotFound = errors.New("requested short url has no matches")
+var (
+	// ErrURLNotFound is returned when provided short URL not maps with any original URL.
+	ErrURLNotFound = errors.New("requested short url has no matches")
+
+	// ErrInvalidAlias is returned when provided alias cannot be used as short URL.
+	ErrInvalidAlias = errors.New("invalid alias")
 
-// OriginalURL calls method OriginalURL in his storage and returns ErrURLNotFound if
-// the method returned an error.
+	// ErrAliasTaken is returned when provided alias is already mapped with another original URL
+	// or owned by another owner.
+	ErrAliasTaken = errors.New("requested alias is already taken")
+
+	// ErrInvalidExpiration is returned when provided expiration settings cannot be used for short URL.
+	ErrInvalidExpiration = errors.New("invalid expiration")
+
+	// ErrURLExpired is returned when provided short URL was mapped with original URL, but it has expired.
+	ErrURLExpired = errors.New("requested short url has expired")
+
+	// ErrURLDisabled is returned when provided short URL is mapped with original URL, but it is disabled.
+	ErrURLDisabled = errors.New("requested short url is disabled")
+)
+
+// OriginalURL calls method Link in his storage and returns ErrURLNotFound if
+// the method returned an error. If the link is disabled, it returns ErrURLDisabled,
+// if it has expired, it returns ErrURLExpired.
 func (s ShortURLService) OriginalURL(ctx context.Context, shortURL string) (string, error) {
-	original, err := s.storage.OriginalURL(ctx, shortURL)
+	link, err := s.storage.Link(ctx, shortURL)
 	if err != nil {
 		return "", errors.Join(ErrURLNotFound, err)
 	}
 
-	return original, nil
+	if link.Disabled {
+		return "", fmt.Errorf("%w: %q", ErrURLDisabled, shortURL)
+	}
+
+	if link.IsExpired(time.Now()) {
+		return "", fmt.Errorf("%w: %q", ErrURLExpired, shortURL)
+	}
+
+	s.addLinksResolved(1)
+	return link.OriginalURL, nil
 }
 
-// ShortURL calls method ShortURL in his storage.
-func (s ShortURLService) ShortURL(ctx context.Context, originalURL string) (string, error) {
-	short, err := s.storage.ShortURL(ctx, originalURL)
+// ShortURL returns short URL for the original URL. Without options except owner, it calls method
+// ShortURL in his storage, so the same short URL is returned to the same owner for the same original
+// URL. Otherwise, it validates options and calls method AddLink in his storage to create a new short URL.
+//
+// It returns ErrInvalidAlias or ErrInvalidExpiration if options cannot be used, and
+// ErrAliasTaken if the alias is mapped with another original URL or owned by another owner.
+func (s ShortURLService) ShortURL(ctx context.Context, originalURL string, options LinkOptions) (string, error) {
+	if options.isReusable() {
+		short, err := s.storage.ShortURL(ctx, options.Owner, originalURL)
+		if err != nil {
+			return "", fmt.Errorf("failed to insert or get short url for url %q: %w", originalURL, err)
+		}
+
+		s.addLinksCreated(1)
+		return short, nil
+	}
+
+	link, err := s.newLink(originalURL, options)
+	if err != nil {
+		return "", err
+	}
+
+	short, err := s.storage.AddLink(ctx, link)
+	if errors.Is(err, urlstore.ErrShortURLTaken) {
+		return "", errors.Join(ErrAliasTaken, err)
+	}
+
 	if err != nil {
-		return "", fmt.Errorf("failed to insert or get short url for url %q: %w", originalURL, err)
+		return "", fmt.Errorf("failed to insert short url for url %q: %w", originalURL, err)
 	}
 
+	s.addLinksCreated(1)
 	return short, nil
 }
+
+// Delete calls method Delete in his storage to remove the owner's short URL with its statistics.
+// It returns ErrURLNotFound if the short URL is not found in his storage or is owned by another owner.
+// Pass AnyOwner to remove a short URL of any owner.
+func (s ShortURLService) Delete(ctx context.Context, owner, shortURL string) error {
+	return storageChangeError(s.storage.Delete(ctx, owner, shortURL), shortURL)
+}
+
+// Disable calls method SetDisabled in his storage, so the owner's short URL is not resolved until it is
+// enabled. It returns ErrURLNotFound if the short URL is not found in his storage or is owned by another owner.
+// Pass AnyOwner to disable a short URL of any owner.
+func (s ShortURLService) Disable(ctx context.Context, owner, shortURL string) error {
+	return storageChangeError(s.storage.SetDisabled(ctx, owner, shortURL, true), shortURL)
+}
+
+// Enable calls method SetDisabled in his storage, so the owner's disabled short URL is resolved again.
+// It returns ErrURLNotFound if the short URL is not found in his storage or is owned by another owner.
+// Pass AnyOwner to enable a short URL of any owner.
+func (s ShortURLService) Enable(ctx context.Context, owner, shortURL string) error {
+	return storageChangeError(s.storage.SetDisabled(ctx, owner, shortURL, false), shortURL)
+}
+
+// UpdateOriginalURL calls method UpdateOriginalURL in his storage to map the owner's short URL with
+// another original URL. Click statistics of the short URL are kept and the previous original URL is
+// recorded in its history. It returns ErrURLNotFound if the short URL is not found in his storage
+// or is owned by another owner. Pass AnyOwner to update a short URL of any owner.
+func (s ShortURLService) UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error {
+	return storageChangeError(s.storage.UpdateOriginalURL(ctx, owner, shortURL, originalURL), shortURL)
+}
+
+// History returns changes of original URL mapped with the owner's short URL, the oldest first.
+// It returns ErrURLNotFound if the short URL is not found in his storage or is owned by another owner.
+func (s ShortURLService) History(ctx context.Context, owner, shortURL string) ([]DestinationChange, error) {
+	if err := s.checkOwner(ctx, owner, shortURL); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.storage.History(ctx, shortURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history of short url %q: %w", shortURL, err)
+	}
+
+	result := make([]DestinationChange, 0, len(changes))
+	for _, change := range changes {
+		result = append(result, DestinationChange{
+			PreviousURL: change.PreviousURL,
+			NewURL:      change.NewURL,
+			ChangedAt:   change.ChangedAt,
+		})
+	}
+
+	return result, nil
+}
+
+// checkOwner returns ErrURLNotFound if the short URL is not found in his storage or is owned
+// by another owner, so links of other owners cannot be told apart from missing ones.
+// Empty owner means anonymous links, AnyOwner means links of any owner.
+func (s ShortURLService) checkOwner(ctx context.Context, owner, shortURL string) error {
+	link, err := s.storage.Link(ctx, shortURL)
+	if err != nil {
+		return errors.Join(ErrURLNotFound, err)
+	}
+
+	if !link.IsOwnedBy(owner) {
+		return fmt.Errorf("%w: %q", ErrURLNotFound, shortURL)
+	}
+
+	return nil
+}
+
+// Ping checks that the storage is available to handle requests.
+func (s ShortURLService) Ping(ctx context.Context) error {
+	if err := s.storage.Ping(ctx); err != nil {
+		return fmt.Errorf("storage is unavailable: %w", err)
+	}
+
+	return nil
+}
+
+// storageChangeError returns ErrURLNotFound if the error of storage change means that
+// the short URL is not found, otherwise it wraps the error.
+func storageChangeError(err error, shortURL string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, urlstore.ErrNotFound) {
+		return errors.Join(ErrURLNotFound, err)
+	}
+
+	return fmt.Errorf("failed to change short url %q: %w", shortURL, err)
+}
+
+// PurgeExpiredURLs calls method DeleteExpired in his storage every interval until
+// the context is done. Errors are logged, so the next attempt is made anyway.
+func (s ShortURLService) PurgeExpiredURLs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.purgeExpiredURLs(ctx)
+		}
+	}
+}
+
+func (s ShortURLService) purgeExpiredURLs(ctx context.Context) {
+	deletedCount, err := s.storage.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		slog.Error("Failed to purge expired urls", slog.String("error", err.Error()))
+		return
+	}
+
+	if deletedCount != 0 {
+		slog.Info("Expired urls purged", slog.Int64("count", deletedCount))
+	}
+}
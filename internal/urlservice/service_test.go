@@ -3,13 +3,18 @@ package urlservice
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
 )
 
 func TestNewShortURLService(t *testing.T) {
@@ -49,6 +54,8 @@ func TestNewShortURLService(t *testing.T) {
 func TestShortURLService_OriginalURL(t *testing.T) {
 	tests := []struct {
 		name          string
+		expiresAt     time.Time
+		disabled      bool
 		wantError     bool
 		expectedError error
 	}{
@@ -56,24 +63,41 @@ func TestShortURLService_OriginalURL(t *testing.T) {
 			name:      "no error",
 			wantError: false,
 		},
+		{
+			name:          "disabled",
+			disabled:      true,
+			wantError:     true,
+			expectedError: ErrURLDisabled,
+		},
+		{
+			name:      "not expired",
+			expiresAt: time.Now().Add(time.Hour),
+			wantError: false,
+		},
 		{
 			name:          "error",
 			wantError:     true,
 			expectedError: ErrURLNotFound,
 		},
+		{
+			name:          "expired",
+			expiresAt:     time.Now().Add(-time.Hour),
+			wantError:     true,
+			expectedError: ErrURLExpired,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			storageMock := NewMockurlStorage(t)
 			storageMock.EXPECT().
-				OriginalURL(mock.Anything, mock.Anything).
-				RunAndReturn(func(_ context.Context, _ string) (string, error) {
-					if tt.wantError {
-						return "", errors.New("some error")
+				Link(mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, _ string) (urlstore.Link, error) {
+					if tt.wantError && tt.expiresAt.IsZero() && !tt.disabled {
+						return urlstore.Link{}, errors.New("some error")
 					}
 
-					return "", nil
+					return urlstore.Link{ExpiresAt: tt.expiresAt, Disabled: tt.disabled}, nil
 				}).
 				Once()
 
@@ -114,8 +138,8 @@ func TestShortURLService_ShortURL(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			storageMock := NewMockurlStorage(t)
 			storageMock.EXPECT().
-				ShortURL(mock.Anything, mock.Anything).
-				RunAndReturn(func(_ context.Context, _ string) (string, error) {
+				ShortURL(mock.Anything, mock.Anything, mock.Anything).
+				RunAndReturn(func(_ context.Context, _, _ string) (string, error) {
 					if tt.wantError {
 						return "", errors.New("some error")
 					}
@@ -127,7 +151,7 @@ func TestShortURLService_ShortURL(t *testing.T) {
 				storage: storageMock,
 			}
 
-			_, err := sut.ShortURL(context.Background(), "123")
+			_, err := sut.ShortURL(context.Background(), "123", LinkOptions{})
 			if !tt.wantError {
 				assert.NoError(t, err)
 				return
@@ -137,3 +161,376 @@ func TestShortURLService_ShortURL(t *testing.T) {
 		})
 	}
 }
+
+func TestShortURLService_ShortURL_WithOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		options       LinkOptions
+		storageError  error
+		callsStorage  bool
+		expectedError error
+	}{
+		{
+			name:         "alias with separator",
+			options:      LinkOptions{Alias: "launch-2026"},
+			callsStorage: true,
+		},
+		{
+			name:         "alias of other length than short url",
+			options:      LinkOptions{Alias: "launch"},
+			callsStorage: true,
+		},
+		{
+			name:          "too long alias",
+			options:       LinkOptions{Alias: strings.Repeat("a", maxAliasLength+1)},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "alias with not allowed symbol",
+			options:       LinkOptions{Alias: "launch/2026"},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "reserved alias",
+			options:       LinkOptions{Alias: "Metrics"},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "alias in form of generated short url",
+			options:       LinkOptions{Alias: "launch2026"},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "alias is taken",
+			options:       LinkOptions{Alias: "launch-2026"},
+			storageError:  urlstore.ErrShortURLTaken,
+			callsStorage:  true,
+			expectedError: ErrAliasTaken,
+		},
+		{
+			name:         "storage error",
+			options:      LinkOptions{Alias: "launch-2026"},
+			storageError: errors.New("some error"),
+			callsStorage: true,
+		},
+		{
+			name:         "expiration time",
+			options:      LinkOptions{ExpiresAt: time.Now().Add(time.Hour)},
+			callsStorage: true,
+		},
+		{
+			name:         "ttl with alias",
+			options:      LinkOptions{Alias: "launch-2026", TTL: time.Hour},
+			callsStorage: true,
+		},
+		{
+			name:          "expiration time in the past",
+			options:       LinkOptions{ExpiresAt: time.Now().Add(-time.Hour)},
+			expectedError: ErrInvalidExpiration,
+		},
+		{
+			name:          "negative ttl",
+			options:       LinkOptions{TTL: -time.Hour},
+			expectedError: ErrInvalidExpiration,
+		},
+		{
+			name:          "both expiration time and ttl",
+			options:       LinkOptions{ExpiresAt: time.Now().Add(time.Hour), TTL: time.Hour},
+			expectedError: ErrInvalidExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const generatedShortURL = "1111111111"
+
+			storageMock := NewMockurlStorage(t)
+			if tt.callsStorage {
+				storageMock.EXPECT().
+					AddLink(mock.Anything, mock.Anything).
+					RunAndReturn(func(_ context.Context, link urlstore.Link) (string, error) {
+						assert.Equal(t, tt.options.Alias, link.ShortURL)
+						assert.False(t, link.ExpiresAt.IsZero() && (tt.options.TTL != 0 || !tt.options.ExpiresAt.IsZero()), "Expiration was not set")
+						if tt.storageError != nil {
+							return "", tt.storageError
+						}
+
+						if link.ShortURL == "" {
+							return generatedShortURL, nil
+						}
+
+						return link.ShortURL, nil
+					}).
+					Once()
+			}
+
+			sut := ShortURLService{
+				storage:        storageMock,
+				shortURLLength: 10,
+			}
+
+			result, err := sut.ShortURL(context.Background(), "https://example.com/", tt.options)
+			if tt.storageError == nil && tt.expectedError == nil {
+				require.NoError(t, err)
+				if tt.options.Alias != "" {
+					assert.Equal(t, tt.options.Alias, result)
+				}
+
+				return
+			}
+
+			require.Error(t, err)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestShortURLService_ShortURL_ExpiredAlias(t *testing.T) {
+	ctx := context.Background()
+	sut := NewShortURLService(encoder.NewIDEncoder(), 10, WithInMemoryStorage())
+
+	shortURL, err := sut.ShortURL(ctx, "https://example.com", LinkOptions{Alias: "soon", TTL: 10 * time.Millisecond})
+	require.NoError(t, err)
+	require.Equal(t, "soon", shortURL)
+
+	time.Sleep(20 * time.Millisecond)
+	_, err = sut.OriginalURL(ctx, "soon")
+	require.ErrorIs(t, err, ErrURLExpired)
+
+	shortURL, err = sut.ShortURL(ctx, "https://example.com", LinkOptions{Alias: "soon", TTL: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, "soon", shortURL)
+
+	originalURL, err := sut.OriginalURL(ctx, "soon")
+	require.NoError(t, err, "Expired alias is not recreated")
+	assert.Equal(t, "https://example.com", originalURL)
+}
+
+func TestShortURLService_PurgeExpiredURLs(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	purged := make(chan struct{})
+	var purgedOnce sync.Once
+	storageMock.EXPECT().
+		DeleteExpired(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, _ time.Time) (int64, error) {
+			purgedOnce.Do(func() { close(purged) })
+			return 1, nil
+		})
+
+	sut := ShortURLService{
+		storage: storageMock,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sut.PurgeExpiredURLs(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	<-purged
+	cancel()
+	<-done
+}
+
+func TestShortURLService_Stats(t *testing.T) {
+	lastClickAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	clickStats := urlstore.NewClickStats("short")
+	clickStats.Clicks = 15
+	clickStats.LastClickAt = lastClickAt
+	clickStats.AddCount(urlstore.DimensionDay, "2024-03-02", 5)
+	clickStats.AddCount(urlstore.DimensionDay, "2024-03-01", 10)
+	for i := range topCountsLimit + 5 {
+		clickStats.AddCount(urlstore.DimensionReferrer, strings.Repeat("r", i+1), int64(i))
+	}
+
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, "short").
+		Return(urlstore.Link{ShortURL: "short", OriginalURL: "original", Owner: "owner"}, nil).
+		Once()
+	storageMock.EXPECT().
+		ClickStats(mock.Anything, "short").
+		Return(clickStats, nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	stats, err := sut.Stats(context.Background(), "owner", "short")
+	require.NoError(t, err)
+
+	assert.Equal(t, "short", stats.ShortURL)
+	assert.Equal(t, int64(15), stats.Clicks)
+	assert.Equal(t, lastClickAt, stats.LastClickAt)
+	assert.Equal(t, []ClickCount{{Value: "2024-03-01", Clicks: 10}, {Value: "2024-03-02", Clicks: 5}}, stats.Days)
+	require.Len(t, stats.Referrers, topCountsLimit)
+	assert.Equal(t, ClickCount{Value: strings.Repeat("r", topCountsLimit+5), Clicks: topCountsLimit + 4}, stats.Referrers[0])
+	assert.Empty(t, stats.Networks)
+}
+
+func TestShortURLService_Stats_NotFound(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, mock.Anything).
+		Return(urlstore.Link{}, errors.New("not found")).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	_, err := sut.Stats(context.Background(), "", "short")
+
+	assert.ErrorIs(t, err, ErrURLNotFound)
+}
+
+func TestShortURLService_ChangeShortURL(t *testing.T) {
+	tests := []struct {
+		name         string
+		storageError error
+		wantError    bool
+		wantNotFound bool
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name:         "not found",
+			storageError: fmt.Errorf("%w: %q", urlstore.ErrNotFound, "short"),
+			wantError:    true,
+			wantNotFound: true,
+		},
+		{
+			name:         "storage error",
+			storageError: errors.New("some error"),
+			wantError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := NewMockurlStorage(t)
+			storageMock.EXPECT().Delete(mock.Anything, "owner", "short").Return(tt.storageError).Once()
+			storageMock.EXPECT().SetDisabled(mock.Anything, "owner", "short", true).Return(tt.storageError).Once()
+			storageMock.EXPECT().SetDisabled(mock.Anything, "owner", "short", false).Return(tt.storageError).Once()
+			storageMock.EXPECT().UpdateOriginalURL(mock.Anything, "owner", "short", "original").Return(tt.storageError).Once()
+
+			sut := ShortURLService{storage: storageMock}
+			errs := []error{
+				sut.Delete(context.Background(), "owner", "short"),
+				sut.Disable(context.Background(), "owner", "short"),
+				sut.Enable(context.Background(), "owner", "short"),
+				sut.UpdateOriginalURL(context.Background(), "owner", "short", "original"),
+			}
+
+			for _, err := range errs {
+				if !tt.wantError {
+					assert.NoError(t, err)
+					continue
+				}
+
+				require.Error(t, err)
+				assert.Equal(t, tt.wantNotFound, errors.Is(err, ErrURLNotFound))
+			}
+		})
+	}
+}
+
+func TestShortURLService_History(t *testing.T) {
+	changedAt := time.Date(2024, 3, 2, 10, 0, 0, 0, time.UTC)
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, "short").
+		Return(urlstore.Link{ShortURL: "short", OriginalURL: "new"}, nil).
+		Once()
+	storageMock.EXPECT().
+		History(mock.Anything, "short").
+		Return([]urlstore.DestinationChange{{ShortURL: "short", PreviousURL: "old", NewURL: "new", ChangedAt: changedAt}}, nil).
+		Once()
+	storageMock.EXPECT().
+		Link(mock.Anything, "not found").
+		Return(urlstore.Link{}, urlstore.ErrNotFound).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	changes, err := sut.History(context.Background(), "", "short")
+	require.NoError(t, err)
+	assert.Equal(t, []DestinationChange{{PreviousURL: "old", NewURL: "new", ChangedAt: changedAt}}, changes)
+
+	_, err = sut.History(context.Background(), "", "not found")
+	assert.ErrorIs(t, err, ErrURLNotFound)
+}
+
+func TestShortURLService_AnotherOwner(t *testing.T) {
+	sut := NewShortURLService(encoder.NewIDEncoder(), 10, WithInMemoryStorage())
+	ctx := context.Background()
+	shortURL, err := sut.ShortURL(ctx, "https://example.com", LinkOptions{Owner: "owner"})
+	require.NoError(t, err)
+
+	_, historyErr := sut.History(ctx, "another", shortURL)
+	_, statsErr := sut.Stats(ctx, "", shortURL)
+	errs := []error{
+		sut.Delete(ctx, "another", shortURL),
+		sut.Disable(ctx, "another", shortURL),
+		sut.Enable(ctx, "", shortURL),
+		sut.UpdateOriginalURL(ctx, "another", shortURL, "https://example.org"),
+		historyErr,
+		statsErr,
+	}
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrURLNotFound, "Link of another owner is changed or read")
+	}
+
+	originalURL, err := sut.OriginalURL(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", originalURL, "Link of another owner is changed")
+}
+
+func TestShortURLService_AnyOwner(t *testing.T) {
+	sut := NewShortURLService(encoder.NewIDEncoder(), 10, WithInMemoryStorage())
+	ctx := context.Background()
+	shortURL, err := sut.ShortURL(ctx, "https://example.com", LinkOptions{Owner: "owner"})
+	require.NoError(t, err)
+
+	_, err = sut.Stats(ctx, AnyOwner, shortURL)
+	require.NoError(t, err)
+
+	require.NoError(t, sut.Disable(ctx, AnyOwner, shortURL))
+	_, err = sut.OriginalURL(ctx, shortURL)
+	assert.ErrorIs(t, err, ErrURLDisabled)
+
+	require.NoError(t, sut.Delete(ctx, AnyOwner, shortURL))
+	_, err = sut.OriginalURL(ctx, shortURL)
+	assert.ErrorIs(t, err, ErrURLNotFound)
+}
+
+func TestShortURLService_ShortURLWithOwner(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		ShortURL(mock.Anything, "owner", "https://example.com/").
+		Return("1234567890", nil).
+		Once()
+	storageMock.EXPECT().
+		AddLink(mock.Anything, urlstore.Link{ShortURL: "my-alias", OriginalURL: "https://example.com/", Owner: "owner"}).
+		Return("my-alias", nil).
+		Once()
+
+	sut := ShortURLService{storage: storageMock, shortURLLength: 10}
+	result, err := sut.ShortURL(context.Background(), "https://example.com/", LinkOptions{Owner: "owner"})
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", result, "Short url of owner without other options should be reusable")
+
+	result, err = sut.ShortURL(context.Background(), "https://example.com/", LinkOptions{Alias: "my-alias", Owner: "owner"})
+	require.NoError(t, err)
+	assert.Equal(t, "my-alias", result)
+}
+
+func TestShortURLService_Ping(t *testing.T) {
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().Ping(mock.Anything).Return(nil).Once()
+	storageMock.EXPECT().Ping(mock.Anything).Return(errors.New("some error")).Once()
+
+	sut := ShortURLService{storage: storageMock}
+
+	assert.NoError(t, sut.Ping(context.Background()))
+	assert.Error(t, sut.Ping(context.Background()))
+}
@@ -0,0 +1,121 @@
+package urlservice
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"shorturl/internal/urlservice/analytics"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+const (
+	// clicksBufferSize is a count of clicks waiting to be aggregated, newer clicks are dropped.
+	clicksBufferSize = 10000
+	// clicksFlushInterval is the maximum time between saves of aggregated clicks.
+	clicksFlushInterval = 5 * time.Second
+	// topCountsLimit is the maximum count of returned values of referrers, user agents and networks.
+	topCountsLimit = 10
+)
+
+// Stats are click statistics of short URL. Days are sorted by date, other
+// counts are sorted by clicks and contain only the most clicked values.
+type Stats struct {
+	ShortURL    string
+	Clicks      int64
+	LastClickAt time.Time
+	Days        []ClickCount
+	Referrers   []ClickCount
+	UserAgents  []ClickCount
+	Networks    []ClickCount
+}
+
+// ClickCount is a count of clicks with the value of some click property.
+type ClickCount struct {
+	Value  string
+	Clicks int64
+}
+
+// RecordClick passes the click to his recorder without waiting for it to be saved.
+// If the recorder is overloaded, the click is dropped and a warning is logged.
+func (s ShortURLService) RecordClick(click analytics.Click) {
+	if s.clickRecorder == nil {
+		return
+	}
+
+	if !s.clickRecorder.Record(click) {
+		slog.Warn("Click dropped, recorder buffer is full", slog.String("short_url", click.ShortURL))
+	}
+}
+
+// RunClickRecorder saves recorded clicks to his storage until the context is done.
+// It returns after the remaining clicks are saved.
+func (s ShortURLService) RunClickRecorder(ctx context.Context) {
+	if s.clickRecorder == nil {
+		return
+	}
+
+	s.clickRecorder.Run(ctx)
+}
+
+// Stats returns click statistics of the owner's short URL. It returns ErrURLNotFound if
+// the short URL is not found in his storage or is owned by another owner. Statistics of
+// expired links are returned until they are purged.
+func (s ShortURLService) Stats(ctx context.Context, owner, shortURL string) (Stats, error) {
+	if err := s.checkOwner(ctx, owner, shortURL); err != nil {
+		return Stats{}, err
+	}
+
+	clickStats, err := s.storage.ClickStats(ctx, shortURL)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to get stats of short url %q: %w", shortURL, err)
+	}
+
+	return newStats(shortURL, clickStats), nil
+}
+
+func newStats(shortURL string, clickStats urlstore.ClickStats) Stats {
+	days := clickCounts(clickStats.Counts[urlstore.DimensionDay])
+	slices.SortFunc(days, func(a, b ClickCount) int {
+		return cmp.Compare(a.Value, b.Value)
+	})
+
+	return Stats{
+		ShortURL:    shortURL,
+		Clicks:      clickStats.Clicks,
+		LastClickAt: clickStats.LastClickAt,
+		Days:        days,
+		Referrers:   topClickCounts(clickStats.Counts[urlstore.DimensionReferrer]),
+		UserAgents:  topClickCounts(clickStats.Counts[urlstore.DimensionUserAgent]),
+		Networks:    topClickCounts(clickStats.Counts[urlstore.DimensionNetwork]),
+	}
+}
+
+func topClickCounts(counts map[string]int64) []ClickCount {
+	result := clickCounts(counts)
+	slices.SortFunc(result, func(a, b ClickCount) int {
+		if byClicks := cmp.Compare(b.Clicks, a.Clicks); byClicks != 0 {
+			return byClicks
+		}
+
+		return cmp.Compare(a.Value, b.Value)
+	})
+
+	if len(result) > topCountsLimit {
+		result = result[:topCountsLimit]
+	}
+
+	return result
+}
+
+func clickCounts(counts map[string]int64) []ClickCount {
+	result := make([]ClickCount, 0, len(counts))
+	for value, clicks := range counts {
+		result = append(result, ClickCount{Value: value, Clicks: clicks})
+	}
+
+	return result
+}
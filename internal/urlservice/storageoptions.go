@@ -2,19 +2,24 @@ package urlservice
 
 import (
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.etcd.io/bbolt"
 
 	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/boltstore"
 	"shorturl/internal/urlservice/dbstore"
 	"shorturl/internal/urlservice/memstore"
+	"shorturl/internal/urlservice/redisstore"
 )
 
 // StorageOptionFunc is used to select a storage type for InMemoryURLStorage instance.
 type StorageOptionFunc func(idEncoder encoder.IDEncoder, shortURLLength uint) urlStorage
 
 // WithInMemoryStorage returns an option that initializes and returns in-memory storage for urlStorage interface.
-func WithInMemoryStorage() StorageOptionFunc {
+// Options of the storage, like memstore.WithJournal, are optional.
+func WithInMemoryStorage(options ...memstore.Option) StorageOptionFunc {
 	return func(idEncoder encoder.IDEncoder, shortURLLength uint) urlStorage {
-		inMemoryStorage := memstore.NewInMemoryURLStorage(idEncoder, shortURLLength)
+		inMemoryStorage := memstore.NewInMemoryURLStorage(idEncoder, shortURLLength, options...)
 		adapter := newInMemoryURLStorageAdapter(inMemoryStorage)
 
 		return adapter
@@ -28,3 +33,19 @@ func WithPostgreSQLStorage(pool *pgxpool.Pool) StorageOptionFunc {
 		return dbstore.NewPostgreSQLStorage(pool, idEncoder, shortURLLength)
 	}
 }
+
+// WithRedisStorage returns an option that initializes and returns Redis storage for urlStorage interface.
+// It needs a client of Redis or another server speaking its protocol, keys of the storage have default prefix.
+func WithRedisStorage(client redis.UniversalClient) StorageOptionFunc {
+	return func(idEncoder encoder.IDEncoder, shortURLLength uint) urlStorage {
+		return redisstore.NewRedisStorage(client, redisstore.DefaultKeyPrefix, idEncoder, shortURLLength)
+	}
+}
+
+// WithBoltStorage returns an option that initializes and returns bbolt storage for urlStorage interface.
+// It needs a database opened by boltstore.Open.
+func WithBoltStorage(db *bbolt.DB) StorageOptionFunc {
+	return func(idEncoder encoder.IDEncoder, shortURLLength uint) urlStorage {
+		return boltstore.NewBoltStorage(db, idEncoder, shortURLLength)
+	}
+}
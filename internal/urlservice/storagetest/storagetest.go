@@ -0,0 +1,624 @@
+// Package storagetest provides a conformance test suite of URL storages.
+//
+// Every storage backend runs the same suite, so all of them behave in the same way
+// for the service using them.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// Storage is a URL storage tested by the suite. It has the same methods as the storage used by the service.
+type Storage interface {
+	Link(ctx context.Context, shortURL string) (urlstore.Link, error)
+	ShortURL(ctx context.Context, owner, originalURL string) (string, error)
+	AddLink(ctx context.Context, link urlstore.Link) (string, error)
+	ShortURLs(ctx context.Context, owner string, originalURLs []string) ([]string, error)
+	Links(ctx context.Context, shortURLs []string) ([]urlstore.Link, error)
+	OwnerLinks(ctx context.Context, owner, afterShortURL string, limit int) ([]urlstore.Link, error)
+	ForEachLink(ctx context.Context, fn func(link urlstore.Link) error) error
+	DeleteExpired(ctx context.Context, moment time.Time) (int64, error)
+	Delete(ctx context.Context, owner, shortURL string) error
+	SetDisabled(ctx context.Context, owner, shortURL string, disabled bool) error
+	UpdateOriginalURL(ctx context.Context, owner, shortURL, originalURL string) error
+	History(ctx context.Context, shortURL string) ([]urlstore.DestinationChange, error)
+	AddClickStats(ctx context.Context, stats []urlstore.ClickStats) error
+	ClickStats(ctx context.Context, shortURL string) (urlstore.ClickStats, error)
+	Ping(ctx context.Context) error
+}
+
+// Factory returns a new empty storage encoding short URLs of the length by the encoder.
+// It is called by every test of the suite, resources of the storage should be released by t.Cleanup.
+type Factory func(t *testing.T, idEncoder encoder.IDEncoder, shortURLLength uint) Storage
+
+// ShortURLLength is a length of short URLs encoded by storages of the suite.
+const ShortURLLength = 10
+
+// Run runs the suite against storages created by the factory. Tests are run sequentially,
+// so storages may share resources, like a database cleaned by the factory.
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, factory Factory)
+	}{
+		{name: "ShortURL", test: testShortURL},
+		{name: "ShortURL of owners", test: testShortURLOfOwners},
+		{name: "ShortURL concurrently", test: testShortURLConcurrently},
+		{name: "ShortURL skips imported short URL", test: testShortURLSkipsImportedShortURL},
+		{name: "ShortURL of unexpected length", test: testShortURLOfUnexpectedLength},
+		{name: "ShortURLs", test: testShortURLs},
+		{name: "Link not found", test: testLinkNotFound},
+		{name: "Links", test: testLinks},
+		{name: "AddLink", test: testAddLink},
+		{name: "AddLink with new short URL", test: testAddLinkWithNewShortURL},
+		{name: "DeleteExpired", test: testDeleteExpired},
+		{name: "Delete", test: testDelete},
+		{name: "Delete does not reissue short URL", test: testDeleteDoesNotReissueShortURL},
+		{name: "SetDisabled", test: testSetDisabled},
+		{name: "UpdateOriginalURL", test: testUpdateOriginalURL},
+		{name: "Changes of another owner", test: testChangesOfAnotherOwner},
+		{name: "ClickStats", test: testClickStats},
+		{name: "OwnerLinks", test: testOwnerLinks},
+		{name: "ForEachLink", test: testForEachLink},
+		{name: "Ping", test: testPing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, factory)
+		})
+	}
+}
+
+func newStorage(t *testing.T, factory Factory) Storage {
+	t.Helper()
+
+	return factory(t, encoder.NewIDEncoder(), ShortURLLength)
+}
+
+func testShortURL(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	first, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+	assert.Len(t, first, ShortURLLength)
+
+	again, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first, again, "Short url of the same original url is not reused")
+
+	another, err := sut.ShortURL(ctx, "", "https://example.org")
+	require.NoError(t, err)
+	assert.Len(t, another, ShortURLLength)
+	assert.NotEqual(t, first, another)
+
+	link, err := sut.Link(ctx, first)
+	require.NoError(t, err)
+	assert.Equal(t, urlstore.Link{ShortURL: first, OriginalURL: "https://example.com"}, link)
+}
+
+func testShortURLOfOwners(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	anonymous, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	owned, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, anonymous, owned, "Short url of anonymous link is reused by owner")
+
+	again, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, owned, again)
+
+	link, err := sut.Link(ctx, owned)
+	require.NoError(t, err)
+	assert.Equal(t, urlstore.Link{ShortURL: owned, OriginalURL: "https://example.com", Owner: "owner"}, link)
+}
+
+func testShortURLConcurrently(t *testing.T, factory Factory) {
+	const goroutines = 20
+
+	sut := newStorage(t, factory)
+
+	var wg sync.WaitGroup
+	results := make([]string, goroutines)
+	for i := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shortURL, err := sut.ShortURL(context.Background(), "", "https://example.com")
+			assert.NoError(t, err)
+			results[i] = shortURL
+		}()
+	}
+
+	wg.Wait()
+	for _, result := range results {
+		assert.Equal(t, results[0], result, "Concurrent calls returned different short urls")
+	}
+
+	links, err := sut.Links(context.Background(), results[:1])
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+}
+
+func testShortURLSkipsImportedShortURL(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	idEncoder := encoder.NewIDEncoder()
+	for id := range uint(3) {
+		imported := idEncoder.EncodeID(id, ShortURLLength)
+		_, err := sut.AddLink(ctx, urlstore.Link{ShortURL: imported, OriginalURL: fmt.Sprintf("https://example.org/%d", id)})
+		require.NoError(t, err)
+	}
+
+	shortURL, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	link, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", link.OriginalURL, "Imported short url is replaced")
+}
+
+// encoderFunc is an encoder returning results of the function.
+type encoderFunc func(id, minLen uint) string
+
+func (f encoderFunc) EncodeID(id, minLen uint) string {
+	return f(id, minLen)
+}
+
+func testShortURLOfUnexpectedLength(t *testing.T, factory Factory) {
+	shortEncoder := encoderFunc(func(id, _ uint) string {
+		return fmt.Sprint(id)
+	})
+	sut := factory(t, shortEncoder, ShortURLLength)
+	ctx := context.Background()
+
+	_, err := sut.ShortURL(ctx, "", "https://example.com")
+	assert.Error(t, err)
+
+	_, err = sut.ShortURLs(ctx, "", []string{"https://example.com"})
+	assert.Error(t, err)
+
+	_, err = sut.AddLink(ctx, urlstore.Link{OriginalURL: "https://example.com"})
+	assert.Error(t, err)
+}
+
+func testShortURLs(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	saved, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+
+	shortURLs, err := sut.ShortURLs(ctx, "owner", []string{"https://example.org", "https://example.com", "https://example.org"})
+	require.NoError(t, err)
+	require.Len(t, shortURLs, 3)
+	assert.Equal(t, saved, shortURLs[1], "Saved short url is not reused")
+	assert.Equal(t, shortURLs[0], shortURLs[2], "Short urls of the same original url differ")
+	assert.NotEqual(t, shortURLs[0], shortURLs[1])
+	assert.Len(t, shortURLs[0], ShortURLLength)
+
+	again, err := sut.ShortURL(ctx, "owner", "https://example.org")
+	require.NoError(t, err)
+	assert.Equal(t, shortURLs[0], again)
+}
+
+func testLinkNotFound(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+
+	_, err := sut.Link(context.Background(), "1234567890")
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+}
+
+func testLinks(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	first, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+	second, err := sut.ShortURL(ctx, "", "https://example.org")
+	require.NoError(t, err)
+
+	links, err := sut.Links(ctx, []string{first, "missing", second})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []urlstore.Link{
+		{ShortURL: first, OriginalURL: "https://example.com"},
+		{ShortURL: second, OriginalURL: "https://example.org"},
+	}, links)
+}
+
+func testAddLink(t *testing.T, factory Factory) {
+	expiredAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		saved            *urlstore.Link
+		link             urlstore.Link
+		expectedDisabled bool
+		expectedError    error
+	}{
+		{
+			name: "new alias",
+			link: urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+		},
+		{
+			name:             "new disabled alias",
+			link:             urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Disabled: true},
+			expectedDisabled: true,
+		},
+		{
+			name:  "same mapping",
+			saved: &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+			link:  urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+		},
+		{
+			name:  "same mapping of disabled link",
+			saved: &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+			link:  urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Disabled: true},
+		},
+		{
+			name:          "taken alias",
+			saved:         &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.org"},
+			link:          urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+			expectedError: urlstore.ErrShortURLTaken,
+		},
+		{
+			name:  "same mapping of the same owner",
+			saved: &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Owner: "alice"},
+			link:  urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Owner: "alice"},
+		},
+		{
+			name:          "alias of another owner",
+			saved:         &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Owner: "alice"},
+			link:          urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Owner: "bob"},
+			expectedError: urlstore.ErrShortURLTaken,
+		},
+		{
+			name:          "anonymous alias of owner",
+			saved:         &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", Owner: "alice"},
+			link:          urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+			expectedError: urlstore.ErrShortURLTaken,
+		},
+		{
+			name:  "expired alias",
+			saved: &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.org", ExpiresAt: expiredAt},
+			link:  urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+		},
+		{
+			name:  "expired same mapping",
+			saved: &urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com", ExpiresAt: expiredAt, Disabled: true},
+			link:  urlstore.Link{ShortURL: "alias", OriginalURL: "https://example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut := newStorage(t, factory)
+			ctx := context.Background()
+			if tt.saved != nil {
+				_, err := sut.AddLink(ctx, *tt.saved)
+				require.NoError(t, err)
+			}
+
+			shortURL, err := sut.AddLink(ctx, tt.link)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.link.ShortURL, shortURL)
+
+			link, err := sut.Link(ctx, shortURL)
+			require.NoError(t, err)
+			assert.Equal(t, tt.link.OriginalURL, link.OriginalURL)
+			assert.True(t, link.ExpiresAt.IsZero())
+			assert.Equal(t, tt.expectedDisabled, link.Disabled, "Saved link is changed")
+
+			reused, err := sut.ShortURL(ctx, "", tt.link.OriginalURL)
+			require.NoError(t, err)
+			assert.NotEqual(t, shortURL, reused, "Added link is reused")
+		})
+	}
+}
+
+func testAddLinkWithNewShortURL(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+	shortURL, err := sut.AddLink(ctx, urlstore.Link{OriginalURL: "https://example.com", ExpiresAt: expiresAt, Owner: "owner"})
+	require.NoError(t, err)
+	assert.Len(t, shortURL, ShortURLLength)
+
+	reused, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, reused, "Added link is reused")
+
+	saved, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.True(t, expiresAt.Equal(saved.ExpiresAt), "Expiration time is changed: %s", saved.ExpiresAt)
+	assert.Equal(t, "owner", saved.Owner)
+}
+
+func testDeleteExpired(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	now := time.Now()
+	_, err := sut.AddLink(ctx, urlstore.Link{ShortURL: "expired", OriginalURL: "https://example.com", ExpiresAt: now.Add(-time.Minute)})
+	require.NoError(t, err)
+	_, err = sut.AddLink(ctx, urlstore.Link{ShortURL: "expiring", OriginalURL: "https://example.com", ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err)
+	_, err = sut.AddLink(ctx, urlstore.Link{ShortURL: "permanent", OriginalURL: "https://example.com"})
+	require.NoError(t, err)
+
+	count, err := sut.DeleteExpired(ctx, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	_, err = sut.Link(ctx, "expired")
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+	_, err = sut.Link(ctx, "expiring")
+	assert.NoError(t, err)
+	_, err = sut.Link(ctx, "permanent")
+	assert.NoError(t, err)
+}
+
+func testDelete(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, sut.AddClickStats(ctx, []urlstore.ClickStats{{ShortURL: shortURL, Clicks: 1, LastClickAt: time.Now()}}))
+
+	require.NoError(t, sut.Delete(ctx, "owner", shortURL))
+	assert.ErrorIs(t, sut.Delete(ctx, "owner", shortURL), urlstore.ErrNotFound)
+
+	_, err = sut.Link(ctx, shortURL)
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+
+	stats, err := sut.ClickStats(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Zero(t, stats.Clicks, "Stats of deleted link are kept")
+
+	recreated, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+
+	link, err := sut.Link(ctx, recreated)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", link.OriginalURL)
+}
+
+// testDeleteDoesNotReissueShortURL checks that short URLs of deleted links are not reissued,
+// so old copies of them never lead to another link.
+func testDeleteDoesNotReissueShortURL(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	require.NoError(t, sut.Delete(ctx, "owner", shortURL))
+
+	recreated, err := sut.ShortURL(ctx, "owner", "https://example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, recreated, "Short URL of deleted link is reissued by ShortURL")
+
+	require.NoError(t, sut.Delete(ctx, "owner", recreated))
+	shortURLs, err := sut.ShortURLs(ctx, "owner", []string{"https://example.com"})
+	require.NoError(t, err)
+	require.Len(t, shortURLs, 1)
+	assert.NotContains(t, []string{shortURL, recreated}, shortURLs[0], "Short URL of deleted link is reissued by ShortURLs")
+
+	_, err = sut.Link(ctx, shortURL)
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+}
+
+func testSetDisabled(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, sut.SetDisabled(ctx, "", shortURL, true))
+	link, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.True(t, link.Disabled)
+
+	reused, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, shortURL, reused, "Disabled link is replaced")
+
+	require.NoError(t, sut.SetDisabled(ctx, "", shortURL, false))
+	link, err = sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.False(t, link.Disabled)
+
+	assert.ErrorIs(t, sut.SetDisabled(ctx, "", "missing", true), urlstore.ErrNotFound)
+}
+
+func testUpdateOriginalURL(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, sut.UpdateOriginalURL(ctx, "", shortURL, "https://example.org"))
+	require.NoError(t, sut.UpdateOriginalURL(ctx, "", shortURL, "https://example.org"))
+	assert.ErrorIs(t, sut.UpdateOriginalURL(ctx, "", "missing", "https://example.org"), urlstore.ErrNotFound)
+
+	link, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.org", link.OriginalURL)
+
+	reused, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, shortURL, reused, "Updated link is reused for previous url")
+
+	changes, err := sut.History(ctx, shortURL)
+	require.NoError(t, err)
+	require.Len(t, changes, 1, "Same original url is not ignored")
+	assert.Equal(t, shortURL, changes[0].ShortURL)
+	assert.Equal(t, "https://example.com", changes[0].PreviousURL)
+	assert.Equal(t, "https://example.org", changes[0].NewURL)
+	assert.WithinDuration(t, time.Now(), changes[0].ChangedAt, time.Minute)
+
+	changes, err = sut.History(ctx, reused)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+// testChangesOfAnotherOwner checks that links are changed only by their owners or by any owner,
+// and a link of another owner cannot be told apart from a missing one.
+func testChangesOfAnotherOwner(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "alice", "https://example.com")
+	require.NoError(t, err)
+
+	for _, owner := range []string{"bob", ""} {
+		assert.ErrorIs(t, sut.SetDisabled(ctx, owner, shortURL, true), urlstore.ErrNotFound)
+		assert.ErrorIs(t, sut.UpdateOriginalURL(ctx, owner, shortURL, "https://example.org"), urlstore.ErrNotFound)
+		assert.ErrorIs(t, sut.Delete(ctx, owner, shortURL), urlstore.ErrNotFound)
+	}
+
+	link, err := sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, urlstore.Link{ShortURL: shortURL, OriginalURL: "https://example.com", Owner: "alice"}, link,
+		"Link is changed by another owner")
+
+	require.NoError(t, sut.SetDisabled(ctx, urlstore.AnyOwner, shortURL, true))
+	require.NoError(t, sut.UpdateOriginalURL(ctx, urlstore.AnyOwner, shortURL, "https://example.org"))
+
+	link, err = sut.Link(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, urlstore.Link{ShortURL: shortURL, OriginalURL: "https://example.org", Disabled: true, Owner: "alice"}, link)
+
+	require.NoError(t, sut.Delete(ctx, urlstore.AnyOwner, shortURL))
+	_, err = sut.Link(ctx, shortURL)
+	assert.ErrorIs(t, err, urlstore.ErrNotFound)
+}
+
+func testClickStats(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	shortURL, err := sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	firstClick := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	lastClick := time.Now().Truncate(time.Microsecond)
+	first := urlstore.NewClickStats(shortURL)
+	first.Clicks, first.LastClickAt = 2, lastClick
+	first.AddCount(urlstore.DimensionReferrer, "example.org:8080", 2)
+	second := urlstore.NewClickStats(shortURL)
+	second.Clicks, second.LastClickAt = 1, firstClick
+	second.AddCount(urlstore.DimensionReferrer, "example.org:8080", 1)
+	second.AddCount(urlstore.DimensionDay, "2024-03-02", 1)
+	missing := urlstore.NewClickStats("missing")
+	missing.Clicks, missing.LastClickAt = 1, lastClick
+
+	require.NoError(t, sut.AddClickStats(ctx, []urlstore.ClickStats{first, missing}))
+	require.NoError(t, sut.AddClickStats(ctx, []urlstore.ClickStats{second}))
+
+	stats, err := sut.ClickStats(ctx, shortURL)
+	require.NoError(t, err)
+	assert.Equal(t, shortURL, stats.ShortURL)
+	assert.EqualValues(t, 3, stats.Clicks)
+	assert.True(t, lastClick.Equal(stats.LastClickAt), "Last click time is not the latest: %s", stats.LastClickAt)
+	assert.Equal(t, map[urlstore.ClickDimension]map[string]int64{
+		urlstore.DimensionReferrer: {"example.org:8080": 3},
+		urlstore.DimensionDay:      {"2024-03-02": 1},
+	}, stats.Counts)
+
+	stats, err = sut.ClickStats(ctx, "missing")
+	require.NoError(t, err)
+	assert.Zero(t, stats.Clicks, "Stats of not existing short url are saved")
+}
+
+func testOwnerLinks(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	var shortURLs []string
+	for i := range 3 {
+		shortURL, err := sut.ShortURL(ctx, "owner", fmt.Sprintf("https://example.com/%d", i))
+		require.NoError(t, err)
+		shortURLs = append(shortURLs, shortURL)
+	}
+
+	_, err := sut.ShortURL(ctx, "another", "https://example.com")
+	require.NoError(t, err)
+	_, err = sut.ShortURL(ctx, "", "https://example.com")
+	require.NoError(t, err)
+
+	firstPage, err := sut.OwnerLinks(ctx, "owner", "", 2)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 2)
+	assert.Equal(t, shortURLs[0], firstPage[0].ShortURL)
+	assert.Equal(t, shortURLs[1], firstPage[1].ShortURL)
+	assert.Equal(t, "owner", firstPage[0].Owner)
+
+	secondPage, err := sut.OwnerLinks(ctx, "owner", firstPage[1].ShortURL, 2)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 1)
+	assert.Equal(t, shortURLs[2], secondPage[0].ShortURL)
+
+	unknown, err := sut.OwnerLinks(ctx, "unknown", "", 2)
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
+
+func testForEachLink(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+	ctx := context.Background()
+
+	for _, alias := range []string{"c", "a", "b"} {
+		_, err := sut.AddLink(ctx, urlstore.Link{ShortURL: alias, OriginalURL: "https://example.com/" + alias})
+		require.NoError(t, err)
+	}
+
+	var visited []string
+	err := sut.ForEachLink(ctx, func(link urlstore.Link) error {
+		visited = append(visited, link.ShortURL)
+		return sut.SetDisabled(ctx, urlstore.AnyOwner, link.ShortURL, true)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, visited, "Links are not visited in order of short urls")
+
+	stop := errors.New("stop")
+	calls := 0
+	err = sut.ForEachLink(ctx, func(link urlstore.Link) error {
+		calls++
+		assert.True(t, link.Disabled)
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+func testPing(t *testing.T, factory Factory) {
+	sut := newStorage(t, factory)
+
+	assert.NoError(t, sut.Ping(context.Background()))
+}
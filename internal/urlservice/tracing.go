@@ -0,0 +1,29 @@
+package urlservice
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "shorturl/internal/urlservice"
+
+// WithTracing returns an option that traces every operation of the service storage with tracer
+// of the provider. Spans are children of spans in contexts of operations. Nil provider is ignored.
+func WithTracing(provider trace.TracerProvider) ServiceOption {
+	return func(service *ShortURLService) {
+		if provider == nil {
+			return
+		}
+
+		service.instrumentedStorage().tracer = provider.Tracer(tracerName)
+	}
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
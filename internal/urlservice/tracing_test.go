@@ -0,0 +1,49 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestWithTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		Link(mock.Anything, "1234567890").
+		RunAndReturn(func(ctx context.Context, _ string) (urlstore.Link, error) {
+			assert.True(t, trace.SpanFromContext(ctx).IsRecording())
+			return urlstore.Link{}, errors.New("some error")
+		}).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	WithMetrics(NewMockMetrics(t))(&sut)
+	WithTracing(provider)(&sut)
+
+	metricsMock := sut.metrics.(*MockMetrics)
+	metricsMock.EXPECT().ObserveStorageOperation("unknown", "link", mock.Anything, mock.Anything).Once()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	_, err := sut.OriginalURL(ctx, "1234567890")
+	require.ErrorIs(t, err, ErrURLNotFound)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "unknown.link", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
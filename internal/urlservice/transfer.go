@@ -0,0 +1,83 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"shorturl/internal/urlservice/urlstore"
+)
+
+// Link is a mapping of short URL with original URL exported from storage or imported to it.
+// Zero ExpiresAt means that the link never expires, empty Owner means that the link is anonymous.
+type Link struct {
+	ShortURL    string
+	OriginalURL string
+	ExpiresAt   time.Time
+	Disabled    bool
+	Owner       string
+}
+
+// ImportLink saves the link exported from another storage and returns its short URL.
+//
+// Enabled link without short URL and expiration is saved like one created by ShortURL without options,
+// otherwise it is saved like one created with options. Unlike aliases, imported short URLs may
+// have a form of encoded ids, generation of new short URLs skips them. Disabled link is saved
+// disabled by the same call of his storage, so it is never resolved, even for a moment. If the short
+// URL is already mapped with the same original URL of the same owner, the saved link is kept as is.
+//
+// It returns ErrInvalidAlias if the short URL cannot be used, ErrInvalidExpiration if the link
+// has expired, and ErrAliasTaken if the short URL is mapped with another original URL or owned
+// by another owner.
+func (s ShortURLService) ImportLink(ctx context.Context, link Link) (string, error) {
+	if link.ShortURL != "" {
+		if err := validateShortURLSymbols(link.ShortURL); err != nil {
+			return "", err
+		}
+	}
+
+	if !link.ExpiresAt.IsZero() && !link.ExpiresAt.After(time.Now()) {
+		return "", fmt.Errorf("%w: link has expired at %s", ErrInvalidExpiration, link.ExpiresAt)
+	}
+
+	// Reusable short URL may belong to an existing link, so disabled links are never reusable.
+	if link.ShortURL == "" && link.ExpiresAt.IsZero() && !link.Disabled {
+		return s.ShortURL(ctx, link.OriginalURL, LinkOptions{Owner: link.Owner})
+	}
+
+	shortURL, err := s.storage.AddLink(ctx, urlstore.Link{
+		ShortURL:    link.ShortURL,
+		OriginalURL: link.OriginalURL,
+		ExpiresAt:   link.ExpiresAt,
+		Disabled:    link.Disabled,
+		Owner:       link.Owner,
+	})
+	if errors.Is(err, urlstore.ErrShortURLTaken) {
+		return "", errors.Join(ErrAliasTaken, err)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to import short url for url %q: %w", link.OriginalURL, err)
+	}
+
+	return shortURL, nil
+}
+
+// ExportLinks calls fn for every link saved in his storage, including expired and disabled ones.
+// It stops on the first error returned by fn and returns it.
+func (s ShortURLService) ExportLinks(ctx context.Context, fn func(link Link) error) error {
+	return s.storage.ForEachLink(ctx, func(link urlstore.Link) error {
+		return fn(newLink(link))
+	})
+}
+
+func newLink(link urlstore.Link) Link {
+	return Link{
+		ShortURL:    link.ShortURL,
+		OriginalURL: link.OriginalURL,
+		ExpiresAt:   link.ExpiresAt,
+		Disabled:    link.Disabled,
+		Owner:       link.Owner,
+	}
+}
@@ -0,0 +1,154 @@
+package urlservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"shorturl/internal/encoder"
+	"shorturl/internal/urlservice/urlstore"
+)
+
+func TestShortURLService_ImportLink(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		link          Link
+		setupMock     func(storageMock *MockurlStorage)
+		expectedError error
+	}{
+		{
+			name: "without short url",
+			link: Link{OriginalURL: "original"},
+			setupMock: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().ShortURL(mock.Anything, "", "original").Return("1234567890", nil).Once()
+			},
+		},
+		{
+			name: "encoded short url",
+			link: Link{ShortURL: "1234567890", OriginalURL: "original", ExpiresAt: expiresAt},
+			setupMock: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().
+					AddLink(mock.Anything, urlstore.Link{ShortURL: "1234567890", OriginalURL: "original", ExpiresAt: expiresAt}).
+					Return("1234567890", nil).
+					Once()
+			},
+		},
+		{
+			name: "disabled",
+			link: Link{ShortURL: "alias", OriginalURL: "original", Disabled: true},
+			setupMock: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().
+					AddLink(mock.Anything, urlstore.Link{ShortURL: "alias", OriginalURL: "original", Disabled: true}).
+					Return("alias", nil).
+					Once()
+			},
+		},
+		{
+			name: "disabled without short url",
+			link: Link{OriginalURL: "original", Disabled: true},
+			setupMock: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().
+					AddLink(mock.Anything, urlstore.Link{OriginalURL: "original", Disabled: true}).
+					Return("1234567890", nil).
+					Once()
+			},
+		},
+		{
+			name: "taken",
+			link: Link{ShortURL: "alias", OriginalURL: "original"},
+			setupMock: func(storageMock *MockurlStorage) {
+				storageMock.EXPECT().AddLink(mock.Anything, mock.Anything).Return("", urlstore.ErrShortURLTaken).Once()
+			},
+			expectedError: ErrAliasTaken,
+		},
+		{
+			name:          "reserved",
+			link:          Link{ShortURL: "api", OriginalURL: "original"},
+			expectedError: ErrInvalidAlias,
+		},
+		{
+			name:          "expired",
+			link:          Link{ShortURL: "alias", OriginalURL: "original", ExpiresAt: time.Now().Add(-time.Hour)},
+			expectedError: ErrInvalidExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageMock := NewMockurlStorage(t)
+			if tt.setupMock != nil {
+				tt.setupMock(storageMock)
+			}
+
+			sut := ShortURLService{storage: storageMock, shortURLLength: 10}
+			shortURL, err := sut.ImportLink(context.Background(), tt.link)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NotEmpty(t, shortURL)
+		})
+	}
+}
+
+func TestShortURLService_ImportLink_ExistingLink(t *testing.T) {
+	ctx := context.Background()
+	sut := NewShortURLService(encoder.NewIDEncoder(), 10, WithInMemoryStorage())
+	shortURL, err := sut.ShortURL(ctx, "https://example.com", LinkOptions{})
+	require.NoError(t, err)
+
+	t.Run("same mapping", func(t *testing.T) {
+		imported, err := sut.ImportLink(ctx, Link{ShortURL: shortURL, OriginalURL: "https://example.com", Disabled: true})
+		require.NoError(t, err)
+		assert.Equal(t, shortURL, imported)
+	})
+
+	t.Run("reusable short url", func(t *testing.T) {
+		imported, err := sut.ImportLink(ctx, Link{OriginalURL: "https://example.com", Disabled: true})
+		require.NoError(t, err)
+		assert.NotEqual(t, shortURL, imported, "Disabled link is imported as reusable one")
+
+		_, err = sut.OriginalURL(ctx, imported)
+		assert.ErrorIs(t, err, ErrURLDisabled)
+	})
+
+	originalURL, err := sut.OriginalURL(ctx, shortURL)
+	require.NoError(t, err, "Existing link is disabled by import")
+	assert.Equal(t, "https://example.com", originalURL)
+}
+
+func TestShortURLService_ExportLinks(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour)
+	storageMock := NewMockurlStorage(t)
+	storageMock.EXPECT().
+		ForEachLink(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, fn func(urlstore.Link) error) error {
+			return errors.Join(
+				fn(urlstore.Link{ShortURL: "first", OriginalURL: "original"}),
+				fn(urlstore.Link{ShortURL: "second", OriginalURL: "original", ExpiresAt: expiresAt, Disabled: true}),
+			)
+		}).
+		Once()
+
+	sut := ShortURLService{storage: storageMock}
+	var links []Link
+	err := sut.ExportLinks(context.Background(), func(link Link) error {
+		links = append(links, link)
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Link{
+		{ShortURL: "first", OriginalURL: "original"},
+		{ShortURL: "second", OriginalURL: "original", ExpiresAt: expiresAt, Disabled: true},
+	}, links)
+}
@@ -0,0 +1,109 @@
+// Package urlstore declares values shared by URL storage implementations,
+// so the service using them can handle their results in the same way.
+package urlstore
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrShortURLTaken is returned by storage when requested short URL is already
+	// mapped with another original URL.
+	ErrShortURLTaken = errors.New("short url is already taken")
+
+	// ErrNotFound is returned by storage when requested short URL is not saved.
+	ErrNotFound = errors.New("short url not found")
+)
+
+// AnyOwner is passed to storage instead of the owner to change a link of any owner. It is used by
+// administrative commands. Owners of links created over API are hashes of API keys, so it is never
+// the owner of a link.
+const AnyOwner = "*"
+
+// Link is a mapping of short URL with the original URL saved in storage.
+//
+// Zero ExpiresAt means that the link never expires. Disabled link is kept
+// in storage, but must not be resolved. Empty Owner means that the link was
+// created anonymously.
+type Link struct {
+	ShortURL    string
+	OriginalURL string
+	ExpiresAt   time.Time
+	Disabled    bool
+	Owner       string
+}
+
+// IsExpired reports whether the link has expired at the moment.
+func (l Link) IsExpired(moment time.Time) bool {
+	return !l.ExpiresAt.IsZero() && !moment.Before(l.ExpiresAt)
+}
+
+// IsOwnedBy reports whether the link may be changed by the owner. Empty owner means anonymous links.
+func (l Link) IsOwnedBy(owner string) bool {
+	return owner == AnyOwner || l.Owner == owner
+}
+
+// DestinationChange is a record of the change of original URL mapped with short URL.
+type DestinationChange struct {
+	ShortURL    string
+	PreviousURL string
+	NewURL      string
+	ChangedAt   time.Time
+}
+
+// ClickDimension is a property of clicks on short URL which values are counted separately.
+type ClickDimension string
+
+const (
+	// DimensionDay is a date of click in UTC in format YYYY-MM-DD.
+	DimensionDay ClickDimension = "day"
+	// DimensionReferrer is a host of page containing clicked short URL.
+	DimensionReferrer ClickDimension = "referrer"
+	// DimensionUserAgent is a user agent of client clicked short URL.
+	DimensionUserAgent ClickDimension = "user_agent"
+	// DimensionNetwork is a network of client clicked short URL, not its exact address.
+	DimensionNetwork ClickDimension = "network"
+)
+
+// ClickStats are aggregated clicks on short URL. Counts contain count of clicks for each
+// value of each dimension.
+//
+// It is used both for statistics saved in storage and for clicks to add to them.
+type ClickStats struct {
+	ShortURL    string
+	Clicks      int64
+	LastClickAt time.Time
+	Counts      map[ClickDimension]map[string]int64
+}
+
+// NewClickStats returns empty statistics of the short URL ready to add clicks.
+func NewClickStats(shortURL string) ClickStats {
+	return ClickStats{
+		ShortURL: shortURL,
+		Counts:   make(map[ClickDimension]map[string]int64),
+	}
+}
+
+// Merge adds clicks of other statistics to the statistics.
+func (s *ClickStats) Merge(other ClickStats) {
+	s.Clicks += other.Clicks
+	if other.LastClickAt.After(s.LastClickAt) {
+		s.LastClickAt = other.LastClickAt
+	}
+
+	for dimension, counts := range other.Counts {
+		for value, clicks := range counts {
+			s.AddCount(dimension, value, clicks)
+		}
+	}
+}
+
+// AddCount adds clicks to the value of the dimension. It does not change total count of clicks.
+func (s *ClickStats) AddCount(dimension ClickDimension, value string, clicks int64) {
+	if s.Counts[dimension] == nil {
+		s.Counts[dimension] = make(map[string]int64)
+	}
+
+	s.Counts[dimension][value] += clicks
+}